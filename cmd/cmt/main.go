@@ -8,6 +8,7 @@ import (
 
 	"github.com/agentic-camerata/cmt/internal/cli"
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/notify"
 )
 
 var version = "dev"
@@ -34,8 +35,10 @@ func main() {
 
 	c.SetDatabase(database)
 
-	// Run command
-	if err := ctx.Run(&c); err != nil {
+	// Run command, then let the notifications of the sessions it ran go out
+	err = ctx.Run(&c)
+	notify.WaitDefault()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -7,7 +7,7 @@ _cmt_completions() {
     cur="${COMP_WORDS[COMP_CWORD]}"
     prev="${COMP_WORDS[COMP_CWORD-1]}"
 
-    local commands="new research plan implement review fix-test fix-local-comments fix-pr-build fix-pr-comments quick play sessions jump dashboard todo catalog"
+    local commands="new research plan implement review fix-test fix-local-comments fix-pr-build fix-pr-comments quick play sessions jump send dashboard todo catalog agents hooks serve mcp"
     local global_opts="-d --db -v --verbose -a --autonomous -h --help --model --agent"
     local file_opts="-f --files -d --dirs -t --thoughts -c --catalog"
     local loop_opts="--loop --loop-limit"
@@ -177,18 +177,67 @@ _cmt_completions() {
             COMPREPLY=()
             ;;
         play)
-            case "$prev" in
-                -r|--resume)
-                    local sessions
-                    sessions=$(cmt sessions 2>/dev/null | tail -n +2 | awk '{print $1}')
-                    COMPREPLY=($(compgen -W "$sessions" -- "$cur"))
+            local play_commands="run list show validate rerun"
+            local playbooks
+            playbooks=$(cmt play list 2>/dev/null | awk '$2 ~ /^(project|global)/ {print $1}')
+            case "${COMP_WORDS[2]}" in
+                list)
+                    COMPREPLY=()
+                    ;;
+                show)
+                    COMPREPLY=($(compgen -W "$playbooks" -- "$cur"))
+                    ;;
+                validate)
+                    case "$prev" in
+                        --set)
+                            COMPREPLY=()
+                            ;;
+                        *)
+                            if [[ "$cur" == -* ]]; then
+                                COMPREPLY=($(compgen -W "--set" -- "$cur"))
+                            else
+                                COMPREPLY=($(compgen -W "$playbooks" -- "$cur") $(compgen -f -- "$cur"))
+                            fi
+                            ;;
+                    esac
+                    ;;
+                rerun)
+                    case "$prev" in
+                        --phase)
+                            COMPREPLY=()
+                            ;;
+                        *)
+                            if [[ "$cur" == -* ]]; then
+                                COMPREPLY=($(compgen -W "--phase" -- "$cur"))
+                            else
+                                local sessions
+                                sessions=$(cmt sessions 2>/dev/null | tail -n +2 | awk '{print $1}')
+                                COMPREPLY=($(compgen -W "$sessions" -- "$cur"))
+                            fi
+                            ;;
+                    esac
                     ;;
                 *)
-                    if [[ "$cur" == -* ]]; then
-                        COMPREPLY=($(compgen -W "-r --resume" -- "$cur"))
-                    else
-                        COMPREPLY=($(compgen -f -- "$cur"))
-                    fi
+                    # "cmt play FILE" is short for "cmt play run FILE"
+                    case "$prev" in
+                        -r|--resume)
+                            local sessions
+                            sessions=$(cmt sessions 2>/dev/null | tail -n +2 | awk '{print $1}')
+                            COMPREPLY=($(compgen -W "$sessions" -- "$cur"))
+                            ;;
+                        --from-phase)
+                            COMPREPLY=()
+                            ;;
+                        *)
+                            if [[ "$cur" == -* ]]; then
+                                COMPREPLY=($(compgen -W "-r --resume --from-phase --dry-run" -- "$cur"))
+                            elif [[ $COMP_CWORD -eq 2 ]]; then
+                                COMPREPLY=($(compgen -W "$play_commands $playbooks" -- "$cur") $(compgen -f -- "$cur"))
+                            else
+                                COMPREPLY=($(compgen -W "$playbooks" -- "$cur") $(compgen -f -- "$cur"))
+                            fi
+                            ;;
+                    esac
                     ;;
             esac
             ;;
@@ -215,6 +264,16 @@ _cmt_completions() {
                 COMPREPLY=($(compgen -W "last $sessions" -- "$cur"))
             fi
             ;;
+        send)
+            # send <session> <message> - complete with session IDs
+            if [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "--no-enter" -- "$cur"))
+            elif [[ $COMP_CWORD -eq 2 ]]; then
+                local sessions
+                sessions=$(cmt sessions 2>/dev/null | tail -n +2 | awk '{print $1}')
+                COMPREPLY=($(compgen -W "last $sessions" -- "$cur"))
+            fi
+            ;;
         dashboard)
             if [[ "$cur" == -* ]]; then
                 COMPREPLY=($(compgen -W "--venues --todos --debug" -- "$cur"))
@@ -253,7 +312,7 @@ _cmt_completions() {
             esac
             ;;
         todo)
-            local todo_commands="add list search done undone update rm"
+            local todo_commands="add list search done undone update rm start serve"
             case "${COMP_WORDS[2]}" in
                 add)
                     case "$prev" in
@@ -316,6 +375,38 @@ _cmt_completions() {
                             ;;
                     esac
                     ;;
+                start)
+                    case "$prev" in
+                        -w|--workflow)
+                            COMPREPLY=($(compgen -W "new research plan" -- "$cur"))
+                            ;;
+                        --venue)
+                            COMPREPLY=($(compgen -d -- "$cur"))
+                            ;;
+                        --split)
+                            COMPREPLY=($(compgen -W "h v" -- "$cur"))
+                            ;;
+                        --tmux-session) COMPREPLY=() ;;
+                        *)
+                            if [[ "$cur" == -* ]]; then
+                                COMPREPLY=($(compgen -W "-w --workflow --venue --done --window --split --tmux-session" -- "$cur"))
+                            fi
+                            ;;
+                    esac
+                    ;;
+                serve)
+                    case "$prev" in
+                        --listen|--github-secret|--slack-secret) COMPREPLY=() ;;
+                        --token-file)
+                            COMPREPLY=($(compgen -f -- "$cur"))
+                            ;;
+                        *)
+                            if [[ "$cur" == -* ]]; then
+                                COMPREPLY=($(compgen -W "--listen --token-file --github-secret --slack-secret" -- "$cur"))
+                            fi
+                            ;;
+                    esac
+                    ;;
                 done|undone|rm)
                     # These take a todo ID - no dynamic completion
                     COMPREPLY=()
@@ -327,6 +418,37 @@ _cmt_completions() {
                     ;;
             esac
             ;;
+        agents)
+            COMPREPLY=()
+            ;;
+        hooks)
+            # hooks list|trust [dir]
+            if [[ $COMP_CWORD -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "list trust" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -d -- "$cur"))
+            fi
+            ;;
+        serve)
+            case "$prev" in
+                --listen)
+                    COMPREPLY=($(compgen -W "127.0.0.1:7777 unix:" -- "$cur"))
+                    ;;
+                --token-file)
+                    COMPREPLY=($(compgen -f -- "$cur"))
+                    ;;
+                *)
+                    if [[ "$cur" == -* ]]; then
+                        COMPREPLY=($(compgen -W "--listen --token-file" -- "$cur"))
+                    fi
+                    ;;
+            esac
+            ;;
+        mcp)
+            if [[ "$cur" == -* ]]; then
+                COMPREPLY=($(compgen -W "--allow-autonomous" -- "$cur"))
+            fi
+            ;;
         *)
             # Complete commands and global options
             if [[ "$cur" == -* ]]; then
@@ -8,6 +8,11 @@ function __cmt_sessions
     cmt sessions 2>/dev/null | tail -n +2 | awk '{print $1}'
 end
 
+# Helper function to get library playbook names
+function __cmt_playbooks
+    cmt play list 2>/dev/null | awk '$2 ~ /^(project|global)/ {print $1}'
+end
+
 # Global options
 complete -c cmt -s d -l db -d 'Database path' -r
 complete -c cmt -s v -l verbose -d 'Enable verbose output'
@@ -30,9 +35,14 @@ complete -c cmt -n __fish_use_subcommand -a quick -d 'Quick single-response quer
 complete -c cmt -n __fish_use_subcommand -a play -d 'Run a multi-phase playbook workflow'
 complete -c cmt -n __fish_use_subcommand -a sessions -d 'List all sessions'
 complete -c cmt -n __fish_use_subcommand -a jump -d 'Jump to a session\'s tmux location'
+complete -c cmt -n __fish_use_subcommand -a send -d 'Type a message into a running session'
 complete -c cmt -n __fish_use_subcommand -a dashboard -d 'Open the TUI dashboard'
 complete -c cmt -n __fish_use_subcommand -a todo -d 'Manage todos'
 complete -c cmt -n __fish_use_subcommand -a catalog -d 'Store and reuse research files across projects'
+complete -c cmt -n __fish_use_subcommand -a agents -d 'List agent backends, their versions and capabilities'
+complete -c cmt -n __fish_use_subcommand -a hooks -d 'List and trust lifecycle hook scripts'
+complete -c cmt -n __fish_use_subcommand -a serve -d 'Serve a local HTTP/JSON API for sessions, todos and venues'
+complete -c cmt -n __fish_use_subcommand -a mcp -d 'Serve cmt\'s tools to agents over the Model Context Protocol (stdio)'
 
 # File flags for commands that support them
 complete -c cmt -n '__fish_seen_subcommand_from new research plan review fix-test fix-local-comments fix-pr-build fix-pr-comments' -s f -d 'File path to prepend to prompt (repeatable)' -r -F
@@ -51,9 +61,26 @@ complete -c cmt -n '__fish_seen_subcommand_from new' -l resume-id -d 'Resume a s
 # fix-local-comments command options
 complete -c cmt -n '__fish_seen_subcommand_from fix-local-comments' -l comment-tag -d 'Comment tag to search for' -r
 
-# play command - complete with any file or directory
-complete -c cmt -n '__fish_seen_subcommand_from play' -F -d 'Playbook file'
-complete -c cmt -n '__fish_seen_subcommand_from play' -s r -l resume -d 'Resume an abandoned play session' -f -a '(__cmt_sessions)'
+# play subcommands; "cmt play FILE" is short for "cmt play run FILE"
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from run list show validate rerun' -a run -d 'Run a multi-phase playbook workflow'
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from run list show validate rerun' -a list -d 'List playbooks in the project and global libraries'
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from run list show validate rerun' -a show -d 'Print a library playbook'
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from run list show validate rerun' -a validate -d 'Check a playbook for errors without running it'
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from run list show validate rerun' -a rerun -d 'Rerun a single phase of a play session'
+
+# play command - complete with library playbooks and any file or directory
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from list rerun' -a '(__cmt_playbooks)' -d 'Library playbook'
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from list show rerun' -F -d 'Playbook file'
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from list show validate rerun' -s r -l resume -d 'Resume an abandoned play session' -f -a '(__cmt_sessions)'
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from list show validate rerun' -l from-phase -d 'With --resume, restart from this phase (number or tag)' -r
+complete -c cmt -n '__fish_seen_subcommand_from play; and not __fish_seen_subcommand_from list show validate rerun' -l dry-run -d 'Print the expanded phases, file flow and prompts without running any agent'
+
+# play validate options
+complete -c cmt -n '__fish_seen_subcommand_from play; and __fish_seen_subcommand_from validate' -l set -d 'Set a playbook parameter (NAME=VALUE)' -r
+
+# play rerun options
+complete -c cmt -n '__fish_seen_subcommand_from play; and __fish_seen_subcommand_from rerun' -a '(__cmt_sessions)' -d 'Play session ID'
+complete -c cmt -n '__fish_seen_subcommand_from play; and __fish_seen_subcommand_from rerun' -l phase -d 'Phase to rerun (number or tag)' -r
 
 # implement command - complete with markdown files for plan argument
 complete -c cmt -n '__fish_seen_subcommand_from implement' -s d -l dir -d 'Directory to list plans from' -r -a '(__fish_complete_directories)'
@@ -63,6 +90,11 @@ complete -c cmt -n '__fish_seen_subcommand_from implement' -a '(__fish_complete_
 complete -c cmt -n '__fish_seen_subcommand_from jump' -a 'last' -d 'Jump to most recent session'
 complete -c cmt -n '__fish_seen_subcommand_from jump' -a '(__cmt_sessions)' -d 'Session ID'
 
+# send command - complete with session IDs
+complete -c cmt -n '__fish_seen_subcommand_from send' -a 'last' -d 'Send to most recent session'
+complete -c cmt -n '__fish_seen_subcommand_from send' -a '(__cmt_sessions)' -d 'Session ID'
+complete -c cmt -n '__fish_seen_subcommand_from send' -l no-enter -d 'Type the text without pressing Enter'
+
 # sessions command options
 complete -c cmt -n '__fish_seen_subcommand_from sessions' -s s -d 'Filter by status' -r -a 'waiting working completed abandoned killed deleted restored'
 complete -c cmt -n '__fish_seen_subcommand_from sessions' -s n -d 'Limit number of sessions' -r
@@ -83,14 +115,28 @@ complete -c cmt -n '__fish_seen_subcommand_from catalog; and not __fish_seen_sub
 complete -c cmt -n '__fish_seen_subcommand_from catalog; and __fish_seen_subcommand_from save' -s F -l force -d 'Overwrite if the catalog entry already exists'
 complete -c cmt -n '__fish_seen_subcommand_from catalog; and __fish_seen_subcommand_from save' -a '(__fish_complete_suffix .md)' -d 'Markdown file'
 
+# hooks subcommands, then a venue directory
+complete -c cmt -n '__fish_seen_subcommand_from hooks; and not __fish_seen_subcommand_from list trust' -a list -d 'List the hooks that run for sessions in a directory'
+complete -c cmt -n '__fish_seen_subcommand_from hooks; and not __fish_seen_subcommand_from list trust' -a trust -d 'Allow a venue\'s .cmt/hooks/ scripts to run'
+complete -c cmt -n '__fish_seen_subcommand_from hooks; and __fish_seen_subcommand_from list trust' -a '(__fish_complete_directories)' -d 'Venue directory'
+
+# serve command options
+complete -c cmt -n '__fish_seen_subcommand_from serve; and not __fish_seen_subcommand_from todo' -l listen -d 'Address to listen on: host:port, or unix:/path' -r
+complete -c cmt -n '__fish_seen_subcommand_from serve; and not __fish_seen_subcommand_from todo' -l token-file -d 'File holding the API token' -r -F
+
+# mcp command options
+complete -c cmt -n '__fish_seen_subcommand_from mcp' -l allow-autonomous -d 'Let agents start subsessions that skip permission prompts'
+
 # todo subcommands
-complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm' -a add -d 'Add a new todo'
-complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm' -a list -d 'List todos'
-complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm' -a search -d 'Search todos with filters'
-complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm' -a done -d 'Mark a todo as done'
-complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm' -a undone -d 'Mark a todo as not done'
-complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm' -a update -d 'Update a todo'
-complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm' -a rm -d 'Remove a todo'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a add -d 'Add a new todo'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a list -d 'List todos'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a search -d 'Search todos with filters'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a done -d 'Mark a todo as done'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a undone -d 'Mark a todo as not done'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a update -d 'Update a todo'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a rm -d 'Remove a todo'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a start -d 'Start an agent session on a todo'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and not __fish_seen_subcommand_from add list search done undone update rm start serve' -a serve -d 'Accept todos over HTTP from scripts and webhooks'
 
 # todo add options
 complete -c cmt -n '__fish_seen_subcommand_from add' -s s -l source -d 'Source (e.g. slack, github, email)' -r
@@ -121,3 +167,17 @@ complete -c cmt -n '__fish_seen_subcommand_from update' -s f -l sender -d 'Sende
 complete -c cmt -n '__fish_seen_subcommand_from update' -s u -l url -d 'URL' -r
 complete -c cmt -n '__fish_seen_subcommand_from update' -s d -l date -d 'Date (YYYY-MM-DD)' -r
 complete -c cmt -n '__fish_seen_subcommand_from update' -s m -l full-message -d 'Full message text' -r
+
+# todo start options
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from start' -s w -l workflow -d 'Workflow to start' -r -f -a 'new research plan'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from start' -l venue -d 'Directory to run the session in' -r -f -a '(__fish_complete_directories)'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from start' -l done -d 'Mark the todo done when the session completes'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from start' -l window -d 'Start the session in a new tmux window'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from start' -l split -d 'Start the session in a new tmux pane' -r -f -a 'h v'
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from start' -l tmux-session -d 'Start the session in a window of this tmux session' -r
+
+# todo serve options
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from serve' -l listen -d 'Address to listen on: host:port, or unix:/path' -r
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from serve' -l token-file -d 'File holding the token' -r -F
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from serve' -l github-secret -d 'Secret GitHub webhook payloads must be signed with' -r
+complete -c cmt -n '__fish_seen_subcommand_from todo; and __fish_seen_subcommand_from serve' -l slack-secret -d 'Signing secret Slack requests must be signed with' -r
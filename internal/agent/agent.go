@@ -38,16 +38,29 @@ type RunOptions struct {
 	AutonomousMode    bool           // If true, skip permission prompts
 	CommentTag        string         // Comment tag for fix-local-comments (from CMT_COMMENT_TAG env var)
 	ResumeSessionID   string         // If non-empty, pass --resume to agent. "*" means interactive picker
+	ResumeAgent       string         // Backend ResumeSessionID belongs to; other backends of a chain start fresh. Empty means any
 	SkipTracking      bool           // If true, skip DB session creation and activity monitoring
 	AutoTerminate     bool           // If true, send kill when session goes idle after working
 	CapturedFiles     *[]string      // If non-nil, collect thoughts/shared/*.md paths from output
 	CapturePattern    *regexp.Regexp // If non-nil, override default file capture regex
-	CapturedSessionID *string        // If non-nil, capture Claude session ID from PTY output into this string
+	CapturedSessionID *string        // If non-nil, capture the backend's session ID into this string (see runner.Base.SessionIDs)
+	CapturedAgent     *string        // If non-nil, set to the name of the backend that ran the session, which CapturedSessionID belongs to
+	SessionID         string         // If non-empty, the ID to give the session record instead of a new one
 	ParentID          string         // Parent session ID (for play command phases)
+	TodoID            string         // Todo the session is started for, recorded in the DB
+	CompleteTodo      bool           // If true, mark the todo done when the session completes
 	Interrupted       *bool          // If non-nil, set to true when the child exits without auto-terminate firing
 	LoopInterval      string         // Interval string for looping sessions (e.g. "5m"); stored in DB, empty if not looping
+	Headless          bool           // If true, don't attach the terminal; output only goes to the session log
+	Agent             string         // Name of the backend running the session, recorded in the DB
+	StartupCheck      *StartupCheck  // If non-nil, report ErrBackendUnavailable when the backend fails to start
+	PreSessionRan     bool           // If true, the pre-session hooks already ran for this session, e.g. on an earlier backend of a chain
 }
 
+// Backends lists the built-in agent backends. More can be defined in the agents config.
+// The fake backend runs a scripted transcript for tests and demos.
+var Backends = []string{"claude", "codex", "amp", "pi", "fake"}
+
 // Agent defines the interface for AI coding agents (Claude, Codex, etc.)
 type Agent interface {
 	Run(ctx context.Context, opts RunOptions) error
@@ -57,4 +70,9 @@ type Agent interface {
 	// DefaultEffort returns the runner-specific default effort for a command type.
 	// Returns "" if the runner has no effort concept or should use its CLI's built-in default.
 	DefaultEffort(cmd CommandType) string
+	// Prompt returns the prompt the runner sends for opts: the task description
+	// with any command-specific prefix applied.
+	Prompt(opts RunOptions) string
+	// Capabilities reports which options the runner honours.
+	Capabilities() Capabilities
 }
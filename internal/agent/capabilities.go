@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EffortLevels lists every effort level cmt knows; backends accept a subset.
+var EffortLevels = []string{"low", "normal", "medium", "high", "xhigh", "max"}
+
+// Capabilities describes which RunOptions a backend honours.
+type Capabilities struct {
+	Binary           string   // CLI the backend runs ("" if it needs none)
+	Resume           bool     // resumes a session by ID (ResumeSessionID)
+	ResumePicker     bool     // resumes a session picked interactively (ResumeSessionID "*")
+	Models           bool     // accepts a model (Model)
+	Efforts          []string // effort levels it accepts (Effort); nil if it has no effort concept
+	PrintMode        bool     // prints a single response and exits (PrintMode)
+	Autonomous       bool     // runs without permission prompts when asked (AutonomousMode)
+	SessionIDCapture bool     // reports its session ID (CapturedSessionID)
+	NoPersist        bool     // skips saving its own session history for ephemeral runs (SkipTracking)
+}
+
+// Check returns the requested options in opts that the backend cannot honour: errors for
+// options it would get wrong, warnings for options it silently ignores.
+func (c Capabilities) Check(opts RunOptions) (errs, warnings []string) {
+	switch {
+	case opts.ResumeSessionID == "*" && !c.ResumePicker:
+		errs = append(errs, "cannot pick a session to resume")
+	case opts.ResumeSessionID != "" && opts.ResumeSessionID != "*" && !c.Resume:
+		errs = append(errs, "cannot resume a session by ID")
+	}
+	if opts.Effort != "" {
+		if c.Efforts == nil {
+			warnings = append(warnings, fmt.Sprintf("ignores effort %q", opts.Effort))
+		} else if !slices.Contains(c.Efforts, opts.Effort) {
+			errs = append(errs, fmt.Sprintf("does not support effort %q (supported: %s)", opts.Effort, strings.Join(c.Efforts, ", ")))
+		}
+	}
+	if opts.Model != "" && !c.Models {
+		warnings = append(warnings, fmt.Sprintf("ignores model %q", opts.Model))
+	}
+	if opts.PrintMode && !c.PrintMode {
+		warnings = append(warnings, "has no print mode and runs interactively")
+	}
+	if opts.AutonomousMode && !c.Autonomous {
+		warnings = append(warnings, "ignores autonomous mode")
+	}
+	return errs, warnings
+}
+
+// Features lists the capabilities the backend has, for display.
+func (c Capabilities) Features() []string {
+	var out []string
+	if c.Resume || c.ResumePicker {
+		out = append(out, "resume")
+	}
+	if c.Models {
+		out = append(out, "models")
+	}
+	if c.Efforts != nil {
+		out = append(out, "effort ("+strings.Join(c.Efforts, "/")+")")
+	}
+	if c.PrintMode {
+		out = append(out, "print")
+	}
+	if c.Autonomous {
+		out = append(out, "autonomous")
+	}
+	if c.SessionIDCapture {
+		out = append(out, "session-id")
+	}
+	if c.NoPersist {
+		out = append(out, "no-persist")
+	}
+	return out
+}
+
+// intersect returns the capabilities both c and o have.
+func (c Capabilities) intersect(o Capabilities) Capabilities {
+	out := Capabilities{
+		Binary:           c.Binary + "," + o.Binary,
+		Resume:           c.Resume && o.Resume,
+		ResumePicker:     c.ResumePicker && o.ResumePicker,
+		Models:           c.Models && o.Models,
+		PrintMode:        c.PrintMode && o.PrintMode,
+		Autonomous:       c.Autonomous && o.Autonomous,
+		SessionIDCapture: c.SessionIDCapture && o.SessionIDCapture,
+		NoPersist:        c.NoPersist && o.NoPersist,
+	}
+	if c.Efforts != nil && o.Efforts != nil {
+		out.Efforts = []string{}
+		for _, e := range c.Efforts {
+			if slices.Contains(o.Efforts, e) {
+				out.Efforts = append(out.Efforts, e)
+			}
+		}
+	}
+	return out
+}
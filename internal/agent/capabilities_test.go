@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCapabilitiesCheck(t *testing.T) {
+	full := Capabilities{Resume: true, ResumePicker: true, Models: true, Efforts: []string{"low", "max"}, PrintMode: true, Autonomous: true}
+	bare := Capabilities{}
+
+	tests := []struct {
+		name         string
+		caps         Capabilities
+		opts         RunOptions
+		wantErrs     []string
+		wantWarnings []string
+	}{
+		{name: "everything supported", caps: full, opts: RunOptions{ResumeSessionID: "abc", Model: "opus", Effort: "max", PrintMode: true, AutonomousMode: true}},
+		{name: "nothing requested", caps: bare, opts: RunOptions{}},
+		{name: "resume by id", caps: bare, opts: RunOptions{ResumeSessionID: "abc"}, wantErrs: []string{"resume a session by ID"}},
+		{name: "resume picker", caps: bare, opts: RunOptions{ResumeSessionID: "*"}, wantErrs: []string{"pick a session"}},
+		{name: "unsupported effort level", caps: full, opts: RunOptions{Effort: "xhigh"}, wantErrs: []string{`effort "xhigh" (supported: low, max)`}},
+		{
+			name:         "ignored options",
+			caps:         bare,
+			opts:         RunOptions{Model: "opus", Effort: "max", PrintMode: true, AutonomousMode: true},
+			wantWarnings: []string{`model "opus"`, `effort "max"`, "print mode", "autonomous"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, warnings := tt.caps.Check(tt.opts)
+			if len(errs) != len(tt.wantErrs) || len(warnings) != len(tt.wantWarnings) {
+				t.Fatalf("Check() = %q, %q", errs, warnings)
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i], want) {
+					t.Errorf("error %q does not mention %q", errs[i], want)
+				}
+			}
+			for _, want := range tt.wantWarnings {
+				if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, want) }) {
+					t.Errorf("warnings %q do not mention %q", warnings, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChainCapabilities(t *testing.T) {
+	a := &stubAgent{caps: Capabilities{Binary: "a", Resume: true, Models: true, Efforts: []string{"low", "high", "max"}}}
+	b := &stubAgent{caps: Capabilities{Binary: "b", Models: true, Efforts: []string{"high", "max"}, PrintMode: true}}
+	got := NewChain([]string{"a", "b"}, []Agent{a, b}).Capabilities()
+
+	if got.Resume || got.PrintMode || !got.Models || !slices.Equal(got.Efforts, []string{"high", "max"}) {
+		t.Errorf("Capabilities() = %+v, want only what both backends have", got)
+	}
+
+	c := &stubAgent{caps: Capabilities{Binary: "c"}}
+	if got := NewChain([]string{"a", "c"}, []Agent{a, c}).Capabilities(); got.Efforts != nil {
+		t.Errorf("Efforts = %v, want nil when a backend has no effort concept", got.Efforts)
+	}
+}
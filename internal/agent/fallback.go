@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrBackendUnavailable is returned by Run when RunOptions.StartupCheck is set and the
+// backend failed to get going: it is not installed, exited before printing anything,
+// printed nothing for too long, or reported a known startup error such as a rate limit.
+var ErrBackendUnavailable = errors.New("agent backend unavailable")
+
+// StartupCheck configures how a runner decides that its backend is unavailable.
+type StartupCheck struct {
+	FirstOutputTimeout time.Duration    // No output for this long after start means unavailable
+	Window             time.Duration    // How long after start output is checked against ErrorPatterns
+	ErrorPatterns      []*regexp.Regexp // Output matching any of these before the agent shows its UI means unavailable
+}
+
+// StartupErrorPatterns are the startup errors the agent CLIs and the shell are known to
+// print when they cannot serve a session. They are whole phrases, so a task that merely
+// mentions rate limits or logins does not match.
+var StartupErrorPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`API Error: (429|529)\b`),                        // claude
+	regexp.MustCompile(`Claude AI usage limit reached`),                 // claude
+	regexp.MustCompile(`"type":\s*"overloaded_error"`),                  // claude
+	regexp.MustCompile(`Invalid API key\W+Please run /login`),           // claude
+	regexp.MustCompile(`OAuth token has expired\W+Please run /login`),   // claude
+	regexp.MustCompile(`You've hit your usage limit`),                   // codex
+	regexp.MustCompile(`Rate limit reached for \S+ in organization`),    // OpenAI API
+	regexp.MustCompile(`exceeded retry limit, last status: 429`),        // codex
+	regexp.MustCompile(`(?m)^\S+: (line \d+: )?\S+: command not found`), // a wrapper script's shell
+}
+
+// DefaultStartupCheck is the check applied to every backend of a chain but the last.
+var DefaultStartupCheck = StartupCheck{
+	FirstOutputTimeout: 30 * time.Second,
+	Window:             10 * time.Second,
+	ErrorPatterns:      StartupErrorPatterns,
+}
+
+// Chain runs a session on the first available of several backends, in order. Every
+// backend but the last runs with a StartupCheck; when it reports ErrBackendUnavailable
+// the next backend is tried. A session ID to resume is only passed to the backend it
+// belongs to (RunOptions.ResumeAgent); the others start a fresh session. A chain of one
+// backend just records its name on the session.
+type Chain struct {
+	names  []string
+	agents []Agent
+	check  StartupCheck
+	log    io.Writer
+}
+
+// Ensure Chain implements Agent at compile time.
+var _ Agent = (*Chain)(nil)
+
+// NewChain creates a chain of the given backends; names[i] is the name of agents[i].
+func NewChain(names []string, agents []Agent) *Chain {
+	return &Chain{names: names, agents: agents, check: DefaultStartupCheck, log: os.Stderr}
+}
+
+// Names returns the names of the chain's backends, in order.
+func (c *Chain) Names() []string {
+	return c.names
+}
+
+// Run starts the session on the first backend that is available.
+func (c *Chain) Run(ctx context.Context, opts RunOptions) error {
+	var files int
+	if opts.CapturedFiles != nil {
+		files = len(*opts.CapturedFiles)
+	}
+	for i, ag := range c.agents {
+		o := opts
+		o.Agent = c.names[i]
+		if o.ResumeAgent != "" && o.ResumeAgent != o.Agent {
+			o.ResumeSessionID = ""
+		}
+		last := i == len(c.agents)-1
+		if !last {
+			check := c.check
+			o.StartupCheck = &check
+		}
+		caps := ag.Capabilities()
+		errs, warnings := caps.Check(o)
+		if len(errs) > 0 {
+			if last {
+				return fmt.Errorf("%s %s", c.names[i], strings.Join(errs, "; "))
+			}
+			// A later backend may support what this one cannot
+			fmt.Fprintf(c.log, "--- %s %s; falling back to %s\n", c.names[i], strings.Join(errs, "; "), c.names[i+1])
+			continue
+		}
+		for _, w := range warnings {
+			fmt.Fprintf(c.log, "--- %s %s\n", c.names[i], w)
+		}
+		if opts.CapturedAgent != nil {
+			*opts.CapturedAgent = o.Agent
+		}
+		err := ag.Run(ctx, o)
+		if last || !errors.Is(err, ErrBackendUnavailable) {
+			return err
+		}
+
+		// The session is the same one as far as hooks are concerned
+		opts.PreSessionRan = true
+		// Forget anything captured from the failed start
+		if opts.CapturedFiles != nil {
+			*opts.CapturedFiles = (*opts.CapturedFiles)[:files]
+		}
+		if opts.CapturedSessionID != nil {
+			*opts.CapturedSessionID = ""
+		}
+		fmt.Fprintf(c.log, "--- %v; falling back to %s\n", err, c.names[i+1])
+	}
+	return nil
+}
+
+// DefaultModel returns the first backend's default model.
+func (c *Chain) DefaultModel(cmd CommandType) string {
+	return c.agents[0].DefaultModel(cmd)
+}
+
+// DefaultEffort returns the first backend's default effort.
+func (c *Chain) DefaultEffort(cmd CommandType) string {
+	return c.agents[0].DefaultEffort(cmd)
+}
+
+// Capabilities returns the capabilities every backend of the chain has.
+func (c *Chain) Capabilities() Capabilities {
+	caps := c.agents[0].Capabilities()
+	for _, ag := range c.agents[1:] {
+		caps = caps.intersect(ag.Capabilities())
+	}
+	return caps
+}
+
+// Prompt returns the first backend's prompt.
+func (c *Chain) Prompt(opts RunOptions) string {
+	return c.agents[0].Prompt(opts)
+}
+
+// ParseChain splits an agent value such as "claude,pi,codex" into backend names.
+func ParseChain(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
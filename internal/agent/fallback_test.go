@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubAgent records the options it was run with and returns err.
+type stubAgent struct {
+	err  error
+	caps Capabilities
+	runs []RunOptions
+}
+
+func (a *stubAgent) Run(ctx context.Context, opts RunOptions) error {
+	a.runs = append(a.runs, opts)
+	if opts.CapturedFiles != nil {
+		*opts.CapturedFiles = append(*opts.CapturedFiles, "thoughts/shared/x.md")
+	}
+	return a.err
+}
+
+func (a *stubAgent) DefaultModel(cmd CommandType) string  { return "" }
+func (a *stubAgent) DefaultEffort(cmd CommandType) string { return "" }
+func (a *stubAgent) Prompt(opts RunOptions) string        { return opts.TaskDescription }
+func (a *stubAgent) Capabilities() Capabilities           { return a.caps }
+
+func TestChainRun(t *testing.T) {
+	unavailable := fmt.Errorf("%w: claude is not installed", ErrBackendUnavailable)
+	failed := errors.New("exit status 2")
+
+	tests := []struct {
+		name     string
+		errs     []error
+		wantErr  error
+		wantRuns []int
+	}{
+		{name: "first backend runs", errs: []error{nil, nil}, wantRuns: []int{1, 0}},
+		{name: "falls back when unavailable", errs: []error{unavailable, nil}, wantRuns: []int{1, 1}},
+		{name: "other errors do not fall back", errs: []error{failed, nil}, wantErr: failed, wantRuns: []int{1, 0}},
+		{name: "last backend's error is returned", errs: []error{unavailable, unavailable}, wantErr: ErrBackendUnavailable, wantRuns: []int{1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubs := []*stubAgent{{err: tt.errs[0]}, {err: tt.errs[1]}}
+			chain := NewChain([]string{"claude", "pi"}, []Agent{stubs[0], stubs[1]})
+			chain.log = io.Discard
+
+			var files []string
+			err := chain.Run(context.Background(), RunOptions{CapturedFiles: &files})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			for i, s := range stubs {
+				if len(s.runs) != tt.wantRuns[i] {
+					t.Fatalf("backend %d ran %d times, want %d", i, len(s.runs), tt.wantRuns[i])
+				}
+			}
+			if got := stubs[0].runs[0]; got.Agent != "claude" || got.StartupCheck == nil || got.PreSessionRan {
+				t.Errorf("first backend options: agent %q, startup check %v, pre-session ran %v", got.Agent, got.StartupCheck, got.PreSessionRan)
+			}
+			if len(stubs[1].runs) > 0 {
+				if got := stubs[1].runs[0]; got.Agent != "pi" || got.StartupCheck != nil || !got.PreSessionRan {
+					t.Errorf("last backend options: agent %q, startup check %v, pre-session ran %v", got.Agent, got.StartupCheck, got.PreSessionRan)
+				}
+			}
+			if len(files) != 1 {
+				t.Errorf("captured files = %v, want only the last run's", files)
+			}
+		})
+	}
+}
+
+func TestParseChain(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "claude", want: []string{"claude"}},
+		{value: "Claude, pi ,codex", want: []string{"claude", "pi", "codex"}},
+		{value: "claude,,pi,", want: []string{"claude", "pi"}},
+		{value: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := ParseChain(tt.value); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseChain(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestChainRunChecksCapabilities(t *testing.T) {
+	stub := &stubAgent{}
+	chain := NewChain([]string{"codex"}, []Agent{stub})
+	var log strings.Builder
+	chain.log = &log
+
+	err := chain.Run(context.Background(), RunOptions{ResumeSessionID: "abc"})
+	if err == nil || !strings.Contains(err.Error(), "codex cannot resume") || len(stub.runs) != 0 {
+		t.Fatalf("Run() error = %v after %d runs, want it to refuse to resume", err, len(stub.runs))
+	}
+
+	if err := chain.Run(context.Background(), RunOptions{Model: "o3"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.Contains(log.String(), `codex ignores model "o3"`) {
+		t.Errorf("log = %q, want a warning about the ignored model", log.String())
+	}
+}
+
+func TestChainRunSkipsIncapableBackend(t *testing.T) {
+	codex, claude := &stubAgent{}, &stubAgent{caps: Capabilities{Resume: true}}
+	chain := NewChain([]string{"codex", "claude"}, []Agent{codex, claude})
+	var log strings.Builder
+	chain.log = &log
+
+	if err := chain.Run(context.Background(), RunOptions{ResumeSessionID: "abc"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(codex.runs) != 0 || len(claude.runs) != 1 {
+		t.Errorf("runs = %d, %d, want only claude to run", len(codex.runs), len(claude.runs))
+	}
+	if !strings.Contains(log.String(), "codex cannot resume a session by ID; falling back to claude") {
+		t.Errorf("log = %q, want the skip to be reported", log.String())
+	}
+}
+
+func TestChainRunResumesOnlyOwningBackend(t *testing.T) {
+	unavailable := fmt.Errorf("%w: claude is not installed", ErrBackendUnavailable)
+
+	tests := []struct {
+		name       string
+		claudeErr  error
+		wantResume []string // ResumeSessionID each backend that ran was given
+		wantAgent  string
+	}{
+		{name: "other backend starts fresh", wantResume: []string{""}, wantAgent: "claude"},
+		{name: "owning backend resumes", claudeErr: unavailable, wantResume: []string{"", "abc"}, wantAgent: "pi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claude := &stubAgent{err: tt.claudeErr, caps: Capabilities{Resume: true}}
+			pi := &stubAgent{caps: Capabilities{Resume: true}}
+			chain := NewChain([]string{"claude", "pi"}, []Agent{claude, pi})
+			chain.log = io.Discard
+
+			var agentName string
+			opts := RunOptions{ResumeSessionID: "abc", ResumeAgent: "pi", CapturedAgent: &agentName}
+			if err := chain.Run(context.Background(), opts); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var got []string
+			for _, s := range []*stubAgent{claude, pi} {
+				for _, run := range s.runs {
+					got = append(got, run.ResumeSessionID)
+				}
+			}
+			if !slices.Equal(got, tt.wantResume) {
+				t.Errorf("resume IDs = %q, want %q", got, tt.wantResume)
+			}
+			if agentName != tt.wantAgent {
+				t.Errorf("CapturedAgent = %q, want %q", agentName, tt.wantAgent)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SessionIDSource finds the ID a backend gives its own session, so the session can be
+// resumed later. Runners try FromOutput on the PTY output as it arrives and, if that
+// found nothing, AfterExit once the process is gone.
+type SessionIDSource interface {
+	// FromOutput returns the session ID in the output seen so far, or "".
+	FromOutput(output string) string
+	// AfterExit returns the ID of the session the backend ran in workDir since started, or "".
+	AfterExit(workDir string, started time.Time) string
+}
+
+// OutputPattern finds the session ID in PTY output: the first group of the first match.
+type OutputPattern struct {
+	Re *regexp.Regexp
+}
+
+func (p OutputPattern) FromOutput(output string) string {
+	if m := p.Re.FindStringSubmatch(output); len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+func (p OutputPattern) AfterExit(string, time.Time) string { return "" }
+
+// SessionFiles finds the session ID from the session file the backend wrote or appended to
+// during the run. When several files changed, as with concurrent sessions, it gives up
+// rather than guess.
+type SessionFiles struct {
+	Dir          func(workDir string) string // directory holding the session files, searched recursively
+	Ext          string                      // session file extension, e.g. ".jsonl"
+	IDRe         *regexp.Regexp              // first group of its match on the file name is the ID; nil means the file path is the ID
+	MatchWorkDir bool                        // only consider files whose first line names workDir (as a JSON string)
+}
+
+// mtimeSlack allows for file systems whose timestamps lag the clock.
+const mtimeSlack = 100 * time.Millisecond
+
+func (f SessionFiles) FromOutput(string) string { return "" }
+
+func (f SessionFiles) AfterExit(workDir string, started time.Time) string {
+	var found []string
+	filepath.WalkDir(f.Dir(workDir), func(path string, d fs.DirEntry, err error) error { //nolint:errcheck
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, f.Ext) {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.ModTime().Before(started.Add(-mtimeSlack)) {
+			return nil
+		}
+		if f.MatchWorkDir && !firstLineMentions(path, workDir) {
+			return nil
+		}
+		found = append(found, path)
+		return nil
+	})
+	if len(found) != 1 {
+		return ""
+	}
+	if f.IDRe == nil {
+		return found[0]
+	}
+	if m := f.IDRe.FindStringSubmatch(filepath.Base(found[0])); len(m) > 1 {
+		return m[1]
+	}
+	return ""
+}
+
+// firstLineMentions reports whether the first line of the file contains s as a JSON string.
+func firstLineMentions(path, s string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	quoted, _ := json.Marshal(s)
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	return sc.Scan() && strings.Contains(sc.Text(), string(quoted))
+}
+
+// FirstOf tries each source in order.
+type FirstOf []SessionIDSource
+
+func (s FirstOf) FromOutput(output string) string {
+	for _, src := range s {
+		if id := src.FromOutput(output); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func (s FirstOf) AfterExit(workDir string, started time.Time) string {
+	for _, src := range s {
+		if id := src.AfterExit(workDir, started); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// JSONSessionIDRe matches a "session_id" field in JSON output, as agent CLIs print in
+// their JSON output modes.
+var JSONSessionIDRe = regexp.MustCompile(`"session_?[iI]d"\s*:\s*"([^"]+)"`)
+
+// HomeDir returns the home directory joined with elem, or "" if it is unknown.
+func HomeDir(elem ...string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(append([]string{home}, elem...)...)
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestOutputPattern(t *testing.T) {
+	tests := []struct {
+		name   string
+		re     *regexp.Regexp
+		output string
+		want   string
+	}{
+		{name: "claude session line", re: regexp.MustCompile(`session_([A-Za-z0-9]{10,})`), output: "resume with session_01ABCDEFGHIJ\n", want: "01ABCDEFGHIJ"},
+		{name: "json output", re: JSONSessionIDRe, output: `{"type":"result","session_id": "abc-123"}`, want: "abc-123"},
+		{name: "camel case json", re: JSONSessionIDRe, output: `{"sessionId":"abc-123"}`, want: "abc-123"},
+		{name: "no match", re: JSONSessionIDRe, output: "working...", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (OutputPattern{Re: tt.re}).FromOutput(tt.output); got != tt.want {
+				t.Errorf("FromOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionFiles(t *testing.T) {
+	started := time.Now()
+	old := started.Add(-time.Hour)
+
+	write := func(t *testing.T, path, content string, mtime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idRe := regexp.MustCompile(`^rollout-(.+)\.jsonl$`)
+
+	t.Run("file written during the run", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, "old.jsonl"), "{}\n", old)
+		write(t, filepath.Join(dir, "2025", "rollout-new.jsonl"), "{}\n", started.Add(time.Second))
+		write(t, filepath.Join(dir, "notes.txt"), "", started.Add(time.Second))
+
+		src := SessionFiles{Dir: func(string) string { return dir }, Ext: ".jsonl", IDRe: idRe}
+		if got := src.AfterExit("/work", started); got != "new" {
+			t.Errorf("AfterExit() = %q, want new", got)
+		}
+	})
+
+	t.Run("path is the ID without IDRe", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "s.jsonl")
+		write(t, path, "{}\n", started.Add(time.Second))
+
+		src := SessionFiles{Dir: func(string) string { return dir }, Ext: ".jsonl"}
+		if got := src.AfterExit("/work", started); got != path {
+			t.Errorf("AfterExit() = %q, want %q", got, path)
+		}
+	})
+
+	t.Run("several candidates", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, "rollout-a.jsonl"), "{}\n", started.Add(time.Second))
+		write(t, filepath.Join(dir, "rollout-b.jsonl"), "{}\n", started.Add(time.Second))
+
+		src := SessionFiles{Dir: func(string) string { return dir }, Ext: ".jsonl", IDRe: idRe}
+		if got := src.AfterExit("/work", started); got != "" {
+			t.Errorf("AfterExit() = %q, want no guess", got)
+		}
+	})
+
+	t.Run("matches the working directory", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, "rollout-a.jsonl"), `{"payload":{"cwd":"/work"}}`+"\n", started.Add(time.Second))
+		write(t, filepath.Join(dir, "rollout-b.jsonl"), `{"payload":{"cwd":"/elsewhere"}}`+"\n", started.Add(time.Second))
+
+		src := SessionFiles{Dir: func(string) string { return dir }, Ext: ".jsonl", IDRe: idRe, MatchWorkDir: true}
+		if got := src.AfterExit("/work", started); got != "a" {
+			t.Errorf("AfterExit() = %q, want a", got)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		src := SessionFiles{Dir: func(string) string { return filepath.Join(t.TempDir(), "none") }, Ext: ".jsonl"}
+		if got := src.AfterExit("/work", started); got != "" {
+			t.Errorf("AfterExit() = %q, want empty", got)
+		}
+	})
+}
+
+func TestFirstOf(t *testing.T) {
+	src := FirstOf{
+		OutputPattern{Re: regexp.MustCompile(`first=(\w+)`)},
+		OutputPattern{Re: regexp.MustCompile(`second=(\w+)`)},
+	}
+	if got := src.FromOutput("second=b first=a"); got != "a" {
+		t.Errorf("FromOutput() = %q, want a", got)
+	}
+	if got := src.FromOutput("second=b"); got != "b" {
+		t.Errorf("FromOutput() = %q, want b", got)
+	}
+	if got := src.AfterExit("/work", time.Now()); got != "" {
+		t.Errorf("AfterExit() = %q, want empty", got)
+	}
+}
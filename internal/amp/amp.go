@@ -4,6 +4,7 @@ package amp
 import (
 	"context"
 	"os/exec"
+	"regexp"
 	"time"
 
 	"github.com/agentic-camerata/cmt/internal/agent"
@@ -27,9 +28,13 @@ func NewRunner(database *db.DB) (*Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	base.SessionIDs = agent.OutputPattern{Re: threadIDRe}
 	return &Runner{base: base}, nil
 }
 
+// threadIDRe matches the ID of the Amp thread a session runs in, e.g. in its thread URL.
+var threadIDRe = regexp.MustCompile(`\b(T-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\b`)
+
 // Run starts an Amp session.
 func (r *Runner) Run(ctx context.Context, opts agent.RunOptions) error {
 	execOpts := prepareRunOptions(opts)
@@ -58,6 +63,24 @@ func (r *Runner) DefaultEffort(cmd agent.CommandType) string {
 	return ""
 }
 
+// Prompt returns the task description with the Amp prompt prefix applied.
+func (r *Runner) Prompt(opts agent.RunOptions) string {
+	return agent.ApplyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+}
+
+// Capabilities reports the options the Amp CLI honours. Amp picks models through its
+// modes, so it takes no model.
+func (r *Runner) Capabilities() agent.Capabilities {
+	return agent.Capabilities{
+		Binary:           "amp",
+		Resume:           true,
+		ResumePicker:     true,
+		PrintMode:        true,
+		Autonomous:       true,
+		SessionIDCapture: true,
+	}
+}
+
 // buildCommand constructs the amp CLI command from the given options.
 func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 	args := []string{}
@@ -77,7 +100,7 @@ func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 	}
 
 	// Amp uses -x (--execute) for non-interactive single-response mode.
-	taskDescription := agent.ApplyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+	taskDescription := r.Prompt(opts)
 	if opts.PrintMode {
 		if taskDescription != "" {
 			args = append(args, "-x", taskDescription)
@@ -0,0 +1,205 @@
+// Package api serves sessions, todos, venues and the catalog as a local HTTP/JSON API,
+// for editor plugins, status bars and web UIs. Every request must carry the token from
+// the token file, as "Authorization: Bearer <token>" or, for EventSource clients that
+// cannot set headers, a token query parameter.
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// DefaultListen is the address cmt serve listens on by default.
+const DefaultListen = "127.0.0.1:7777"
+
+// defaultPollInterval is how often streams check for new output and session changes.
+const defaultPollInterval = 500 * time.Millisecond
+
+// StartRequest asks for a new session of a cmt command.
+type StartRequest struct {
+	Command     string `json:"command"` // new, research, plan, implement or review
+	Task        string `json:"task"`    // the command's argument: task, topic, plan file or focus
+	Dir         string `json:"dir"`     // working directory (absolute)
+	Agent       string `json:"agent,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Effort      string `json:"effort,omitempty"`
+	Autonomous  bool   `json:"autonomous,omitempty"`
+	TmuxSession string `json:"tmux_session,omitempty"` // tmux session to start the window in
+	ParentID    string `json:"parent_id,omitempty"`    // session the new one is a child of
+}
+
+// Validate checks the command, that it has a task if it needs one, and that the
+// directory exists.
+func (req *StartRequest) Validate() error {
+	needsTask, ok := startCommands[req.Command]
+	switch {
+	case !ok:
+		return fmt.Errorf("invalid command %q (want new, research, plan, implement or review)", req.Command)
+	case needsTask && req.Task == "":
+		return fmt.Errorf("%s needs a task", req.Command)
+	case !filepath.IsAbs(req.Dir):
+		return fmt.Errorf("dir must be an absolute path")
+	}
+	if info, err := os.Stat(req.Dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", req.Dir)
+	}
+	return nil
+}
+
+// Started describes a session a Launcher started.
+type Started struct {
+	ID   string `json:"id"`
+	Tmux string `json:"tmux"` // where the session's window or pane is
+}
+
+// Launcher starts a session in a new tmux window. The session record appears once the
+// agent starts in it.
+type Launcher func(req StartRequest) (Started, error)
+
+// startCommands are the commands sessions can be started with, and whether they need a task.
+var startCommands = map[string]bool{"new": false, "research": true, "plan": true, "implement": false, "review": false}
+
+// Server is the API server.
+type Server struct {
+	db     *db.DB
+	token  string
+	launch Launcher
+	mux    *http.ServeMux
+
+	// PollInterval is how often streams check for changes.
+	PollInterval time.Duration
+}
+
+// New returns a server for database, accepting token. Sessions are started with launch.
+func New(database *db.DB, token string, launch Launcher) *Server {
+	s := &Server{db: database, token: token, launch: launch, mux: http.NewServeMux(), PollInterval: defaultPollInterval}
+
+	s.mux.HandleFunc("GET /api/sessions", s.listSessions)
+	s.mux.HandleFunc("POST /api/sessions", s.startSession)
+	s.mux.HandleFunc("GET /api/sessions/{id}", s.getSession)
+	s.mux.HandleFunc("POST /api/sessions/{id}/kill", s.killSession)
+	s.mux.HandleFunc("GET /api/sessions/{id}/log", s.sessionLog)
+	s.mux.HandleFunc("GET /api/sessions/{id}/screen", s.sessionScreen)
+	s.mux.HandleFunc("GET /api/events", s.events)
+
+	s.mux.HandleFunc("GET /api/todos", s.listTodos)
+	s.mux.HandleFunc("POST /api/todos", s.createTodo)
+	s.mux.HandleFunc("GET /api/todos/{id}", s.getTodo)
+	s.mux.HandleFunc("PATCH /api/todos/{id}", s.updateTodo)
+	s.mux.HandleFunc("DELETE /api/todos/{id}", s.deleteTodo)
+
+	s.mux.HandleFunc("GET /api/venues", s.listVenues)
+	s.mux.HandleFunc("POST /api/venues", s.pinVenue)
+	s.mux.HandleFunc("DELETE /api/venues", s.unpinVenue)
+
+	s.mux.HandleFunc("GET /api/catalog", s.listCatalog)
+	s.mux.HandleFunc("GET /api/catalog/{name}", s.getCatalogFile)
+	return s
+}
+
+// ServeHTTP checks the token and serves the request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		writeError(w, http.StatusUnauthorized, "missing or invalid token")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck // the client went away
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// readJSON decodes the request body into v, answering 400 when it can't.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// Listen listens on addr: host:port, or unix:/path for a Unix socket only the user can use.
+func Listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("listen on %s: %w", addr, err)
+		}
+		return ln, nil
+	}
+
+	os.Remove(path) //nolint:errcheck // a stale socket from a previous run
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("restrict socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// TokenPath returns the default token file path: ~/.config/cmt/api-token.
+func TokenPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "cmt", "api-token"), nil
+}
+
+// LoadToken reads the token from path, creating a random one readable only by the user
+// if the file does not exist.
+func LoadToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("token file %s is empty", path)
+		}
+		return token, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read token: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("write token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,389 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+const testToken = "secret"
+
+func testServer(t *testing.T, launch Launcher) (*httptest.Server, *db.DB) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	s := New(database, testToken, launch)
+	s.PollInterval = 10 * time.Millisecond
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts, database
+}
+
+// call makes an authenticated request and decodes the JSON response into out, if given.
+func call(t *testing.T, ts *httptest.Server, method, path, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func createSession(t *testing.T, database *db.DB, id string, status db.SessionStatus, dir, output string) {
+	t.Helper()
+	s := &db.Session{ID: id, WorkflowType: db.WorkflowGeneral, Status: status, WorkingDirectory: dir, OutputFile: output}
+	if err := database.CreateSession(s); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	ts, _ := testServer(t, nil)
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   int
+	}{
+		{name: "no token", want: http.StatusUnauthorized},
+		{name: "wrong token", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "bearer token", header: "Bearer " + testToken, want: http.StatusOK},
+		{name: "query token", query: "?token=" + testToken, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/sessions"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessions(t *testing.T) {
+	ts, database := testServer(t, nil)
+	createSession(t, database, "run1", db.StatusWorking, "/src/app", "")
+	createSession(t, database, "done1", db.StatusCompleted, "/src/app", "")
+
+	var all []Session
+	if code := call(t, ts, http.MethodGet, "/api/sessions", "", &all); code != http.StatusOK || len(all) != 2 {
+		t.Fatalf("list = %d %+v, want both sessions", code, all)
+	}
+	var working []Session
+	call(t, ts, http.MethodGet, "/api/sessions?status=working", "", &working)
+	if len(working) != 1 || working[0].ID != "run1" {
+		t.Errorf("list working = %+v, want run1", working)
+	}
+	if code := call(t, ts, http.MethodGet, "/api/sessions?status=bogus", "", nil); code != http.StatusBadRequest {
+		t.Errorf("invalid status = %d, want 400", code)
+	}
+
+	var got Session
+	if code := call(t, ts, http.MethodGet, "/api/sessions/done1", "", &got); code != http.StatusOK || got.Status != "completed" || got.Directory != "/src/app" {
+		t.Errorf("get = %d %+v, want the completed session", code, got)
+	}
+	if code := call(t, ts, http.MethodGet, "/api/sessions/missing", "", nil); code != http.StatusNotFound {
+		t.Errorf("get missing = %d, want 404", code)
+	}
+
+	if code := call(t, ts, http.MethodPost, "/api/sessions/done1/kill", "", nil); code != http.StatusConflict {
+		t.Errorf("kill finished session = %d, want 409", code)
+	}
+	if code := call(t, ts, http.MethodPost, "/api/sessions/run1/kill", "", &got); code != http.StatusOK || got.Status != "killed" {
+		t.Errorf("kill = %d %+v, want the killed session", code, got)
+	}
+	if s, _ := database.GetSession("run1"); s.Status != db.StatusKilled {
+		t.Errorf("status after kill = %s, want killed", s.Status)
+	}
+}
+
+func TestStartSession(t *testing.T) {
+	var launched StartRequest
+	ts, _ := testServer(t, func(req StartRequest) (Started, error) {
+		launched = req
+		return Started{ID: "new1", Tmux: "cmt:2.0"}, nil
+	})
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "unknown command", body: `{"command":"rm","dir":"` + dir + `"}`, want: http.StatusBadRequest},
+		{name: "research without topic", body: `{"command":"research","dir":"` + dir + `"}`, want: http.StatusBadRequest},
+		{name: "relative dir", body: `{"command":"new","dir":"src"}`, want: http.StatusBadRequest},
+		{name: "unknown field", body: `{"command":"new","dir":"` + dir + `","prompt":"x"}`, want: http.StatusBadRequest},
+		{name: "research", body: `{"command":"research","task":"auth flow","dir":"` + dir + `","agent":"claude"}`, want: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := call(t, ts, http.MethodPost, "/api/sessions", tt.body, nil); code != tt.want {
+				t.Errorf("start = %d, want %d", code, tt.want)
+			}
+		})
+	}
+	if launched.Command != "research" || launched.Task != "auth flow" || launched.Agent != "claude" || launched.Dir != dir {
+		t.Errorf("launched %+v, want the research session", launched)
+	}
+}
+
+func TestSessionLogAndScreen(t *testing.T) {
+	ts, database := testServer(t, nil)
+	output := filepath.Join(t.TempDir(), "s1.log")
+	os.WriteFile(output, []byte("hello\r\nworld\r\n"), 0644)
+	createSession(t, database, "s1", db.StatusWorking, "/src/app", output)
+
+	resp, err := http.Get(ts.URL + "/api/sessions/s1/log?bytes=7&token=" + testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != "world\r\n" {
+		t.Errorf("log tail = %q, want the last 7 bytes", data)
+	}
+
+	var screen struct{ Lines []string }
+	call(t, ts, http.MethodGet, "/api/sessions/s1/screen", "", &screen)
+	if len(screen.Lines) < 2 || screen.Lines[0] != "hello" || screen.Lines[1] != "world" {
+		t.Errorf("screen = %q, want hello and world", screen.Lines)
+	}
+}
+
+func TestSessionLogFollow(t *testing.T) {
+	ts, database := testServer(t, nil)
+	output := filepath.Join(t.TempDir(), "s1.log")
+	os.WriteFile(output, []byte("one\n"), 0644)
+	createSession(t, database, "s1", db.StatusWorking, "/src/app", output)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/sessions/s1/log?follow=true&token="+testToken, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewScanner(resp.Body)
+	next := func() (string, string) { return readEvent(t, events) }
+
+	if event, data := next(); event != "output" || data != `{"data":"one\n"}` {
+		t.Errorf("first event = %s %s, want the existing output", event, data)
+	}
+	f, _ := os.OpenFile(output, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("two\n")
+	f.Close()
+	if event, data := next(); event != "output" || data != `{"data":"two\n"}` {
+		t.Errorf("second event = %s %s, want the new output", event, data)
+	}
+	database.UpdateSessionStatus("s1", db.StatusCompleted)
+	if event, data := next(); event != "end" || data != `{"status":"completed"}` {
+		t.Errorf("last event = %s %s, want end", event, data)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	ts, database := testServer(t, nil)
+	createSession(t, database, "old", db.StatusCompleted, "/src/app", "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/events?token="+testToken, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewScanner(resp.Body)
+
+	// Existing sessions are not reported; new ones and changes are
+	createSession(t, database, "new1", db.StatusWorking, "/src/app", "")
+	var got Session
+	event, data := readEvent(t, events)
+	if err := json.Unmarshal([]byte(data), &got); event != "session" || err != nil || got.ID != "new1" {
+		t.Fatalf("event = %s %s, want the new session", event, data)
+	}
+	database.DeleteSession("old")
+	if event, data := readEvent(t, events); event != "session_removed" || data != `{"id":"old"}` {
+		t.Errorf("event = %s %s, want old removed", event, data)
+	}
+}
+
+func TestTodos(t *testing.T) {
+	ts, _ := testServer(t, nil)
+
+	var created Todo
+	if code := call(t, ts, http.MethodPost, "/api/todos", `{"summary":"Review PR","source":"github","idempotency_key":"pr-1"}`, &created); code != http.StatusCreated {
+		t.Fatalf("create = %d, want 201", code)
+	}
+	if created.Summary != "Review PR" || created.Status != "todo" || created.Source == nil || *created.Source != "github" {
+		t.Errorf("created = %+v", created)
+	}
+	var again Todo
+	if code := call(t, ts, http.MethodPost, "/api/todos", `{"summary":"Review PR","idempotency_key":"pr-1"}`, &again); code != http.StatusOK || again.ID != created.ID {
+		t.Errorf("create duplicate = %d %s, want 200 with %s", code, again.ID, created.ID)
+	}
+	if code := call(t, ts, http.MethodPost, "/api/todos", `{"source":"github"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("create without summary = %d, want 400", code)
+	}
+
+	var updated Todo
+	if code := call(t, ts, http.MethodPatch, "/api/todos/"+created.ID, `{"status":"done","source":""}`, &updated); code != http.StatusOK {
+		t.Fatalf("update = %d, want 200", code)
+	}
+	if updated.Status != "done" || updated.Source != nil || updated.Summary != "Review PR" {
+		t.Errorf("updated = %+v, want done, source cleared, summary kept", updated)
+	}
+	if code := call(t, ts, http.MethodPatch, "/api/todos/"+created.ID, `{"status":"deleted"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("update to deleted = %d, want 400", code)
+	}
+
+	var done []Todo
+	call(t, ts, http.MethodGet, "/api/todos?status=done", "", &done)
+	if len(done) != 1 || done[0].ID != created.ID {
+		t.Errorf("list done = %+v", done)
+	}
+
+	if code := call(t, ts, http.MethodDelete, "/api/todos/"+created.ID, "", nil); code != http.StatusNoContent {
+		t.Errorf("delete = %d, want 204", code)
+	}
+	var deleted []Todo
+	call(t, ts, http.MethodGet, "/api/todos?status=deleted", "", &deleted)
+	if len(deleted) != 1 || deleted[0].DeletedAt == nil {
+		t.Errorf("list deleted = %+v", deleted)
+	}
+	if code := call(t, ts, http.MethodGet, "/api/todos/missing", "", nil); code != http.StatusNotFound {
+		t.Errorf("get missing = %d, want 404", code)
+	}
+}
+
+func TestVenues(t *testing.T) {
+	ts, database := testServer(t, nil)
+	pinned := t.TempDir()
+	createSession(t, database, "s1", db.StatusWorking, "/src/app", "")
+	createSession(t, database, "s2", db.StatusCompleted, "/src/app", "")
+
+	if code := call(t, ts, http.MethodPost, "/api/venues", `{"directory":"`+pinned+`"}`, nil); code != http.StatusCreated {
+		t.Fatalf("pin = %d, want 201", code)
+	}
+	if code := call(t, ts, http.MethodPost, "/api/venues", `{"directory":"/does/not/exist"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("pin missing directory = %d, want 400", code)
+	}
+
+	var venues []Venue
+	call(t, ts, http.MethodGet, "/api/venues", "", &venues)
+	want := []Venue{
+		{Directory: pinned, Pinned: true},
+		{Directory: "/src/app", Sessions: 2, Running: 1},
+	}
+	if len(venues) != 2 || venues[0] != want[0] || venues[1] != want[1] {
+		t.Errorf("venues = %+v, want %+v", venues, want)
+	}
+
+	if code := call(t, ts, http.MethodDelete, "/api/venues?directory="+pinned, "", nil); code != http.StatusNoContent {
+		t.Errorf("unpin = %d, want 204", code)
+	}
+	call(t, ts, http.MethodGet, "/api/venues", "", &venues)
+	if len(venues) != 1 || venues[0].Pinned {
+		t.Errorf("venues after unpin = %+v", venues)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CMT_CATALOG_DIR", dir)
+	os.WriteFile(filepath.Join(dir, "auth.md"), []byte("# Auth\n"), 0644)
+	ts, _ := testServer(t, nil)
+
+	var entries []CatalogEntry
+	call(t, ts, http.MethodGet, "/api/catalog", "", &entries)
+	if len(entries) != 1 || entries[0].Name != "auth.md" || entries[0].Size != 7 {
+		t.Errorf("catalog = %+v, want auth.md", entries)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/catalog/auth?token=" + testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != "# Auth\n" {
+		t.Errorf("catalog file = %q", data)
+	}
+	if code := call(t, ts, http.MethodGet, "/api/catalog/missing", "", nil); code != http.StatusNotFound {
+		t.Errorf("missing catalog file = %d, want 404", code)
+	}
+}
+
+// readEvent returns the next server-sent event's name and data.
+func readEvent(t *testing.T, events *bufio.Scanner) (string, string) {
+	t.Helper()
+	var event, data string
+	for events.Scan() {
+		line := events.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && event != "":
+			return event, data
+		}
+	}
+	t.Fatalf("stream ended: %v", events.Err())
+	return "", ""
+}
+
+func TestLoadToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cmt", "api-token")
+	token, err := LoadToken(path)
+	if err != nil {
+		t.Fatalf("LoadToken() error = %v", err)
+	}
+	if len(token) != 64 {
+		t.Errorf("token = %q, want 32 random bytes in hex", token)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if again, _ := LoadToken(path); again != token {
+		t.Errorf("second LoadToken() = %q, want the stored token", again)
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// Session is the JSON representation of a session.
+type Session struct {
+	ID             string          `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Workflow       string          `json:"workflow"`
+	Status         string          `json:"status"`
+	Directory      string          `json:"directory"`
+	Task           string          `json:"task"`
+	Prefix         string          `json:"prefix,omitempty"`
+	Agent          string          `json:"agent,omitempty"`
+	AgentSessionID string          `json:"agent_session_id,omitempty"`
+	Tmux           string          `json:"tmux,omitempty"` // session:window.pane
+	OutputFile     string          `json:"output_file,omitempty"`
+	PID            int             `json:"pid,omitempty"`
+	ParentID       string          `json:"parent_id,omitempty"`
+	TodoID         string          `json:"todo_id,omitempty"`
+	LoopInterval   string          `json:"loop_interval,omitempty"`
+	PlaybookFile   string          `json:"playbook_file,omitempty"`
+	PlayState      json.RawMessage `json:"play_state,omitempty"`
+	DeletedAt      *time.Time      `json:"deleted_at,omitempty"`
+}
+
+// NewSession returns the JSON representation of s.
+func NewSession(s *db.Session) Session {
+	j := Session{
+		ID:             s.ID,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+		Workflow:       string(s.WorkflowType),
+		Status:         string(s.Status),
+		Directory:      s.WorkingDirectory,
+		Task:           s.TaskDescription,
+		Prefix:         s.Prefix,
+		Agent:          s.Agent,
+		AgentSessionID: s.ClaudeSessionID,
+		OutputFile:     s.OutputFile,
+		PID:            s.PID,
+		ParentID:       s.ParentID,
+		TodoID:         s.TodoID,
+		LoopInterval:   s.LoopInterval,
+		PlaybookFile:   s.PlaybookFile,
+		DeletedAt:      s.DeletedAt,
+	}
+	if s.HasTmuxLocation() {
+		j.Tmux = fmt.Sprintf("%s:%d.%d", s.TmuxSession, s.TmuxWindow, s.TmuxPane)
+	}
+	if s.PlayState != "" && json.Valid([]byte(s.PlayState)) {
+		j.PlayState = json.RawMessage(s.PlayState)
+	}
+	return j
+}
+
+// Todo is the JSON representation of a todo, as printed by cmt todo list.
+type Todo struct {
+	ID             string  `json:"id"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+	Status         string  `json:"status"`
+	Summary        string  `json:"summary"`
+	Date           *string `json:"date,omitempty"`
+	Source         *string `json:"source,omitempty"`
+	URL            *string `json:"url,omitempty"`
+	Channel        *string `json:"channel,omitempty"`
+	Sender         *string `json:"sender,omitempty"`
+	IdempotencyKey *string `json:"idempotency_key,omitempty"`
+	FullMessage    *string `json:"full_message,omitempty"`
+	DeletedAt      *string `json:"deleted_at,omitempty"`
+}
+
+// NewTodo returns the JSON representation of t.
+func NewTodo(t *db.Todo) Todo {
+	j := Todo{
+		ID:             t.ID,
+		CreatedAt:      t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      t.UpdatedAt.Format(time.RFC3339),
+		Status:         string(t.Status),
+		Summary:        t.Summary,
+		Source:         t.Source,
+		URL:            t.URL,
+		Channel:        t.Channel,
+		Sender:         t.Sender,
+		IdempotencyKey: t.IdempotencyKey,
+		FullMessage:    t.FullMessage,
+	}
+	if t.Date != nil {
+		s := t.Date.Format(time.RFC3339)
+		j.Date = &s
+	}
+	if t.DeletedAt != nil {
+		s := t.DeletedAt.Format(time.RFC3339)
+		j.DeletedAt = &s
+	}
+	return j
+}
+
+// Venue is the JSON representation of a venue: a pinned directory or one sessions ran in.
+type Venue struct {
+	Directory string `json:"directory"`
+	Pinned    bool   `json:"pinned"`
+	Sessions  int    `json:"sessions"`
+	Running   int    `json:"running"`
+}
+
+// CatalogEntry is the JSON representation of a cataloged file.
+type CatalogEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
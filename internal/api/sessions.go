@@ -0,0 +1,358 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/vt"
+)
+
+const (
+	// defaultLogBytes is how much of a session's log GET .../log returns by default.
+	defaultLogBytes = 64 * 1024
+	// screenRows and screenCols are the terminal size output is replayed at for
+	// GET .../screen, matching the size headless sessions run at.
+	screenRows = 40
+	screenCols = 120
+	// maxScreenReplay is how much of the log is replayed to build the screen.
+	maxScreenReplay = 256 * 1024
+	// keepaliveInterval is how often idle event streams send a comment, so proxies and
+	// clients don't time them out.
+	keepaliveInterval = 15 * time.Second
+)
+
+func isRunning(s *db.Session) bool {
+	return s.Status == db.StatusWaiting || s.Status == db.StatusWorking
+}
+
+// lookupSession returns the session named by the request path, answering 404 when there is none.
+func (s *Server) lookupSession(w http.ResponseWriter, r *http.Request) *db.Session {
+	session, err := s.db.GetSession(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil
+	}
+	if session == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("session %s not found", r.PathValue("id")))
+		return nil
+	}
+	return session
+}
+
+// GET /api/sessions[?status=...][&deleted=true]
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	var (
+		sessions []*db.Session
+		err      error
+	)
+	status := db.SessionStatus(r.URL.Query().Get("status"))
+	switch {
+	case r.URL.Query().Get("deleted") == "true":
+		sessions, err = s.db.ListDeletedSessions()
+	case status == "", status == db.StatusWaiting, status == db.StatusWorking, status == db.StatusCompleted,
+		status == db.StatusAbandoned, status == db.StatusKilled, status == db.StatusRestored:
+		sessions, err = s.db.ListSessions(status)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status %q", status))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]Session, len(sessions))
+	for i, session := range sessions {
+		out[i] = NewSession(session)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /api/sessions/{id}
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	if session := s.lookupSession(w, r); session != nil {
+		writeJSON(w, http.StatusOK, NewSession(session))
+	}
+}
+
+// POST /api/sessions
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ParentID != "" {
+		if parent, err := s.db.GetSession(req.ParentID); err != nil || parent == nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("parent session %s not found", req.ParentID))
+			return
+		}
+	}
+	if s.launch == nil {
+		writeError(w, http.StatusNotImplemented, "this server cannot start sessions")
+		return
+	}
+
+	started, err := s.launch(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, started)
+}
+
+// POST /api/sessions/{id}/kill
+func (s *Server) killSession(w http.ResponseWriter, r *http.Request) {
+	session := s.lookupSession(w, r)
+	if session == nil {
+		return
+	}
+	if !isRunning(session) {
+		writeError(w, http.StatusConflict, fmt.Sprintf("session %s is not running (%s)", session.ID, session.Status))
+		return
+	}
+	if session.PID > 0 {
+		syscall.Kill(session.PID, syscall.SIGKILL)
+	}
+	if err := s.db.UpdateSessionStatus(session.ID, db.StatusKilled); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	session.Status = db.StatusKilled
+	writeJSON(w, http.StatusOK, NewSession(session))
+}
+
+// GET /api/sessions/{id}/log[?bytes=N][&follow=true]
+//
+// Returns the raw end of the session's output. With follow, streams it as server-sent
+// "output" events until the session stops, then sends "end".
+func (s *Server) sessionLog(w http.ResponseWriter, r *http.Request) {
+	session := s.lookupSession(w, r)
+	if session == nil {
+		return
+	}
+	if session.OutputFile == "" {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("session %s has no output file", session.ID))
+		return
+	}
+	tail := int64(defaultLogBytes)
+	if v := r.URL.Query().Get("bytes"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid bytes %q", v))
+			return
+		}
+		tail = n
+	}
+
+	data, offset, err := readTail(session.OutputFile, tail)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("read output: %v", err))
+		return
+	}
+	if r.URL.Query().Get("follow") != "true" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(data)
+		return
+	}
+
+	stream, ok := newEventStream(w)
+	if !ok {
+		return
+	}
+	if len(data) > 0 {
+		stream.send("output", map[string]string{"data": string(data)})
+	}
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+		data, next, err := readFrom(session.OutputFile, offset)
+		if err == nil {
+			offset = next
+			if len(data) > 0 {
+				stream.send("output", map[string]string{"data": string(data)})
+				continue
+			}
+		}
+		current, err := s.db.GetSession(session.ID)
+		if err != nil || current == nil || !isRunning(current) {
+			status := ""
+			if current != nil {
+				status = string(current.Status)
+			}
+			stream.send("end", map[string]string{"status": status})
+			return
+		}
+	}
+}
+
+// GET /api/sessions/{id}/screen
+//
+// Returns what the session's terminal shows now, replayed through a terminal emulator.
+func (s *Server) sessionScreen(w http.ResponseWriter, r *http.Request) {
+	session := s.lookupSession(w, r)
+	if session == nil {
+		return
+	}
+	if session.OutputFile == "" {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("session %s has no output file", session.ID))
+		return
+	}
+	data, _, err := readTail(session.OutputFile, maxScreenReplay)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("read output: %v", err))
+		return
+	}
+	screen := vt.New(screenRows, screenCols)
+	screen.Write(data)
+	writeJSON(w, http.StatusOK, map[string][]string{"lines": screen.Lines()})
+}
+
+// GET /api/events
+//
+// Streams server-sent "session" events with a session whenever one is created or its
+// status changes, and "session_removed" events when one is deleted.
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	// Sessions that exist when the client connects are not reported
+	seen := make(map[string]string)
+	sessions, err := s.db.ListSessions("")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, session := range sessions {
+		seen[session.ID] = sessionVersion(session)
+	}
+
+	stream, ok := newEventStream(w)
+	if !ok {
+		return
+	}
+	poll := time.NewTicker(s.PollInterval)
+	defer poll.Stop()
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			stream.comment("keepalive")
+			continue
+		case <-poll.C:
+		}
+
+		sessions, err := s.db.ListSessions("")
+		if err != nil {
+			continue
+		}
+		current := make(map[string]bool, len(sessions))
+		// Oldest first, so clients see changes in order
+		for i := len(sessions) - 1; i >= 0; i-- {
+			session := sessions[i]
+			current[session.ID] = true
+			version := sessionVersion(session)
+			if seen[session.ID] == version {
+				continue
+			}
+			seen[session.ID] = version
+			stream.send("session", NewSession(session))
+		}
+		for id := range seen {
+			if !current[id] {
+				delete(seen, id)
+				stream.send("session_removed", map[string]string{"id": id})
+			}
+		}
+	}
+}
+
+// sessionVersion changes whenever the session does. updated_at alone has a resolution
+// of a second, which status changes can come faster than.
+func sessionVersion(session *db.Session) string {
+	return session.UpdatedAt.String() + " " + string(session.Status)
+}
+
+// eventStream writes server-sent events.
+type eventStream struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func newEventStream(w http.ResponseWriter) (*eventStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &eventStream{w: w, flusher: flusher}, true
+}
+
+func (e *eventStream) send(event string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, data)
+	e.flusher.Flush()
+}
+
+func (e *eventStream) comment(text string) {
+	fmt.Fprintf(e.w, ": %s\n\n", text)
+	e.flusher.Flush()
+}
+
+// readTail returns the last n bytes of the file at path, and the offset of its end.
+func readTail(path string, n int64) ([]byte, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	return readFrom(path, max(info.Size()-n, 0))
+}
+
+// readFrom returns the file at path from offset on, and the offset of its end. If the
+// file shrank (a loop iteration recreated it), it is read from the start.
+func readFrom(path string, offset int64) ([]byte, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset, err
+	}
+	return data, offset + int64(len(data)), nil
+}
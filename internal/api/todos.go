@@ -0,0 +1,207 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// TodoInput is the body of POST /api/todos and PATCH /api/todos/{id}. On PATCH, fields
+// left out are unchanged, and an empty string clears an optional field.
+type TodoInput struct {
+	Summary        *string `json:"summary"`
+	Status         *string `json:"status"` // todo or done; PATCH only
+	Date           *string `json:"date"`   // YYYY-MM-DD
+	Source         *string `json:"source"`
+	URL            *string `json:"url"`
+	Channel        *string `json:"channel"`
+	Sender         *string `json:"sender"`
+	IdempotencyKey *string `json:"idempotency_key"` // POST only
+	FullMessage    *string `json:"full_message"`
+}
+
+// apply sets t's fields from the input.
+func (in *TodoInput) apply(t *db.Todo) error {
+	if in.Summary != nil {
+		if *in.Summary == "" {
+			return fmt.Errorf("summary must not be empty")
+		}
+		t.Summary = *in.Summary
+	}
+	if in.Status != nil {
+		switch status := db.TodoStatus(*in.Status); status {
+		case db.TodoStatusTodo, db.TodoStatusDone:
+			t.Status = status
+		default:
+			return fmt.Errorf("invalid status %q: must be todo or done", *in.Status)
+		}
+	}
+	if in.Date != nil {
+		t.Date = nil
+		if *in.Date != "" {
+			parsed, err := time.Parse("2006-01-02", *in.Date)
+			if err != nil {
+				return fmt.Errorf("invalid date %q: expected YYYY-MM-DD format", *in.Date)
+			}
+			t.Date = &parsed
+		}
+	}
+	setOptional(&t.Source, in.Source)
+	setOptional(&t.URL, in.URL)
+	setOptional(&t.Channel, in.Channel)
+	setOptional(&t.Sender, in.Sender)
+	setOptional(&t.FullMessage, in.FullMessage)
+	return nil
+}
+
+func setOptional(field **string, v *string) {
+	if v == nil {
+		return
+	}
+	*field = nil
+	if *v != "" {
+		*field = v
+	}
+}
+
+// lookupTodo returns the todo named by the request path, answering 404 when there is none.
+func (s *Server) lookupTodo(w http.ResponseWriter, r *http.Request) *db.Todo {
+	t, err := s.db.GetTodo(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil
+	}
+	if t == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("todo %s not found", r.PathValue("id")))
+		return nil
+	}
+	return t
+}
+
+// GET /api/todos[?status=todo|done|deleted|all]
+func (s *Server) listTodos(w http.ResponseWriter, r *http.Request) {
+	var status db.TodoStatus
+	switch v := r.URL.Query().Get("status"); v {
+	case "", "todo":
+		status = db.TodoStatusTodo
+	case "all":
+		status = ""
+	case "done":
+		status = db.TodoStatusDone
+	case "deleted":
+		status = db.TodoStatusDeleted
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid status %q: must be todo, done, deleted, or all", v))
+		return
+	}
+
+	todos, err := s.db.ListTodos(status)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]Todo, len(todos))
+	for i, t := range todos {
+		out[i] = NewTodo(t)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// POST /api/todos
+//
+// Answers 201 with the new todo, or 200 with the existing one when a todo with the
+// idempotency key already exists.
+func (s *Server) createTodo(w http.ResponseWriter, r *http.Request) {
+	var in TodoInput
+	if !readJSON(w, r, &in) {
+		return
+	}
+	if in.Summary == nil {
+		writeError(w, http.StatusBadRequest, "summary is required")
+		return
+	}
+	if in.Status != nil {
+		writeError(w, http.StatusBadRequest, "new todos cannot set status")
+		return
+	}
+
+	t := &db.Todo{
+		ID:     uuid.New().String()[:8],
+		Status: db.TodoStatusTodo,
+	}
+	if err := in.apply(t); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if in.IdempotencyKey != nil && *in.IdempotencyKey != "" {
+		t.IdempotencyKey = in.IdempotencyKey
+	}
+	id := t.ID
+	if err := s.db.CreateTodo(t); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	created, err := s.db.GetTodo(t.ID)
+	if err != nil || created == nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("read todo %s: %v", t.ID, err))
+		return
+	}
+	status := http.StatusCreated
+	if t.ID != id {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, NewTodo(created))
+}
+
+// GET /api/todos/{id}
+func (s *Server) getTodo(w http.ResponseWriter, r *http.Request) {
+	if t := s.lookupTodo(w, r); t != nil {
+		writeJSON(w, http.StatusOK, NewTodo(t))
+	}
+}
+
+// PATCH /api/todos/{id}
+func (s *Server) updateTodo(w http.ResponseWriter, r *http.Request) {
+	t := s.lookupTodo(w, r)
+	if t == nil {
+		return
+	}
+	var in TodoInput
+	if !readJSON(w, r, &in) {
+		return
+	}
+	if in.IdempotencyKey != nil {
+		writeError(w, http.StatusBadRequest, "idempotency_key cannot be changed")
+		return
+	}
+	if err := in.apply(t); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.db.UpdateTodo(t); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if updated, err := s.db.GetTodo(t.ID); err == nil && updated != nil {
+		t = updated
+	}
+	writeJSON(w, http.StatusOK, NewTodo(t))
+}
+
+// DELETE /api/todos/{id}
+func (s *Server) deleteTodo(w http.ResponseWriter, r *http.Request) {
+	t := s.lookupTodo(w, r)
+	if t == nil {
+		return
+	}
+	if err := s.db.DeleteTodo(t.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
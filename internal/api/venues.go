@@ -0,0 +1,136 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/catalog"
+)
+
+// GET /api/venues
+//
+// Lists pinned venues and the directories sessions ran in, ordered like the dashboard's
+// venues view: pinned first, then by running and total sessions.
+func (s *Server) listVenues(w http.ResponseWriter, r *http.Request) {
+	pinned, err := s.db.ListVenues()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sessions, err := s.db.ListSessions("")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	byDir := make(map[string]*Venue)
+	for _, p := range pinned {
+		byDir[p.Directory] = &Venue{Directory: p.Directory, Pinned: true}
+	}
+	for _, session := range sessions {
+		if session.WorkingDirectory == "" {
+			continue
+		}
+		v, ok := byDir[session.WorkingDirectory]
+		if !ok {
+			v = &Venue{Directory: session.WorkingDirectory}
+			byDir[session.WorkingDirectory] = v
+		}
+		v.Sessions++
+		if isRunning(session) {
+			v.Running++
+		}
+	}
+
+	venues := make([]Venue, 0, len(byDir))
+	for _, v := range byDir {
+		venues = append(venues, *v)
+	}
+	sort.Slice(venues, func(i, j int) bool {
+		if venues[i].Pinned != venues[j].Pinned {
+			return venues[i].Pinned
+		}
+		if venues[i].Running != venues[j].Running {
+			return venues[i].Running > venues[j].Running
+		}
+		if venues[i].Sessions != venues[j].Sessions {
+			return venues[i].Sessions > venues[j].Sessions
+		}
+		return venues[i].Directory < venues[j].Directory
+	})
+	writeJSON(w, http.StatusOK, venues)
+}
+
+// POST /api/venues {"directory": "/abs/path"}
+func (s *Server) pinVenue(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Directory string `json:"directory"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if !filepath.IsAbs(req.Directory) {
+		writeError(w, http.StatusBadRequest, "directory must be an absolute path")
+		return
+	}
+	dir := filepath.Clean(req.Directory)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("not a directory: %s", dir))
+		return
+	}
+	if err := s.db.AddVenue(dir); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, Venue{Directory: dir, Pinned: true})
+}
+
+// DELETE /api/venues?directory=/abs/path
+func (s *Server) unpinVenue(w http.ResponseWriter, r *http.Request) {
+	dir := r.URL.Query().Get("directory")
+	if dir == "" {
+		writeError(w, http.StatusBadRequest, "directory is required")
+		return
+	}
+	if err := s.db.RemoveVenue(filepath.Clean(dir)); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/catalog
+func (s *Server) listCatalog(w http.ResponseWriter, r *http.Request) {
+	entries, err := catalog.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]CatalogEntry, len(entries))
+	for i, e := range entries {
+		out[i] = CatalogEntry{Name: e.Name, Size: e.Size, ModTime: time.Unix(0, e.ModTime)}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GET /api/catalog/{name}
+//
+// Returns the cataloged file's markdown.
+func (s *Server) getCatalogFile(w http.ResponseWriter, r *http.Request) {
+	path, err := catalog.Path(r.PathValue("name"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Write(data)
+}
@@ -4,6 +4,7 @@ package claude
 import (
 	"context"
 	"os/exec"
+	"regexp"
 
 	"github.com/agentic-camerata/cmt/internal/agent"
 	"github.com/agentic-camerata/cmt/internal/db"
@@ -24,9 +25,26 @@ func NewRunner(database *db.DB) (*Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	base.SessionIDs = sessionIDs
 	return &Runner{base: base}, nil
 }
 
+// sessionIDs finds the Claude session ID in the PTY output ("session_01ABC..."), in
+// JSON output, or else from the transcript Claude writes under ~/.claude/projects.
+var sessionIDs = agent.FirstOf{
+	agent.OutputPattern{Re: regexp.MustCompile(`session_([A-Za-z0-9]{10,})`)},
+	agent.OutputPattern{Re: agent.JSONSessionIDRe},
+	agent.SessionFiles{Dir: projectDir, Ext: ".jsonl", IDRe: regexp.MustCompile(`^(.+)\.jsonl$`)},
+}
+
+// projectDir returns the directory Claude keeps the transcripts of sessions run in
+// workDir in: the path with every non-alphanumeric character replaced by "-".
+func projectDir(workDir string) string {
+	return agent.HomeDir(".claude", "projects", nonAlnum.ReplaceAllString(workDir, "-"))
+}
+
+var nonAlnum = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
 // Run starts a Claude session.
 func (r *Runner) Run(ctx context.Context, opts agent.RunOptions) error {
 	cmd := r.buildCommand(opts)
@@ -78,6 +96,25 @@ func (r *Runner) DefaultModel(cmd agent.CommandType) string {
 	return opusVersioned
 }
 
+// Prompt returns the task description with the Claude prompt prefix applied.
+func (r *Runner) Prompt(opts agent.RunOptions) string {
+	return agent.ApplyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+}
+
+// Capabilities reports the options the Claude CLI honours.
+func (r *Runner) Capabilities() agent.Capabilities {
+	return agent.Capabilities{
+		Binary:           "claude",
+		Resume:           true,
+		ResumePicker:     true,
+		Models:           true,
+		Efforts:          []string{"low", "normal", "medium", "high", "max"},
+		PrintMode:        true,
+		Autonomous:       true,
+		SessionIDCapture: true,
+	}
+}
+
 // buildCommand constructs the claude CLI command from the given options.
 func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 	args := []string{}
@@ -114,7 +151,7 @@ func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 		}
 	}
 
-	taskDescription := agent.ApplyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+	taskDescription := r.Prompt(opts)
 	if taskDescription != "" {
 		args = append(args, taskDescription)
 	}
@@ -7,15 +7,38 @@ import (
 	"github.com/agentic-camerata/cmt/internal/amp"
 	"github.com/agentic-camerata/cmt/internal/claude"
 	"github.com/agentic-camerata/cmt/internal/codex"
+	"github.com/agentic-camerata/cmt/internal/config"
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/fake"
+	"github.com/agentic-camerata/cmt/internal/generic"
 	"github.com/agentic-camerata/cmt/internal/pi"
 )
 
-// newAgent creates an Agent implementation based on the agentType string.
-// Valid values are "pi" (default), "claude", "codex", and "amp".
+// newAgent creates an Agent for the agentType string: a backend name, or a comma-separated
+// fallback chain such as "claude,pi" whose backends are tried in order until one starts.
+// Valid backends are "pi" (default), "claude", "codex", "amp", "fake" (scripted, for tests
+// and demos) and the agents defined in the agents config file.
 func newAgent(agentType string, database *db.DB) (agent.Agent, error) {
-	switch agentType {
-	case "pi", "":
+	names := agent.ParseChain(agentType)
+	if len(names) == 0 {
+		names = []string{"pi"}
+	}
+	agents := make([]agent.Agent, len(names))
+	for i, name := range names {
+		ag, err := newBackend(name, database)
+		if err != nil {
+			return nil, err
+		}
+		agents[i] = ag
+	}
+	return agent.NewChain(names, agents), nil
+}
+
+// newBackend creates the Agent implementation for a single backend name: a built-in
+// backend, or a generic runner for an agent defined in the agents config file.
+func newBackend(name string, database *db.DB) (agent.Agent, error) {
+	switch name {
+	case "pi":
 		return pi.NewRunner(database)
 	case "claude":
 		return claude.NewRunner(database)
@@ -23,7 +46,16 @@ func newAgent(agentType string, database *db.DB) (agent.Agent, error) {
 		return codex.NewRunner(database)
 	case "amp":
 		return amp.NewRunner(database)
-	default:
-		return nil, fmt.Errorf("unknown agent %q (valid: claude, codex, amp, pi)", agentType)
+	case "fake":
+		return fake.NewRunner(database)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if spec, ok := cfg.Agents[name]; ok {
+		return generic.NewRunner(database, spec)
 	}
+	return nil, fmt.Errorf("unknown agent %q (valid: %s)", name, config.ValidAgentsText())
 }
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/config"
+)
+
+// versionTimeout bounds how long an agent CLI may take to print its version.
+const versionTimeout = 5 * time.Second
+
+// AgentsCmd lists the agent backends: whether they are installed, their versions and
+// which options they honour
+type AgentsCmd struct{}
+
+// agentInfo is one row of the agents listing.
+type agentInfo struct {
+	name    string
+	caps    agent.Capabilities
+	path    string // resolved binary path, "" if not installed
+	version string
+}
+
+// Run executes the agents command
+func (c *AgentsCmd) Run(cli *CLI) error {
+	if _, err := config.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	names := config.ValidAgents()
+	infos := make([]agentInfo, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		ag, err := newBackend(name, cli.Database())
+		if err != nil {
+			return err
+		}
+		infos[i] = agentInfo{name: name, caps: ag.Capabilities()}
+		wg.Add(1)
+		go func(info *agentInfo) {
+			defer wg.Done()
+			info.path, info.version = probeAgent(info.caps.Binary)
+		}(&infos[i])
+	}
+	wg.Wait()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tINSTALLED\tVERSION\tCAPABILITIES")
+	for _, info := range infos {
+		installed := info.path
+		switch {
+		case info.caps.Binary == "":
+			installed = "built-in"
+		case installed == "":
+			installed = "no (" + info.caps.Binary + " not on PATH)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", info.name, installed, orDash(info.version),
+			orDash(strings.Join(info.caps.Features(), ", ")))
+	}
+	return w.Flush()
+}
+
+// probeAgent returns where binary is installed and the first line it prints for
+// --version, or empty strings when it is not installed or prints nothing.
+func probeAgent(binary string) (path, version string) {
+	if binary == "" {
+		return "", ""
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return path, string(line)
+		}
+	}
+	return path, ""
+}
@@ -20,10 +20,15 @@ type CLI struct {
 	Play       PlayCmd       `cmd:"" help:"Run a multi-phase playbook workflow"`
 	Sessions   SessionsCmd   `cmd:"" help:"List all sessions"`
 	Jump       JumpCmd       `cmd:"" help:"Jump to a session's tmux location"`
+	Send       SendCmd       `cmd:"" help:"Type a message into a running session"`
 	Dashboard  DashboardCmd  `cmd:"" help:"Open the TUI dashboard"`
 	Todo       TodoCmd       `cmd:"" help:"Manage todos"`
 	Venue      VenueCmd      `cmd:"" help:"Manage pinned venues"`
 	Catalog    CatalogCmd    `cmd:"" help:"Store and reuse research files across projects"`
+	Agents     AgentsCmd     `cmd:"" help:"List agent backends, their versions and capabilities"`
+	Hooks      HooksCmd      `cmd:"" help:"List and trust lifecycle hook scripts"`
+	Serve      ServeCmd      `cmd:"" help:"Serve a local HTTP/JSON API for sessions, todos and venues"`
+	MCP        MCPCmd        `cmd:"mcp" help:"Serve cmt's tools to agents over the Model Context Protocol (stdio)"`
 
 	// Global flags
 	DB         string `help:"Database path" default:"~/.config/cmt/sessions.db" env:"CMT_DB" optional:""`
@@ -31,7 +36,7 @@ type CLI struct {
 	Autonomous bool   `short:"a" help:"Enable autonomous mode (skip permission prompts)" env:"CMT_AUTONOMOUS"`
 	Model      string `help:"Override default model for this invocation" env:"CMT_MODEL" optional:""`
 	Effort     string `help:"Override default effort for this invocation (low, normal, max)" env:"CMT_EFFORT" optional:""`
-	Agent      string `help:"Agent backend to use (claude, codex, amp, pi)" default:"pi" env:"CMT_AGENT" optional:""`
+	Agent      string `help:"Agent backend to use (claude, codex, amp, pi, or one defined in ~/.config/cmt/agents.json), or a comma-separated fallback chain such as claude,pi" default:"pi" env:"CMT_AGENT" optional:""`
 
 	// Shared state (populated by Run)
 	database *db.DB
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/alecthomas/kong"
 
+	"github.com/agentic-camerata/cmt/internal/api"
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/runner"
 )
 
 func TestCLIParsing(t *testing.T) {
@@ -87,6 +90,161 @@ func TestCLIParsing(t *testing.T) {
 			args:    []string{"-v", "sessions"},
 			wantErr: false,
 		},
+		{
+			name:    "play with params",
+			args:    []string{"play", "pb.md", "--set", "ticket=ABC-123", "--set", "area=api"},
+			wantErr: false,
+		},
+		{
+			name:    "play dry run",
+			args:    []string{"play", "pb.md", "--dry-run"},
+			wantErr: false,
+		},
+		{
+			name:    "play validate",
+			args:    []string{"play", "validate", "pb.md"},
+			wantErr: false,
+		},
+		{
+			name:    "play resume",
+			args:    []string{"play", "--resume", "abc123"},
+			wantErr: false,
+		},
+		{
+			name:    "play resume from phase",
+			args:    []string{"play", "--resume", "abc123", "--from-phase", "plan"},
+			wantErr: false,
+		},
+		{
+			name:    "play list",
+			args:    []string{"play", "list"},
+			wantErr: false,
+		},
+		{
+			name:    "play show",
+			args:    []string{"play", "show", "ship-ticket"},
+			wantErr: false,
+		},
+		{
+			name:    "play by name",
+			args:    []string{"play", "ship-ticket"},
+			wantErr: false,
+		},
+		{
+			name:    "play without playbook",
+			args:    []string{"play"},
+			wantErr: false,
+		},
+		{
+			name:    "play across venues",
+			args:    []string{"play", "pb.md", "--venues", "svc-a,svc-b", "--parallel", "3"},
+			wantErr: false,
+		},
+		{
+			name:    "play rerun",
+			args:    []string{"play", "rerun", "abc123", "--phase", "2"},
+			wantErr: false,
+		},
+		{
+			name:    "play rerun without phase",
+			args:    []string{"play", "rerun", "abc123"},
+			wantErr: true,
+		},
+		{
+			name:    "new in a tmux window",
+			args:    []string{"new", "--window", "fix the bug"},
+			wantErr: false,
+		},
+		{
+			name:    "research in a split pane of a tmux session",
+			args:    []string{"research", "--split", "h", "--tmux-session", "agents", "auth"},
+			wantErr: false,
+		},
+		{
+			name:    "invalid split direction",
+			args:    []string{"plan", "--split", "x", "api"},
+			wantErr: true,
+		},
+		{
+			name:    "window and split together",
+			args:    []string{"new", "--window", "--split", "v"},
+			wantErr: true,
+		},
+		{
+			name:    "send command",
+			args:    []string{"send", "abc123", "continue"},
+			wantErr: false,
+		},
+		{
+			name:    "send without enter",
+			args:    []string{"send", "last", "--no-enter", "y"},
+			wantErr: false,
+		},
+		{
+			name:    "send without message",
+			args:    []string{"send", "abc123"},
+			wantErr: true,
+		},
+		{
+			name:    "agents command",
+			args:    []string{"agents"},
+			wantErr: false,
+		},
+		{
+			name:    "hooks list",
+			args:    []string{"hooks", "list"},
+			wantErr: false,
+		},
+		{
+			name:    "hooks trust with directory",
+			args:    []string{"hooks", "trust", "/src/app"},
+			wantErr: false,
+		},
+		{
+			name:    "serve",
+			args:    []string{"serve"},
+			wantErr: false,
+		},
+		{
+			name:    "serve on unix socket",
+			args:    []string{"serve", "--listen", "unix:/tmp/cmt.sock", "--token-file", "/tmp/token"},
+			wantErr: false,
+		},
+		{
+			name:    "todo start",
+			args:    []string{"todo", "start", "ab12cd34"},
+			wantErr: false,
+		},
+		{
+			name:    "todo start research in a venue",
+			args:    []string{"todo", "start", "ab12cd34", "--workflow", "research", "--venue", "/tmp", "--done", "--window"},
+			wantErr: false,
+		},
+		{
+			name:    "todo start invalid workflow",
+			args:    []string{"todo", "start", "ab12cd34", "-w", "implement"},
+			wantErr: true,
+		},
+		{
+			name:    "todo start without id",
+			args:    []string{"todo", "start"},
+			wantErr: true,
+		},
+		{
+			name:    "todo serve",
+			args:    []string{"todo", "serve"},
+			wantErr: false,
+		},
+		{
+			name:    "todo serve with secrets",
+			args:    []string{"todo", "serve", "--listen", ":9000", "--github-secret", "gh", "--slack-secret", "sl"},
+			wantErr: false,
+		},
+		{
+			name:    "mcp",
+			args:    []string{"mcp"},
+			wantErr: false,
+		},
 		{
 			name:    "invalid command",
 			args:    []string{"invalid"},
@@ -187,6 +345,46 @@ func TestCLIEnvVars(t *testing.T) {
 	}
 }
 
+func TestMCPUnderAutonomousSession(t *testing.T) {
+	// An autonomous agent's environment, as set up by the runner
+	t.Setenv(runner.EnvAutonomous, "true")
+	t.Setenv("CMT_AUTONOMOUS", "")
+	os.Unsetenv("CMT_AUTONOMOUS")
+
+	var cli CLI
+	parser, err := kong.New(&cli,
+		kong.Name("cmt"),
+		kong.Exit(func(int) {}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	if _, err := parser.Parse([]string{"mcp"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+	cli.SetDatabase(database)
+
+	if cli.Autonomous {
+		t.Error("Autonomous = true, want the session's autonomy not to apply to cmt itself")
+	}
+	if tools := cli.MCP.tools(&cli, t.TempDir()); !tools.AllowAutonomous {
+		t.Error("AllowAutonomous = false, want autonomous subsessions allowed")
+	}
+	args := cli.launchArgs(api.StartRequest{Command: "research", Task: "x"})
+	if slices.Contains(args, "--autonomous") {
+		t.Errorf("launch args = %v, want no --autonomous for a non-autonomous request", args)
+	}
+	args = cli.launchArgs(api.StartRequest{Command: "research", Task: "x", Autonomous: true})
+	if !slices.Contains(args, "--autonomous") {
+		t.Errorf("launch args = %v, want --autonomous for an autonomous request", args)
+	}
+}
+
 func TestSessionsCommand(t *testing.T) {
 	// Set up test database
 	tmpDir := t.TempDir()
@@ -340,6 +538,82 @@ func TestJumpCommand(t *testing.T) {
 	})
 }
 
+func TestInputTarget(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	for _, s := range []*db.Session{
+		{ID: "plain", WorkflowType: db.WorkflowGeneral, Status: db.StatusWorking},
+		{ID: "done", WorkflowType: db.WorkflowGeneral, Status: db.StatusCompleted},
+		{ID: "play", WorkflowType: db.WorkflowPlay, Status: db.StatusWorking},
+		{ID: "phase-1", WorkflowType: db.WorkflowResearch, Status: db.StatusCompleted, ParentID: "play"},
+		{ID: "phase-2", WorkflowType: db.WorkflowPlan, Status: db.StatusWaiting, ParentID: "play"},
+		{ID: "idle-play", WorkflowType: db.WorkflowPlay, Status: db.StatusWaiting},
+	} {
+		if err := database.CreateSession(s); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "plain", want: "plain"},
+		{id: "done", wantErr: true},
+		{id: "play", want: "phase-2"},
+		{id: "idle-play", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			session, err := findSession(database, tt.id)
+			if err != nil {
+				t.Fatalf("findSession() error = %v", err)
+			}
+			target, err := inputTarget(database, session)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("inputTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && target.ID != tt.want {
+				t.Errorf("inputTarget() = %s, want %s", target.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoTask(t *testing.T) {
+	url := "https://github.com/acme/widgets/issues/42"
+	message := "Still happening on main."
+	summary := "Crash on start"
+
+	tests := []struct {
+		name string
+		todo db.Todo
+		want string
+	}{
+		{name: "summary only", todo: db.Todo{Summary: summary}, want: summary},
+		{name: "with url", todo: db.Todo{Summary: summary, URL: &url}, want: summary + "\n\nURL: " + url},
+		{
+			name: "with url and message",
+			todo: db.Todo{Summary: summary, URL: &url, FullMessage: &message},
+			want: summary + "\n\nURL: " + url + "\n\n" + message,
+		},
+		{name: "message same as summary", todo: db.Todo{Summary: summary, FullMessage: &summary}, want: summary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := todoTask(&tt.todo); got != tt.want {
+				t.Errorf("todoTask() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFormatAge(t *testing.T) {
 	tests := []struct {
 		name    string
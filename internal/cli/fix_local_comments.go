@@ -11,12 +11,17 @@ import (
 type FixLocalCommentsCmd struct {
 	FileFlags
 	LoopFlags
+	TmuxFlags
 	CommentTag string `help:"Comment tag to search for" env:"CMT_COMMENT_TAG" optional:""`
 	Issue      string `arg:"" help:"Issue or problem to investigate and fix"`
 }
 
 // Run executes the fix-local-comments command
 func (c *FixLocalCommentsCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowFix)
+	}
+
 	files, err := c.FileFlags.ResolveFiles()
 	if err != nil {
 		return err
@@ -34,6 +39,8 @@ func (c *FixLocalCommentsCmd) Run(cli *CLI) error {
 		return ag.Run(ctx, agent.RunOptions{
 			Command:         agent.CommandFixLocalComments,
 			WorkflowType:    db.WorkflowFix,
+			SessionID:       c.TmuxFlags.TakeSessionID(),
+			ParentID:        c.TmuxFlags.ParentID,
 			TaskDescription: issue,
 			Model:           cli.Model,
 			Effort:          cli.Effort,
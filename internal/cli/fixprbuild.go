@@ -11,11 +11,16 @@ import (
 type FixPRBuildCmd struct {
 	FileFlags
 	LoopFlags
+	TmuxFlags
 	PRLink string `arg:"" help:"Link to the pull request"`
 }
 
 // Run executes the fix-pr-build command
 func (c *FixPRBuildCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowFix)
+	}
+
 	files, err := c.FileFlags.ResolveFiles()
 	if err != nil {
 		return err
@@ -33,6 +38,8 @@ func (c *FixPRBuildCmd) Run(cli *CLI) error {
 		return ag.Run(ctx, agent.RunOptions{
 			Command:         agent.CommandFixPRBuild,
 			WorkflowType:    db.WorkflowFix,
+			SessionID:       c.TmuxFlags.TakeSessionID(),
+			ParentID:        c.TmuxFlags.ParentID,
 			TaskDescription: prLink,
 			Model:           cli.Model,
 			Effort:          cli.Effort,
@@ -11,11 +11,16 @@ import (
 type FixTestCmd struct {
 	FileFlags
 	LoopFlags
+	TmuxFlags
 	Test string `arg:"" help:"Test name or description of the failing test"`
 }
 
 // Run executes the fix-test command
 func (c *FixTestCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowFix)
+	}
+
 	files, err := c.FileFlags.ResolveFiles()
 	if err != nil {
 		return err
@@ -33,6 +38,8 @@ func (c *FixTestCmd) Run(cli *CLI) error {
 		return ag.Run(ctx, agent.RunOptions{
 			Command:         agent.CommandFixTest,
 			WorkflowType:    db.WorkflowFix,
+			SessionID:       c.TmuxFlags.TakeSessionID(),
+			ParentID:        c.TmuxFlags.ParentID,
 			TaskDescription: test,
 			Model:           cli.Model,
 			Effort:          cli.Effort,
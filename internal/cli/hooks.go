@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/agentic-camerata/cmt/internal/hooks"
+)
+
+// HooksCmd is the parent command for lifecycle hooks
+type HooksCmd struct {
+	List  HooksListCmd  `cmd:"" help:"List the hooks that run for sessions in a directory"`
+	Trust HooksTrustCmd `cmd:"" help:"Allow a venue's .cmt/hooks/ scripts to run"`
+}
+
+// HooksListCmd lists global and venue hooks
+type HooksListCmd struct {
+	Dir string `arg:"" optional:"" default:"." help:"Venue directory (default: current directory)"`
+}
+
+func (c *HooksListCmd) Run(cli *CLI) error {
+	dir, err := filepath.Abs(c.Dir)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	userHooks, err := hooks.Default()
+	if err != nil {
+		return err
+	}
+	list, err := userHooks.List(dir)
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		fmt.Printf("No hooks (add executables to %s or %s)\n", userHooks.GlobalDir, filepath.Join(dir, hooks.VenueDir))
+		return nil
+	}
+	for _, hook := range list {
+		scope := "global"
+		if hook.Venue {
+			scope = "venue"
+			if !hook.Trusted {
+				scope = "venue, untrusted"
+			}
+		}
+		fmt.Printf("%-18s %s (%s)\n", hook.Event, hook.Path, scope)
+	}
+	return nil
+}
+
+// HooksTrustCmd trusts the current contents of a venue's hooks
+type HooksTrustCmd struct {
+	Dir string `arg:"" optional:"" default:"." help:"Venue directory (default: current directory)"`
+}
+
+func (c *HooksTrustCmd) Run(cli *CLI) error {
+	dir, err := filepath.Abs(c.Dir)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	userHooks, err := hooks.Default()
+	if err != nil {
+		return err
+	}
+	trusted, err := userHooks.Trust(dir)
+	if err != nil {
+		return err
+	}
+
+	if len(trusted) == 0 {
+		fmt.Printf("No hooks in %s\n", filepath.Join(dir, hooks.VenueDir))
+		return nil
+	}
+	for _, hook := range trusted {
+		fmt.Printf("Trusted %s\n", hook.Path)
+	}
+	return nil
+}
@@ -13,12 +13,17 @@ import (
 // ImplementCmd starts an implementation-focused agent session
 type ImplementCmd struct {
 	LoopFlags
+	TmuxFlags
 	Dir  string `short:"d" name:"dir" default:"thoughts/shared/plans" help:"Directory to list plans from in the fzf selector"`
 	Plan string `arg:"" optional:"" help:"Path to plan file (uses fzf selector if not provided)"`
 }
 
 // Run executes the implement command
 func (c *ImplementCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowImplement)
+	}
+
 	planPath := c.Plan
 	if planPath == "" {
 		var err error
@@ -44,6 +49,8 @@ func (c *ImplementCmd) Run(cli *CLI) error {
 		return ag.Run(ctx, agent.RunOptions{
 			Command:         agent.CommandImplement,
 			WorkflowType:    db.WorkflowImplement,
+			SessionID:       c.TmuxFlags.TakeSessionID(),
+			ParentID:        c.TmuxFlags.ParentID,
 			TaskDescription: task,
 			Model:           cli.Model,
 			Effort:          cli.Effort,
@@ -13,27 +13,9 @@ type JumpCmd struct {
 
 // Run executes the jump command
 func (c *JumpCmd) Run(cli *CLI) error {
-	var sessionID string
-
-	if c.Session == "last" {
-		session, err := cli.Database().GetLastSession()
-		if err != nil {
-			return fmt.Errorf("get last session: %w", err)
-		}
-		if session == nil {
-			return fmt.Errorf("no sessions found")
-		}
-		sessionID = session.ID
-	} else {
-		sessionID = c.Session
-	}
-
-	session, err := cli.Database().GetSession(sessionID)
+	session, err := findSession(cli.Database(), c.Session)
 	if err != nil {
-		return fmt.Errorf("get session: %w", err)
-	}
-	if session == nil {
-		return fmt.Errorf("session not found: %s", sessionID)
+		return err
 	}
 
 	if !tmux.InTmux() {
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"github.com/agentic-camerata/cmt/internal/mcp"
+	"github.com/agentic-camerata/cmt/internal/runner"
+)
+
+// MCPCmd serves cmt's tools to agents over the Model Context Protocol
+type MCPCmd struct {
+	AllowAutonomous bool `help:"Let agents start subsessions that skip permission prompts (default: only from an autonomous session)" name:"allow-autonomous"`
+}
+
+func (c *MCPCmd) Run(cli *CLI) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	tools := c.tools(cli, workDir)
+
+	version := "dev"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		version = info.Main.Version
+	}
+	// Stdout carries the protocol, so nothing else may write to it
+	return tools.Server(version).Serve(os.Stdin, os.Stdout)
+}
+
+// tools returns the tools served to the agent of the session cmt mcp runs in.
+func (c *MCPCmd) tools(cli *CLI, workDir string) *mcp.Tools {
+	return &mcp.Tools{
+		DB:        cli.Database(),
+		WorkDir:   workDir,
+		SessionID: os.Getenv(runner.EnvSessionID),
+		Launch:    cli.launchSession,
+		// Subsessions get no more autonomy than the session asking for them
+		AllowAutonomous: c.AllowAutonomous || os.Getenv(runner.EnvAutonomous) == "true",
+	}
+}
@@ -11,13 +11,18 @@ import (
 type NewCmd struct {
 	FileFlags
 	LoopFlags
-	Resume   bool   `short:"r" help:"Resume a previous Claude session (interactive picker)"`
-	ResumeID string `help:"Resume a specific Claude session by ID" name:"resume-id"`
+	TmuxFlags
+	Resume   bool   `short:"r" help:"Resume a previous agent session (interactive picker)"`
+	ResumeID string `help:"Resume a specific agent session by ID" name:"resume-id"`
 	Task     string `arg:"" optional:"" help:"Initial task or prompt for Claude"`
 }
 
 // Run executes the new command
 func (c *NewCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowGeneral)
+	}
+
 	files, err := c.FileFlags.ResolveFiles()
 	if err != nil {
 		return err
@@ -42,6 +47,8 @@ func (c *NewCmd) Run(cli *CLI) error {
 		return ag.Run(ctx, agent.RunOptions{
 			Command:         agent.CommandNew,
 			WorkflowType:    db.WorkflowGeneral,
+			SessionID:       c.TmuxFlags.TakeSessionID(),
+			ParentID:        c.TmuxFlags.ParentID,
 			TaskDescription: task,
 			Model:           cli.Model,
 			Effort:          cli.Effort,
@@ -11,11 +11,16 @@ import (
 type PlanCmd struct {
 	FileFlags
 	LoopFlags
+	TmuxFlags
 	Task string `arg:"" help:"Task or feature to plan"`
 }
 
 // Run executes the plan command
 func (c *PlanCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowPlan)
+	}
+
 	files, err := c.FileFlags.ResolveFiles()
 	if err != nil {
 		return err
@@ -33,6 +38,8 @@ func (c *PlanCmd) Run(cli *CLI) error {
 		return ag.Run(ctx, agent.RunOptions{
 			Command:         agent.CommandPlan,
 			WorkflowType:    db.WorkflowPlan,
+			SessionID:       c.TmuxFlags.TakeSessionID(),
+			ParentID:        c.TmuxFlags.ParentID,
 			TaskDescription: task,
 			Model:           cli.Model,
 			Effort:          cli.Effort,
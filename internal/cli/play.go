@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -8,26 +9,26 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/term"
 
 	"github.com/agentic-camerata/cmt/internal/agent"
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/hooks"
+	"github.com/agentic-camerata/cmt/internal/notify"
 	"github.com/agentic-camerata/cmt/internal/plans"
 	"github.com/agentic-camerata/cmt/internal/playbook"
 	"github.com/agentic-camerata/cmt/internal/tmux"
 )
 
-// PlayState holds the state persisted between phases so a play session can be resumed.
-type PlayState struct {
-	NextPhase       int                 `json:"next_phase"`
-	Phases          []playbook.Phase    `json:"phases"`
-	ResearchFiles   []string            `json:"research_files"`
-	PlanFile        string              `json:"plan_file"`
-	TaggedFiles     map[string][]string `json:"tagged_files"`
-	PhaseSessionIDs map[int]string      `json:"phase_session_ids"` // phase index → Claude session ID
-}
+// maxJumps bounds how many times a single goto or on-failure phase may jump,
+// so a loop whose exit condition never becomes true still terminates.
+const maxJumps = 20
 
 // phaseCapturePatterns maps phase types to their file capture regex.
 var phaseCapturePatterns = map[string]*regexp.Regexp{
@@ -36,10 +37,37 @@ var phaseCapturePatterns = map[string]*regexp.Regexp{
 	"review":   regexp.MustCompile(`(thoughts/shared/reviews/\S+\.md)`),
 }
 
-// PlayCmd runs a multi-phase playbook workflow
+// PlayCmd groups the playbook commands; "cmt play FILE|NAME" runs a playbook
 type PlayCmd struct {
-	Playbook string `arg:"" optional:"" help:"Path to playbook markdown file"`
-	Resume   string `name:"resume" short:"r" optional:"*" help:"Resume an abandoned play session; use --resume for interactive selection or --resume SESSION_ID for a specific session"`
+	Run      PlayRunCmd      `cmd:"" default:"withargs" help:"Run a multi-phase playbook workflow"`
+	List     PlayListCmd     `cmd:"" help:"List playbooks in the project and global libraries"`
+	Show     PlayShowCmd     `cmd:"" help:"Print a library playbook"`
+	Validate PlayValidateCmd `cmd:"" help:"Check a playbook for errors without running it"`
+	Rerun    PlayRerunCmd    `cmd:"" help:"Rerun a single phase of a play session"`
+}
+
+// PlayRunCmd runs a multi-phase playbook workflow
+type PlayRunCmd struct {
+	Playbook  string            `arg:"" optional:"" help:"Path to playbook markdown file or library playbook name (omit to pick with fzf)"`
+	Resume    string            `name:"resume" short:"r" optional:"*" help:"Resume an abandoned play session; use --resume for interactive selection or --resume SESSION_ID for a specific session"`
+	Set       map[string]string `name:"set" placeholder:"NAME=VALUE" help:"Set a playbook parameter declared in its frontmatter (repeatable)"`
+	DryRun    bool              `name:"dry-run" help:"Print the expanded phases, file flow and prompts without running any agent"`
+	FromPhase string            `name:"from-phase" placeholder:"N|TAG" help:"With --resume, restart from this phase (number or tag) with the files captured before it; also works on completed sessions"`
+
+	Venues     []string `name:"venues" placeholder:"DIR,..." help:"Run the playbook in each of these directories (globs allowed; \"pinned\" for the pinned venues)"`
+	VenuesFile string   `name:"venues-file" type:"existingfile" help:"Read venue directories from a file, one per line"`
+	Parallel   int      `name:"parallel" default:"1" help:"With --venues, how many venues to run at once"`
+
+	// Set on the per-venue plays started by a --venues fan-out
+	SessionID string `name:"session-id" hidden:""`
+	ParentID  string `name:"parent-id" hidden:""`
+	Headless  bool   `name:"headless" hidden:"" help:"Run every phase headless and auto-terminating"`
+}
+
+// PlayRerunCmd reruns one phase of a play session with the files it originally started with
+type PlayRerunCmd struct {
+	ID    string `arg:"" help:"Play session ID"`
+	Phase string `required:"" placeholder:"N|TAG" help:"Phase to rerun (number or tag)"`
 }
 
 // phaseMapping maps playbook phase types to command/workflow types
@@ -47,38 +75,85 @@ var phaseMapping = map[string]struct {
 	Command  agent.CommandType
 	Workflow db.WorkflowType
 }{
-	"research":     {agent.CommandResearch, db.WorkflowResearch},
-	"plan":         {agent.CommandPlan, db.WorkflowPlan},
-	"implement":    {agent.CommandImplement, db.WorkflowImplement},
-	"new":          {agent.CommandNew, db.WorkflowGeneral},
-	"fix":          {agent.CommandFixTest, db.WorkflowFix},
+	"research":           {agent.CommandResearch, db.WorkflowResearch},
+	"plan":               {agent.CommandPlan, db.WorkflowPlan},
+	"implement":          {agent.CommandImplement, db.WorkflowImplement},
+	"new":                {agent.CommandNew, db.WorkflowGeneral},
+	"fix":                {agent.CommandFixTest, db.WorkflowFix},
 	"fix-local-comments": {agent.CommandFixLocalComments, db.WorkflowFix},
-	"review":       {agent.CommandReview, db.WorkflowReview},
+	"review":             {agent.CommandReview, db.WorkflowReview},
 }
 
 // Run executes the play command
-func (c *PlayCmd) Run(cli *CLI) (retErr error) {
+func (c *PlayRunCmd) Run(cli *CLI) (retErr error) {
 	database := cli.Database()
 
 	if c.Resume != "" {
+		if c.DryRun {
+			return fmt.Errorf("--dry-run cannot be combined with --resume")
+		}
 		return c.doResume(cli, database)
 	}
+	if c.FromPhase != "" {
+		return fmt.Errorf("--from-phase requires --resume")
+	}
+
+	path, err := resolvePlaybook(c.Playbook)
+	if err != nil {
+		return err
+	}
+	c.Playbook = path
 
-	if c.Playbook == "" {
-		return fmt.Errorf("either a playbook file or --resume is required")
+	fm, err := playbook.ReadFrontmatter(c.Playbook)
+	if err != nil {
+		return err
+	}
+	params, err := resolvePlayParams(fm, c.Set)
+	if err != nil {
+		return err
 	}
 
-	pb, err := playbook.Parse(c.Playbook)
+	pb, err := playbook.ParseWithParams(c.Playbook, params)
 	if err != nil {
 		return err
 	}
+	// Tag references may point into nested playbooks, so check them across the whole tree
+	expanded, err := pb.Expand(params)
+	if err != nil {
+		return err
+	}
+
+	if c.DryRun {
+		if len(c.Venues) > 0 || c.VenuesFile != "" {
+			venues, err := resolveVenues(database, c.Venues, c.VenuesFile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Venues: %s\n", strings.Join(venues, ", "))
+		}
+		return printDryRun(cli, pb, params)
+	}
 
 	playbookPath, err := filepath.Abs(c.Playbook)
 	if err != nil {
 		return fmt.Errorf("resolve playbook path: %w", err)
 	}
 
-	sessionID := uuid.New().String()[:8]
+	// Fail before the first phase when an agent cannot honour a phase's options, nested
+	// playbooks included
+	check := &playRun{cli: cli, database: database, pb: expanded, agents: make(map[string]agent.Agent)}
+	if errs, _ := check.checkAgents(); len(errs) > 0 {
+		return errs
+	}
+
+	if len(c.Venues) > 0 || c.VenuesFile != "" {
+		return c.runVenues(cli, database, pb, params)
+	}
+
+	sessionID := c.SessionID
+	if sessionID == "" {
+		sessionID = uuid.New().String()[:8]
+	}
 	var tmuxSession string
 	var tmuxWindow, tmuxPane int
 	if tmux.InTmux() {
@@ -100,11 +175,10 @@ func (c *PlayCmd) Run(cli *CLI) (retErr error) {
 	outputDir := filepath.Join(homeDir, ".config", "cmt", "output")
 	os.MkdirAll(outputDir, 0755) //nolint:errcheck
 
-	// Copy playbook to config dir for future reference
-	playbooksDir := os.Getenv("CMT_PLAYBOOKS_DIR")
-	if playbooksDir == "" {
-		playbooksDir = filepath.Join(homeDir, ".agentic-camerata", "playbooks")
-	}
+	// Copy playbook to config dir for future reference. The copies used to go to
+	// CMT_PLAYBOOKS_DIR, which is now the playbook library, where they would show up
+	// as playbooks; sessions keep the full path of their copy, so old ones still resume.
+	playbooksDir := filepath.Join(homeDir, ".config", "cmt", "playbooks")
 	os.MkdirAll(playbooksDir, 0755) //nolint:errcheck
 
 	savedName := sessionID + "-" + filepath.Base(playbookPath)
@@ -130,25 +204,22 @@ func (c *PlayCmd) Run(cli *CLI) (retErr error) {
 		OutputFile:       filepath.Join(outputDir, sessionID+".log"),
 		PlaybookFile:     savedPath,
 		PID:              os.Getpid(),
+		ParentID:         c.ParentID,
 	}
 	if err := database.CreateSession(session); err != nil {
 		return fmt.Errorf("create play session: %w", err)
 	}
 
-	defer func() {
-		if retErr != nil {
-			database.UpdateSessionStatus(sessionID, db.StatusAbandoned)
-			retErr = fmt.Errorf("%w\n\nto resume this session run: cmt play --resume %s", retErr, sessionID)
-		} else {
-			database.UpdateSessionStatus(sessionID, db.StatusCompleted)
-		}
-	}()
+	defer func() { retErr = finishPlaySession(database, sessionID, retErr) }()
 
-	return runPlaybook(cli, database, sessionID, pb, 0, PlayState{})
+	r := newPlayRun(cli, database, sessionID, pb, playbook.State{Params: params})
+	r.headless = c.Headless
+	return r.run(0)
 }
 
-// doResume resumes an abandoned play session.
-func (c *PlayCmd) doResume(cli *CLI, database *db.DB) (retErr error) {
+// doResume resumes an abandoned play session, or with --from-phase, restarts an abandoned
+// or completed one from an earlier phase.
+func (c *PlayRunCmd) doResume(cli *CLI, database *db.DB) (retErr error) {
 	var session *db.Session
 	var err error
 
@@ -160,61 +231,251 @@ func (c *PlayCmd) doResume(cli *CLI, database *db.DB) (retErr error) {
 		}
 	} else {
 		// Resume by specific session ID
-		session, err = database.GetSession(c.Resume)
+		session, err = getPlaySession(database, c.Resume)
 		if err != nil {
-			return fmt.Errorf("get session: %w", err)
+			return err
 		}
-		if session == nil {
-			return fmt.Errorf("session %s not found", c.Resume)
+		if c.FromPhase == "" && session.Status != db.StatusAbandoned {
+			return fmt.Errorf("session %s is not abandoned (status: %s)", c.Resume, session.Status)
 		}
-		if session.WorkflowType != db.WorkflowPlay {
-			return fmt.Errorf("session %s is not a play session", c.Resume)
+		if c.FromPhase != "" && session.Status != db.StatusAbandoned && session.Status != db.StatusCompleted {
+			return fmt.Errorf("session %s is still running (status: %s)", c.Resume, session.Status)
 		}
-		if session.Status != db.StatusAbandoned {
-			return fmt.Errorf("session %s is not abandoned (status: %s)", c.Resume, session.Status)
+	}
+	if err := enterSessionDir(session); err != nil {
+		return err
+	}
+
+	state, pb, err := loadPlayState(session)
+	if err != nil {
+		return err
+	}
+
+	start := state.NextPhase
+	if c.FromPhase != "" {
+		if start, err = resolvePhaseRef(pb.Phases, c.FromPhase); err != nil {
+			return err
 		}
+		// A parallel group only runs as a whole
+		start = pb.ParallelGroupStart(start)
+		if err := state.RestoreSnapshot(start); err != nil {
+			return err
+		}
+		state.ParallelDone = nil
+	}
+
+	fmt.Printf("Resuming play session %s from phase %d/%d\n", session.ID, start+1, len(pb.Phases))
+
+	if err := reactivatePlaySession(database, session.ID); err != nil {
+		return err
+	}
+	defer func() { retErr = finishPlaySession(database, session.ID, retErr) }()
+
+	return runPlaybook(cli, database, session.ID, pb, start, state)
+}
+
+// Run executes the play rerun command
+func (c *PlayRerunCmd) Run(cli *CLI) (retErr error) {
+	database := cli.Database()
+
+	session, err := getPlaySession(database, c.ID)
+	if err != nil {
+		return err
+	}
+	if session.Status != db.StatusAbandoned && session.Status != db.StatusCompleted {
+		return fmt.Errorf("session %s is still running (status: %s)", c.ID, session.Status)
+	}
+	if err := enterSessionDir(session); err != nil {
+		return err
 	}
 
-	// Parse play state
-	var state PlayState
+	state, pb, err := loadPlayState(session)
+	if err != nil {
+		return err
+	}
+	i, err := resolvePhaseRef(pb.Phases, c.Phase)
+	if err != nil {
+		return err
+	}
+	if _, ok := phaseMapping[pb.Phases[i].Type]; !ok {
+		return fmt.Errorf("phase %d (%s) does not run an agent", i+1, pb.Phases[i].Type)
+	}
+	// The play keeps its resume point: only the rerun phase's results change
+	resumeAt, resumeCtx := state.NextPhase, state.Context.Clone()
+	if err := state.RestoreSnapshot(i); err != nil {
+		return err
+	}
+	state.ParallelDone = nil
+
+	fmt.Printf("Rerunning phase %d/%d (%s) of play session %s\n", i+1, len(pb.Phases), pb.Phases[i].Type, session.ID)
+
+	if err := reactivatePlaySession(database, session.ID); err != nil {
+		return err
+	}
+	defer func() {
+		// A play that had not finished stays resumable where it stopped
+		if retErr == nil && resumeAt < len(pb.Phases) {
+			database.UpdateSessionStatus(session.ID, db.StatusAbandoned) //nolint:errcheck
+			fmt.Printf("to continue the play from phase %d run: cmt play --resume %s\n", resumeAt+1, session.ID)
+			return
+		}
+		retErr = finishPlaySession(database, session.ID, retErr)
+	}()
+
+	r := newPlayRun(cli, database, session.ID, pb, state)
+	r.rerun = true
+	r.rerunPhase, r.resumeAt, r.resumeCtx = i, resumeAt, resumeCtx
+	return r.run(i)
+}
+
+// getPlaySession returns the play session with the given ID.
+func getPlaySession(database *db.DB, id string) (*db.Session, error) {
+	session, err := database.GetSession(id)
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	if session.WorkflowType != db.WorkflowPlay {
+		return nil, fmt.Errorf("session %s is not a play session", id)
+	}
+	return session, nil
+}
+
+// enterSessionDir changes to the directory a play session ran in, so resumed phases
+// resolve relative paths the way the original run did.
+func enterSessionDir(session *db.Session) error {
+	if session.WorkingDirectory == "" {
+		return nil
+	}
+	if err := os.Chdir(session.WorkingDirectory); err != nil {
+		return fmt.Errorf("enter session directory: %w", err)
+	}
+	return nil
+}
+
+// loadPlayState decodes a play session's saved state and the playbook it runs.
+func loadPlayState(session *db.Session) (playbook.State, *playbook.Playbook, error) {
+	var state playbook.State
 	if session.PlayState != "" {
 		if err := json.Unmarshal([]byte(session.PlayState), &state); err != nil {
-			return fmt.Errorf("parse play state: %w", err)
+			return state, nil, fmt.Errorf("parse play state: %w", err)
 		}
 	}
 
 	// Build the playbook: use pre-expanded phases from state, or re-parse from file
-	var pb *playbook.Playbook
 	if len(state.Phases) > 0 {
-		pb = &playbook.Playbook{Phases: state.Phases}
-	} else {
-		if session.PlaybookFile == "" {
-			return fmt.Errorf("session %s has no playbook file", session.ID)
+		return state, &playbook.Playbook{Phases: state.Phases}, nil
+	}
+	if session.PlaybookFile == "" {
+		return state, nil, fmt.Errorf("session %s has no playbook file", session.ID)
+	}
+	pb, err := playbook.ParseWithParams(session.PlaybookFile, state.Params)
+	if err != nil {
+		return state, nil, fmt.Errorf("parse playbook: %w", err)
+	}
+	return state, pb, nil
+}
+
+// resolvePhaseRef returns the index of the phase named by ref: a 1-based phase number or a tag.
+func resolvePhaseRef(phases []playbook.Phase, ref string) (int, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 || n > len(phases) {
+			return 0, fmt.Errorf("phase %d out of range (1-%d)", n, len(phases))
 		}
-		pb, err = playbook.Parse(session.PlaybookFile)
-		if err != nil {
-			return fmt.Errorf("parse playbook: %w", err)
+		return n - 1, nil
+	}
+	for i, p := range phases {
+		if p.Tag == ref {
+			return i, nil
 		}
 	}
+	return 0, fmt.Errorf("no phase with tag %q", ref)
+}
 
-	fmt.Printf("Resuming play session %s from phase %d/%d\n", session.ID, state.NextPhase+1, len(pb.Phases))
-
-	// Re-activate the session
-	if err := database.UpdateSessionStatus(session.ID, db.StatusWorking); err != nil {
+// reactivatePlaySession marks a play session as working again under this process.
+func reactivatePlaySession(database *db.DB, sessionID string) error {
+	if err := database.UpdateSessionStatus(sessionID, db.StatusWorking); err != nil {
 		return fmt.Errorf("update session status: %w", err)
 	}
-	database.UpdateSessionPID(session.ID, os.Getpid()) //nolint:errcheck
+	database.UpdateSessionPID(sessionID, os.Getpid()) //nolint:errcheck
+	return nil
+}
 
-	defer func() {
-		if retErr != nil {
-			database.UpdateSessionStatus(session.ID, db.StatusAbandoned)
-			retErr = fmt.Errorf("%w\n\nto resume this session run: cmt play --resume %s", retErr, session.ID)
-		} else {
-			database.UpdateSessionStatus(session.ID, db.StatusCompleted)
+// finishPlaySession marks a play session completed, or abandoned when err is non-nil,
+// in which case it returns err with a hint on how to resume.
+func finishPlaySession(database *db.DB, sessionID string, err error) error {
+	if err != nil {
+		database.UpdateSessionStatus(sessionID, db.StatusAbandoned)
+		notifyPlay(database, sessionID, notify.EventAbandoned, err.Error())
+		return fmt.Errorf("%w\n\nto resume this session run: cmt play --resume %s", err, sessionID)
+	}
+	database.UpdateSessionStatus(sessionID, db.StatusCompleted)
+	notifyPlay(database, sessionID, notify.EventCompleted, "")
+	return nil
+}
+
+// notifyPlay notifies event for a play session.
+func notifyPlay(database *db.DB, sessionID string, event notify.Event, detail string) {
+	// A broken config is reported when the phases' agents are created
+	notifier, _ := notify.Default()
+	if notifier == nil {
+		return
+	}
+	if session, err := database.GetSession(sessionID); err == nil && session != nil {
+		notifier.Notify(notify.ForSession(session, event, detail))
+	}
+}
+
+// cancelPlayNotification drops a play session's pending waiting notification.
+func cancelPlayNotification(sessionID string) {
+	notifier, _ := notify.Default()
+	notifier.Cancel(sessionID)
+}
+
+// resolvePlayParams returns the values for the parameters a playbook declares, taken from
+// --set, then the frontmatter defaults, then prompting on the terminal for the rest.
+func resolvePlayParams(fm *playbook.Frontmatter, set map[string]string) (map[string]string, error) {
+	if err := checkPlayParamNames(fm, set); err != nil {
+		return nil, err
+	}
+
+	values, missing := fm.Values(set)
+	if len(missing) == 0 {
+		return values, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("missing value for playbook parameters: %s (use --set NAME=VALUE)", strings.Join(missing, ", "))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, name := range missing {
+		fmt.Printf("%s: ", name)
+		line, err := reader.ReadString('\n')
+		value := strings.TrimSpace(line)
+		if value == "" {
+			if err != nil {
+				return nil, fmt.Errorf("read parameter %s: %w", name, err)
+			}
+			return nil, fmt.Errorf("parameter %s is required", name)
 		}
-	}()
+		values[name] = value
+	}
+	return values, nil
+}
 
-	return runPlaybook(cli, database, session.ID, pb, state.NextPhase, state)
+// checkPlayParamNames returns an error if set names a parameter the playbook does not declare.
+func checkPlayParamNames(fm *playbook.Frontmatter, set map[string]string) error {
+	for name := range set {
+		if !slices.Contains(fm.Params, name) {
+			if len(fm.Params) == 0 {
+				return fmt.Errorf("unknown parameter %q: playbook declares no params", name)
+			}
+			return fmt.Errorf("unknown parameter %q (declared: %s)", name, strings.Join(fm.Params, ", "))
+		}
+	}
+	return nil
 }
 
 // selectAbandonedPlaySession finds an abandoned play session interactively.
@@ -256,15 +517,7 @@ func fzfSelect(options []string) (string, error) {
 }
 
 // savePlayState persists play state to the database.
-func savePlayState(database *db.DB, sessionID string, nextPhase int, phases []playbook.Phase, researchFiles []string, planFile string, taggedFiles map[string][]string, phaseSessionIDs map[int]string) {
-	state := PlayState{
-		NextPhase:       nextPhase,
-		Phases:          phases,
-		ResearchFiles:   researchFiles,
-		PlanFile:        planFile,
-		TaggedFiles:     taggedFiles,
-		PhaseSessionIDs: phaseSessionIDs,
-	}
+func savePlayState(database *db.DB, sessionID string, state playbook.State) {
 	if data, err := json.Marshal(state); err == nil {
 		database.UpdatePlayState(sessionID, string(data)) //nolint:errcheck
 	}
@@ -292,48 +545,272 @@ func findLastPhaseWithTag(phases []playbook.Phase, currentIndex int, tag string)
 	return currentIndex
 }
 
-// runPlaybook runs the phases of a playbook starting from startPhase,
-// restoring context from state for resumed sessions.
-func runPlaybook(cli *CLI, database *db.DB, sessionID string, pb *playbook.Playbook, startPhase int, state PlayState) error {
-	agents := make(map[string]agent.Agent)
-	getAgent := func(agentType string) (agent.Agent, error) {
-		if agentType == "" {
-			agentType = cli.Agent
-		}
-		if ag, ok := agents[agentType]; ok {
-			return ag, nil
+// jumpTarget resolves the phase index a goto or on-failure at index from jumps to,
+// counting the jump against maxJumps.
+func jumpTarget(phases []playbook.Phase, from int, tag string, jumps map[int]int) (int, error) {
+	target := -1
+	for j, p := range phases {
+		if p.Tag == tag {
+			target = j
+			break
 		}
-		ag, err := newAgent(agentType, database)
-		if err != nil {
-			return nil, err
+	}
+	if target < 0 {
+		return 0, fmt.Errorf("jump target tag %q not found", tag)
+	}
+	jumps[from]++
+	if jumps[from] > maxJumps {
+		return 0, fmt.Errorf("jump to %q taken more than %d times; giving up", tag, maxJumps)
+	}
+	return target, nil
+}
+
+// evalWhen evaluates a phase's when: condition against the outcomes recorded in state.
+func evalWhen(expr string, phases []playbook.Phase, state playbook.State) (bool, error) {
+	cond, err := playbook.ParseCondition(expr)
+	if err != nil {
+		return false, err
+	}
+	tagStatus := make(map[string]string)
+	for j, p := range phases {
+		if st, ok := state.PhaseStatus[j]; ok && p.Tag != "" && st != playbook.StatusSkipped {
+			tagStatus[p.Tag] = st
 		}
-		agents[agentType] = ag
+	}
+	return cond.Eval(playbook.ConditionEnv{
+		TaggedFiles: state.TaggedFiles,
+		TagStatus:   tagStatus,
+		LastStatus:  state.LastStatus,
+	})
+}
+
+// playRun holds the state of a single playbook execution.
+type playRun struct {
+	cli       *CLI
+	database  *db.DB
+	sessionID string
+	pb        *playbook.Playbook
+	state     playbook.State
+	agents    map[string]agent.Agent
+	rerun     bool           // run only the start phase, without following on-failure
+	headless  bool           // run every phase headless and auto-terminating (per-venue plays)
+	feedback  map[int]string // phase index → reviewer feedback for its next run, after a rejected approval
+
+	// With rerun: the rerun phase, and the phase and context the play resumes with, which
+	// the rerun leaves as they were
+	rerunPhase int
+	resumeAt   int
+	resumeCtx  playbook.Context
+}
+
+// runPlaybook runs the phases of a playbook starting from startPhase,
+// restoring context from state for resumed sessions.
+func runPlaybook(cli *CLI, database *db.DB, sessionID string, pb *playbook.Playbook, startPhase int, state playbook.State) error {
+	return newPlayRun(cli, database, sessionID, pb, state).run(startPhase)
+}
+
+// newPlayRun prepares a playbook execution, initializing any state maps left empty.
+func newPlayRun(cli *CLI, database *db.DB, sessionID string, pb *playbook.Playbook, state playbook.State) *playRun {
+	if state.TaggedFiles == nil {
+		state.TaggedFiles = make(map[string][]string)
+	}
+	if state.PhaseSessionIDs == nil {
+		state.PhaseSessionIDs = make(map[int]string)
+	}
+	if state.PhaseAgents == nil {
+		state.PhaseAgents = make(map[int]string)
+	}
+	if state.PhaseStatus == nil {
+		state.PhaseStatus = make(map[int]string)
+	}
+	if state.Jumps == nil {
+		state.Jumps = make(map[int]int)
+	}
+	if state.ParallelDone == nil {
+		state.ParallelDone = make(map[int]bool)
+	}
+	if state.Snapshots == nil {
+		state.Snapshots = make(map[int]playbook.Context)
+	}
+	return &playRun{
+		cli:       cli,
+		database:  database,
+		sessionID: sessionID,
+		pb:        pb,
+		state:     state,
+		agents:    make(map[string]agent.Agent),
+		feedback:  make(map[int]string),
+	}
+}
+
+// getAgent returns the (cached) agent for a phase's agent: override, defaulting to --agent.
+func (r *playRun) getAgent(agentType string) (agent.Agent, error) {
+	if agentType == "" {
+		agentType = r.cli.Agent
+	}
+	if ag, ok := r.agents[agentType]; ok {
 		return ag, nil
 	}
+	ag, err := newAgent(agentType, r.database)
+	if err != nil {
+		return nil, err
+	}
+	r.agents[agentType] = ag
+	return ag, nil
+}
 
-	researchFiles := state.ResearchFiles
-	planFile := state.PlanFile
-	taggedFiles := state.TaggedFiles
-	if taggedFiles == nil {
-		taggedFiles = make(map[string][]string)
+// save persists the play state with nextPhase as the resume point. A rerun keeps the
+// play's resume point and context, only recording the results of the rerun phase.
+func (r *playRun) save(nextPhase int) {
+	r.state.NextPhase = nextPhase
+	r.state.Phases = r.pb.Phases
+	if r.rerun {
+		state := r.state
+		state.NextPhase = r.resumeAt
+		state.Context = r.rerunContext()
+		savePlayState(r.database, r.sessionID, state)
+		return
 	}
-	phaseSessionIDs := state.PhaseSessionIDs
-	if phaseSessionIDs == nil {
-		phaseSessionIDs = make(map[int]string)
+	savePlayState(r.database, r.sessionID, r.state)
+}
+
+// rerunContext returns the context the play resumes with after rerunning a phase: the one
+// it had before, with the rerun phase's status and captured files in place of its old ones.
+// A plan captured by a later plan phase still takes precedence.
+func (r *playRun) rerunContext() playbook.Context {
+	ctx := r.resumeCtx.Clone()
+	i := r.rerunPhase
+	if st, ok := r.state.PhaseStatus[i]; ok {
+		ctx.PhaseStatus[i] = st
+	}
+	files, ok := r.state.PhaseFiles[i]
+	if !ok {
+		return ctx
+	}
+	old := ctx.PhaseFiles[i]
+	ctx.PhaseFiles[i] = files
+	phase := r.pb.Phases[i]
+	switch phase.Type {
+	case "research":
+		ctx.ResearchFiles = mergeFiles(ctx.ResearchFiles, files)
+	case "plan":
+		if pf := lastPlanFile(files); pf != "" && (ctx.PlanFile == "" || ctx.PlanFile == lastPlanFile(old)) {
+			ctx.PlanFile = pf
+		}
+	}
+	if phase.Tag != "" {
+		ctx.TaggedFiles[phase.Tag] = mergeFiles(ctx.TaggedFiles[phase.Tag], files)
+	}
+	return ctx
+}
+
+// phaseCompleteHook runs the on-phase-complete hooks for phase i, which captured files.
+func (r *playRun) phaseCompleteHook(i int, files []string) {
+	userHooks, err := hooks.Default()
+	if err != nil {
+		return
+	}
+	session, err := r.database.GetSession(r.sessionID)
+	if err != nil || session == nil {
+		return
 	}
+	p := hooks.NewPayload(hooks.OnPhaseComplete, session, r.phaseOptions(r.pb.Phases[i]).AutonomousMode)
+	p.Phase = &hooks.Phase{Number: i + 1, Type: r.pb.Phases[i].Type, Files: files}
+	if err := userHooks.Run(p, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "cmt: %v\n", err)
+	}
+}
+
+// snapshot records the current context as the one phase i starts with.
+func (r *playRun) snapshot(i int) {
+	r.state.Snapshots[i] = r.state.Context.Clone()
+}
 
+func (r *playRun) run(startPhase int) error {
+	pb := r.pb
 	for i := startPhase; i < len(pb.Phases); i++ {
+		// A jump or resume into the middle of a parallel group runs the whole group
+		if !r.rerun {
+			i = pb.ParallelGroupStart(i)
+		}
 		phase := pb.Phases[i]
 		total := len(pb.Phases)
 
+		if r.rerun && i > startPhase {
+			r.save(i)
+			fmt.Printf("\n=== Phase %d rerun complete ===\n", startPhase+1)
+			return nil
+		}
+		r.snapshot(i)
+
+		if phase.Parallel != "" && !r.rerun {
+			end := pb.ParallelGroupEnd(i)
+			if err := r.runParallelGroup(i, end); err != nil {
+				return err
+			}
+			i = end - 1
+			continue
+		}
+
+		if phase.When != "" {
+			ok, err := evalWhen(phase.When, pb.Phases, r.state)
+			if err != nil {
+				return fmt.Errorf("phase %d (%s): when: %w", i+1, phase.Type, err)
+			}
+			if !ok {
+				fmt.Printf("\n=== Phase %d/%d: %s (skipped, when: %s) ===\n", i+1, total, phase.Type, phase.When)
+				r.state.PhaseStatus[i] = playbook.StatusSkipped
+				continue
+			}
+		}
+
 		if phase.Type == "exit" {
 			fmt.Printf("\n=== Phase %d/%d: exit ===\n", i+1, total)
 			break
 		}
 
+		if phase.Type == "goto" {
+			target, err := jumpTarget(pb.Phases, i, phase.Content, r.state.Jumps)
+			if err != nil {
+				return fmt.Errorf("phase %d (goto): %w", i+1, err)
+			}
+			fmt.Printf("\n=== Phase %d/%d: goto %s (phase %d) ===\n", i+1, total, phase.Content, target+1)
+			i = target - 1
+			continue
+		}
+
+		if phase.Type == "approve" {
+			fmt.Printf("\n=== Phase %d/%d: approve ===\n", i+1, total)
+			// Save first so an aborted or interrupted play resumes at this gate
+			r.state.StartPhase(i, time.Now())
+			r.save(i)
+			notifyPlay(r.database, r.sessionID, notify.EventWaiting, fmt.Sprintf("approve phase %d", i+1))
+			start, end, feedback, err := r.approve(i)
+			cancelPlayNotification(r.sessionID)
+			r.state.FinishPhase(i, time.Now())
+			if err != nil {
+				r.save(i)
+				return fmt.Errorf("phase %d (approve): %w", i+1, err)
+			}
+			if feedback == "" {
+				fmt.Printf("--- Approved %s\n", describePhases(pb.Phases, start, end))
+				r.state.PhaseStatus[i] = playbook.StatusDone
+				r.save(i + 1)
+				continue
+			}
+			// A rejected parallel group reruns as a whole, each branch with the feedback
+			fmt.Printf("--- Rejected; rerunning %s with your feedback\n", describePhases(pb.Phases, start, end))
+			for j := start; j < end; j++ {
+				r.feedback[j] = feedback
+			}
+			r.save(start)
+			i = start - 1
+			continue
+		}
+
 		if phase.Type == "play" {
 			fmt.Printf("\n=== Phase %d/%d: play %s ===\n", i+1, total, phase.Content)
-			nestedPB, err := playbook.Parse(phase.Content)
+			nestedPB, err := playbook.ParseNested(phase.Content, r.state.Params)
 			if err != nil {
 				return fmt.Errorf("phase %d (play): %w", i+1, err)
 			}
@@ -346,151 +823,439 @@ func runPlaybook(cli *CLI, database *db.DB, sessionID string, pb *playbook.Playb
 			continue
 		}
 
-		mapping, ok := phaseMapping[phase.Type]
-		if !ok {
+		if _, ok := phaseMapping[phase.Type]; !ok {
 			return fmt.Errorf("unknown phase type: %s", phase.Type)
 		}
 
 		fmt.Printf("\n=== Phase %d/%d: %s ===\n", i+1, total, phase.Type)
 
-		task := phase.Content
+		task, rollbackTo, err := buildPhaseTask(pb.Phases, i, r.state, false)
+		if err != nil {
+			return err
+		}
+		if rollbackTo >= 0 {
+			r.state.PhaseStatus[i] = playbook.StatusRolledBack
+			r.save(rollbackTo)
+			return fmt.Errorf("phase %d (%s): missing prerequisite output; rolling back to phase %d (%s)",
+				i+1, phase.Type, rollbackTo+1, pb.Phases[rollbackTo].Type)
+		}
+		if feedback, ok := r.feedback[i]; ok {
+			task = feedbackPrompt(task, feedback)
+			delete(r.feedback, i)
+		}
+
+		// Save state before running so resume starts from this phase if the agent fails
+		r.state.StartPhase(i, time.Now())
+		r.save(i)
 
-		switch phase.Pick {
-		case "true":
-			picked, err := plans.SelectPlanFile()
-			if err != nil {
-				return fmt.Errorf("phase %d (implement pick): %w", i+1, err)
+		var phaseCaptured []string
+		var interrupted bool
+		var capturedSessionID, capturedAgent string
+
+		ag, err := r.getAgent(phase.Agent)
+		if err != nil {
+			return fmt.Errorf("phase %d (%s): %w", i+1, phase.Type, err)
+		}
+		opts := r.phaseOptions(phase)
+		opts.TaskDescription = task
+		opts.AutoTerminate = r.headless || (i < total-1 && !r.rerun)
+		opts.CapturedFiles = &phaseCaptured
+		opts.CapturedSessionID = &capturedSessionID
+		opts.CapturedAgent = &capturedAgent
+		// If this phase was previously run (e.g. rolled back to), resume its agent session
+		opts.ResumeSessionID = phaseResumeID(ag, r.state.PhaseSessionIDs[i])
+		opts.ResumeAgent = r.state.PhaseAgents[i]
+		opts.Interrupted = &interrupted
+		produced, err := runPhaseAgent(phase, ag, opts, task)
+		r.state.FinishPhase(i, time.Now())
+		if capturedSessionID != "" {
+			r.state.PhaseSessionIDs[i] = capturedSessionID
+			r.state.PhaseAgents[i] = capturedAgent
+		}
+		if err != nil {
+			r.state.PhaseStatus[i] = playbook.StatusFailed
+			r.state.LastStatus = playbook.StatusFailed
+			if phase.OnFailure == "" || r.rerun {
+				r.save(i)
+				return fmt.Errorf("phase %d (%s): %w", i+1, phase.Type, err)
 			}
-			task = picked
-		case "last":
-			picked, err := plans.LatestPlanFile()
-			if err != nil {
-				return fmt.Errorf("phase %d (implement pick:last): %w", i+1, err)
+			target, jerr := jumpTarget(pb.Phases, i, phase.OnFailure, r.state.Jumps)
+			if jerr != nil {
+				r.save(i)
+				return fmt.Errorf("phase %d (%s): %w (on-failure: %v)", i+1, phase.Type, err, jerr)
 			}
-			fmt.Printf("--- Selected latest plan: %s\n", picked)
-			task = picked
+			fmt.Printf("--- Phase %d failed (%v); jumping to %s (phase %d)\n", i+1, err, phase.OnFailure, target+1)
+			r.save(target)
+			i = target - 1
+			continue
+		}
+		if interrupted {
+			fmt.Printf("\n=== Playbook interrupted by user ===\n")
+			return fmt.Errorf("interrupted")
 		}
 
-		var filesToPass []string
-		if len(phase.Uses) > 0 {
-			for _, ref := range phase.Uses {
-				filesToPass = append(filesToPass, taggedFiles[ref]...)
-			}
-			if phase.Type == "implement" && task == "" {
-				if pf := lastPlanFile(filesToPass); pf != "" {
-					task = pf
-					filesToPass = nil
-				} else if len(filesToPass) > 0 {
-					task = filesToPass[len(filesToPass)-1]
-					filesToPass = filesToPass[:len(filesToPass)-1]
-				} else {
-					return fmt.Errorf("implement phase references tags with no captured files")
-				}
-			}
-		} else if phase.Pick == "" {
-			switch phase.Type {
-			case "plan":
-				filesToPass = researchFiles
-			case "implement":
-				if task == "" {
-					if planFile != "" {
-						task = planFile
-					}
-					// If planFile is still empty, the rollback check below will handle it
-				} else if planFile != "" {
-					filesToPass = []string{planFile}
-				}
-			}
+		phaseCaptured = mergeFiles(phaseCaptured, produced)
+		recordPhaseOutputs(i, phase, existingFiles(phaseCaptured), &r.state)
+		if len(phaseCaptured) > 0 {
+			fmt.Printf("--- Captured files: %s\n", strings.Join(phaseCaptured, ", "))
 		}
 
-		// Automatic rollback: detect missing prerequisite outputs before running
-		rollbackTo := -1
-		if phase.Type == "implement" && phase.Pick == "" && len(phase.Uses) == 0 && task == "" && planFile == "" {
-			rollbackTo = findLastPhaseOfType(pb.Phases, i, "plan")
+		r.state.PhaseStatus[i] = playbook.StatusDone
+		r.state.LastStatus = playbook.StatusDone
+
+		// Save state after successful phase so resume skips it next time
+		r.save(i + 1)
+		notifyPlay(r.database, r.sessionID, notify.EventPhaseDone, fmt.Sprintf("phase %d/%d (%s)", i+1, total, phase.Type))
+		r.phaseCompleteHook(i, phaseCaptured)
+	}
+
+	if r.rerun {
+		fmt.Printf("\n=== Phase %d rerun complete ===\n", startPhase+1)
+		return nil
+	}
+	fmt.Printf("\n=== Playbook complete (%d phases) ===\n", len(pb.Phases))
+	return nil
+}
+
+// runParallelGroup runs the phases in [start, end) concurrently as headless, auto-terminating
+// sessions and merges each branch's captured files into the play state as it finishes.
+// Branches recorded in state.ParallelDone are skipped, so a resumed play only reruns the
+// branches that did not finish.
+func (r *playRun) runParallelGroup(start, end int) error {
+	pb := r.pb
+	total := len(pb.Phases)
+	group := pb.Phases[start].Parallel
+	fmt.Printf("\n=== Phases %d-%d/%d: parallel group %s ===\n", start+1, end, total, group)
+
+	type branch struct {
+		index       int
+		task        string
+		resumeID    string
+		resumeAgent string
+		ag          agent.Agent
+	}
+	var branches []branch
+	for i := start; i < end; i++ {
+		phase := pb.Phases[i]
+		if r.state.ParallelDone[i] {
+			fmt.Printf("--- [%d %s] already done\n", i+1, phase.Type)
+			continue
 		}
-		for _, ref := range phase.Uses {
-			if len(taggedFiles[ref]) == 0 {
-				rollbackTo = findLastPhaseWithTag(pb.Phases, i, ref)
-				break
+		r.snapshot(i)
+		if phase.When != "" {
+			ok, err := evalWhen(phase.When, pb.Phases, r.state)
+			if err != nil {
+				return fmt.Errorf("phase %d (%s): when: %w", i+1, phase.Type, err)
+			}
+			if !ok {
+				fmt.Printf("--- [%d %s] skipped (when: %s)\n", i+1, phase.Type, phase.When)
+				r.state.PhaseStatus[i] = playbook.StatusSkipped
+				r.state.ParallelDone[i] = true
+				continue
 			}
 		}
+		task, rollbackTo, err := buildPhaseTask(pb.Phases, i, r.state, false)
+		if err != nil {
+			return err
+		}
 		if rollbackTo >= 0 {
-			savePlayState(database, sessionID, rollbackTo, pb.Phases, researchFiles, planFile, taggedFiles, phaseSessionIDs)
+			r.state.PhaseStatus[i] = playbook.StatusRolledBack
+			r.save(rollbackTo)
 			return fmt.Errorf("phase %d (%s): missing prerequisite output; rolling back to phase %d (%s)",
 				i+1, phase.Type, rollbackTo+1, pb.Phases[rollbackTo].Type)
 		}
+		if feedback, ok := r.feedback[i]; ok {
+			task = feedbackPrompt(task, feedback)
+			delete(r.feedback, i)
+		}
+		ag, err := r.getAgent(phase.Agent)
+		if err != nil {
+			return fmt.Errorf("phase %d (%s): %w", i+1, phase.Type, err)
+		}
+		branches = append(branches, branch{index: i, task: task, resumeID: phaseResumeID(ag, r.state.PhaseSessionIDs[i]),
+			resumeAgent: r.state.PhaseAgents[i], ag: ag})
+	}
+
+	for _, b := range branches {
+		r.state.StartPhase(b.index, time.Now())
+	}
+	r.save(start)
+
+	type result struct {
+		index     int
+		captured  []string
+		sessionID string
+		agent     string
+		err       error
+	}
+	results := make(chan result)
+	for _, b := range branches {
+		phase := pb.Phases[b.index]
+		fmt.Printf("--- [%d %s] started (headless)\n", b.index+1, phase.Type)
+		opts := r.phaseOptions(phase)
+		opts.TaskDescription = b.task
+		opts.AutoTerminate = true
+		opts.ResumeSessionID = b.resumeID
+		opts.ResumeAgent = b.resumeAgent
+		opts.Headless = true
+		go func(b branch, phase playbook.Phase, opts agent.RunOptions) {
+			var captured []string
+			var capturedSessionID, capturedAgent string
+			opts.CapturedFiles = &captured
+			opts.CapturedSessionID = &capturedSessionID
+			opts.CapturedAgent = &capturedAgent
+			produced, err := runPhaseAgent(phase, b.ag, opts, b.task)
+			results <- result{index: b.index, captured: mergeFiles(captured, produced), sessionID: capturedSessionID,
+				agent: capturedAgent, err: err}
+		}(b, phase, opts)
+	}
 
-		allFiles := append(phase.Include, filesToPass...)
-		if len(allFiles) > 0 && task != "" {
-			task = PrependFilesToTask(allFiles, task)
+	var failures []string
+	for range branches {
+		res := <-results
+		phase := pb.Phases[res.index]
+		r.state.FinishPhase(res.index, time.Now())
+		if res.sessionID != "" {
+			r.state.PhaseSessionIDs[res.index] = res.sessionID
+			r.state.PhaseAgents[res.index] = res.agent
+		}
+		if res.err != nil {
+			fmt.Printf("--- [%d %s] failed: %v\n", res.index+1, phase.Type, res.err)
+			r.state.PhaseStatus[res.index] = playbook.StatusFailed
+			r.state.LastStatus = playbook.StatusFailed
+			failures = append(failures, fmt.Sprintf("phase %d (%s): %v", res.index+1, phase.Type, res.err))
+			r.save(start)
+			continue
 		}
+		recordPhaseOutputs(res.index, phase, existingFiles(res.captured), &r.state)
+		fmt.Printf("--- [%d %s] done", res.index+1, phase.Type)
+		if len(res.captured) > 0 {
+			fmt.Printf(" (captured: %s)", strings.Join(res.captured, ", "))
+		}
+		fmt.Println()
+		r.state.PhaseStatus[res.index] = playbook.StatusDone
+		r.state.LastStatus = playbook.StatusDone
+		r.state.ParallelDone[res.index] = true
+		r.save(start)
+		notifyPlay(r.database, r.sessionID, notify.EventPhaseDone, fmt.Sprintf("phase %d/%d (%s)", res.index+1, total, phase.Type))
+		r.phaseCompleteHook(res.index, res.captured)
+	}
 
-		// Save state before running so resume starts from this phase if the agent fails
-		savePlayState(database, sessionID, i, pb.Phases, researchFiles, planFile, taggedFiles, phaseSessionIDs)
+	if len(failures) > 0 {
+		return fmt.Errorf("parallel group %s: %d of %d branches failed:\n  %s",
+			group, len(failures), len(branches), strings.Join(failures, "\n  "))
+	}
 
-		var phaseCaptured []string
-		var interrupted bool
-		var capturedSessionID string
+	for i := start; i < end; i++ {
+		delete(r.state.ParallelDone, i)
+	}
+	r.save(end)
+	return nil
+}
 
-		// If this phase was previously run (e.g. rolled back to), resume the Claude session
-		previousClaudeSessionID := phaseSessionIDs[i]
+// phaseOptions returns the run options shared by every agent phase: the command mapping,
+// capture pattern and parent session, with the phase's model, effort and autonomous
+// metadata taking precedence over the global flags.
+func (r *playRun) phaseOptions(phase playbook.Phase) agent.RunOptions {
+	mapping := phaseMapping[phase.Type]
+	opts := agent.RunOptions{
+		Command:        mapping.Command,
+		WorkflowType:   mapping.Workflow,
+		Model:          r.cli.Model,
+		Effort:         r.cli.Effort,
+		AutonomousMode: r.cli.Autonomous,
+		CapturePattern: phaseCapturePatterns[phase.Type],
+		ParentID:       r.sessionID,
+		Headless:       r.headless,
+	}
+	if phase.Model != "" {
+		opts.Model = phase.Model
+	}
+	if phase.Effort != "" {
+		opts.Effort = phase.Effort
+	}
+	if phase.Autonomous != "" {
+		opts.AutonomousMode = phase.Autonomous == "true"
+	}
+	return opts
+}
 
-		ag, err := getAgent(phase.Agent)
-		if err != nil {
-			return fmt.Errorf("phase %d (%s): %w", i+1, phase.Type, err)
+// phaseResumeID returns the session ID to resume a phase's earlier agent session with, or ""
+// if the phase has not run before or its agent cannot resume sessions.
+func phaseResumeID(ag agent.Agent, sessionID string) string {
+	if !ag.Capabilities().Resume {
+		return ""
+	}
+	return sessionID
+}
+
+// runPhaseAgent runs an agent phase and checks its outputs: contracts against the files it
+// created or modified. When a contract is not met, the phase is run once more, resuming its
+// session when possible, with a prompt listing what is missing; if that still falls short
+// the phase fails. It returns the files that satisfied the contracts.
+func runPhaseAgent(phase playbook.Phase, ag agent.Agent, opts agent.RunOptions, task string) ([]string, error) {
+	specs := playbook.ParseOutputSpecs(phase)
+	before := playbook.SnapshotOutputs(specs)
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := phaseContext(phase)
+		err := ag.Run(ctx, opts)
+		cancel()
+		if err != nil || len(specs) == 0 || (opts.Interrupted != nil && *opts.Interrupted) {
+			return nil, err
 		}
-		err = ag.Run(context.Background(), agent.RunOptions{
-			Command:           mapping.Command,
-			WorkflowType:      mapping.Workflow,
-			TaskDescription:   task,
-			Model:             cli.Model,
-			Effort:            cli.Effort,
-			AutoTerminate:     i < total-1,
-			AutonomousMode:    cli.Autonomous,
-			CapturedFiles:     &phaseCaptured,
-			CapturePattern:    phaseCapturePatterns[phase.Type],
-			CapturedSessionID: &capturedSessionID,
-			ResumeSessionID:   previousClaudeSessionID,
-			ParentID:          sessionID,
-			Interrupted:       &interrupted,
-		})
-		if err != nil {
-			return fmt.Errorf("phase %d (%s): %w", i+1, phase.Type, err)
+
+		files, problems := playbook.CheckOutputs(specs, before)
+		if len(problems) == 0 {
+			return files, nil
 		}
-		if interrupted {
-			fmt.Printf("\n=== Playbook interrupted by user ===\n")
-			return fmt.Errorf("interrupted")
+		if attempt >= outputRetries {
+			return nil, fmt.Errorf("outputs not produced: %s", strings.Join(problems, "; "))
 		}
+		fmt.Printf("--- Phase outputs incomplete (%s); retrying with a corrective prompt\n", strings.Join(problems, "; "))
+		opts.TaskDescription = correctivePrompt(task, problems)
+		if opts.CapturedSessionID != nil && *opts.CapturedSessionID != "" && ag.Capabilities().Resume {
+			opts.ResumeSessionID = *opts.CapturedSessionID
+			if opts.CapturedAgent != nil {
+				opts.ResumeAgent = *opts.CapturedAgent
+			}
+		}
+	}
+}
 
-		// Store the Claude session ID for this phase (for future rollback/resume)
-		if capturedSessionID != "" {
-			phaseSessionIDs[i] = capturedSessionID
+// outputRetries is how many corrective reruns a phase gets when its outputs: contracts are not met.
+const outputRetries = 1
+
+// correctivePrompt restates a phase task along with the output contracts it failed to meet.
+func correctivePrompt(task string, problems []string) string {
+	var sb strings.Builder
+	sb.WriteString(task)
+	sb.WriteString("\n\nThe previous attempt at this task did not produce the required output files:\n")
+	for _, p := range problems {
+		sb.WriteString("- " + p + "\n")
+	}
+	sb.WriteString("Write the missing files (with the missing sections) before finishing.")
+	return sb.String()
+}
+
+// mergeFiles appends the files in extra that are not already in files.
+func mergeFiles(files, extra []string) []string {
+	for _, f := range extra {
+		if !slices.Contains(files, f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// phaseContext returns the context a phase runs under, bounded by its timeout: metadata.
+func phaseContext(phase playbook.Phase) (context.Context, context.CancelFunc) {
+	if phase.Timeout == "" {
+		return context.WithCancel(context.Background())
+	}
+	// The timeout was validated when the playbook was parsed
+	d, _ := time.ParseDuration(phase.Timeout)
+	return context.WithTimeoutCause(context.Background(), d, fmt.Errorf("timed out after %s", d))
+}
+
+// buildPhaseTask assembles the prompt for the agent phase at index i from its content, its
+// pick: selection and the files captured by earlier phases. rollbackTo is the index of the
+// phase to roll back to when a prerequisite output is missing, or -1. In a dry run, pick:
+// selections are shown as placeholders instead of being made.
+func buildPhaseTask(phases []playbook.Phase, i int, state playbook.State, dryRun bool) (task string, rollbackTo int, err error) {
+	phase := phases[i]
+	task = phase.Content
+
+	switch {
+	case dryRun && phase.Pick == "true":
+		task = "<plan selected with fzf>"
+	case dryRun && phase.Pick == "last":
+		task = "<latest plan in thoughts/shared/plans>"
+	case phase.Pick == "true":
+		picked, err := plans.SelectPlanFile()
+		if err != nil {
+			return "", -1, fmt.Errorf("phase %d (implement pick): %w", i+1, err)
+		}
+		task = picked
+	case phase.Pick == "last":
+		picked, err := plans.LatestPlanFile()
+		if err != nil {
+			return "", -1, fmt.Errorf("phase %d (implement pick:last): %w", i+1, err)
 		}
+		fmt.Printf("--- Selected latest plan: %s\n", picked)
+		task = picked
+	}
 
-		validated := existingFiles(phaseCaptured)
+	var filesToPass []string
+	if len(phase.Uses) > 0 {
+		for _, ref := range phase.Uses {
+			filesToPass = append(filesToPass, state.TaggedFiles[ref]...)
+		}
+		if phase.Type == "implement" && task == "" {
+			if pf := lastPlanFile(filesToPass); pf != "" {
+				task = pf
+				filesToPass = nil
+			} else if len(filesToPass) > 0 {
+				task = filesToPass[len(filesToPass)-1]
+				filesToPass = filesToPass[:len(filesToPass)-1]
+			} else {
+				return "", -1, fmt.Errorf("implement phase references tags with no captured files")
+			}
+		}
+	} else if phase.Pick == "" {
 		switch phase.Type {
-		case "research":
-			researchFiles = append(researchFiles, validated...)
 		case "plan":
-			if pf := lastPlanFile(validated); pf != "" {
-				planFile = pf
+			filesToPass = state.ResearchFiles
+		case "implement":
+			if task == "" {
+				if state.PlanFile != "" {
+					task = state.PlanFile
+				}
+				// If the plan file is still empty, the rollback check below will handle it
+			} else if state.PlanFile != "" {
+				filesToPass = []string{state.PlanFile}
 			}
 		}
+	}
 
-		if phase.Tag != "" {
-			taggedFiles[phase.Tag] = append(taggedFiles[phase.Tag], validated...)
-		}
-
-		if len(phaseCaptured) > 0 {
-			fmt.Printf("--- Captured files: %s\n", strings.Join(phaseCaptured, ", "))
+	// Automatic rollback: detect missing prerequisite outputs before running
+	rollbackTo = -1
+	if phase.Type == "implement" && phase.Pick == "" && len(phase.Uses) == 0 && task == "" && state.PlanFile == "" {
+		rollbackTo = findLastPhaseOfType(phases, i, "plan")
+	}
+	for _, ref := range phase.Uses {
+		if len(state.TaggedFiles[ref]) == 0 {
+			rollbackTo = findLastPhaseWithTag(phases, i, ref)
+			break
 		}
+	}
+	if rollbackTo >= 0 {
+		return "", rollbackTo, nil
+	}
 
-		// Save state after successful phase so resume skips it next time
-		savePlayState(database, sessionID, i+1, pb.Phases, researchFiles, planFile, taggedFiles, phaseSessionIDs)
+	allFiles := append(append([]string{}, phase.Include...), filesToPass...)
+	if len(allFiles) > 0 && task != "" {
+		task = PrependFilesToTask(allFiles, task)
 	}
+	return task, -1, nil
+}
 
-	fmt.Printf("\n=== Playbook complete (%d phases) ===\n", len(pb.Phases))
-	return nil
+// recordPhaseOutputs stores the files captured by the finished phase at index i in state.
+func recordPhaseOutputs(i int, phase playbook.Phase, validated []string, state *playbook.State) {
+	if state.PhaseFiles == nil {
+		state.PhaseFiles = make(map[int][]string)
+	}
+	state.PhaseFiles[i] = validated
+	switch phase.Type {
+	case "research":
+		state.ResearchFiles = append(state.ResearchFiles, validated...)
+	case "plan":
+		if pf := lastPlanFile(validated); pf != "" {
+			state.PlanFile = pf
+		}
+	}
+	if phase.Tag != "" {
+		state.TaggedFiles[phase.Tag] = append(state.TaggedFiles[phase.Tag], validated...)
+	}
 }
 
 // lastPlanFile returns the last captured file matching thoughts/shared/plans/*.md
@@ -0,0 +1,449 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/fake"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+)
+
+func TestResolvePhaseRef(t *testing.T) {
+	phases := []playbook.Phase{
+		{Type: "research", Tag: "notes"},
+		{Type: "plan", Tag: "design"},
+		{Type: "implement"},
+	}
+
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr bool
+	}{
+		{ref: "1", want: 0},
+		{ref: "3", want: 2},
+		{ref: "design", want: 1},
+		{ref: "0", wantErr: true},
+		{ref: "4", wantErr: true},
+		{ref: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := resolvePhaseRef(phases, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolvePhaseRef(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("resolvePhaseRef(%q) = %d, want %d", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+// scriptedAgent runs each call through the next step of a script.
+type scriptedAgent struct {
+	steps []func(opts agent.RunOptions) error
+	calls []agent.RunOptions
+}
+
+func (a *scriptedAgent) Run(ctx context.Context, opts agent.RunOptions) error {
+	a.calls = append(a.calls, opts)
+	if opts.CapturedSessionID != nil {
+		*opts.CapturedSessionID = "agent-session"
+	}
+	return a.steps[len(a.calls)-1](opts)
+}
+
+func (a *scriptedAgent) DefaultModel(agent.CommandType) string  { return "" }
+func (a *scriptedAgent) DefaultEffort(agent.CommandType) string { return "" }
+func (a *scriptedAgent) Prompt(opts agent.RunOptions) string    { return opts.TaskDescription }
+func (a *scriptedAgent) Capabilities() agent.Capabilities {
+	return agent.Capabilities{Resume: true, SessionIDCapture: true}
+}
+
+func TestRunPhaseAgentOutputs(t *testing.T) {
+	dir := t.TempDir()
+	plan := filepath.Join(dir, "plan.md")
+	phase := playbook.Phase{Type: "plan", Outputs: []string{filepath.Join(dir, "*.md") + "; sections=Overview"}}
+	write := func(content string) func(agent.RunOptions) error {
+		return func(agent.RunOptions) error { return os.WriteFile(plan, []byte(content), 0o644) }
+	}
+	noop := func(agent.RunOptions) error { return nil }
+
+	t.Run("retries with a corrective prompt", func(t *testing.T) {
+		os.Remove(plan)
+		ag := &scriptedAgent{steps: []func(agent.RunOptions) error{write("# Plan\n"), write("# Plan\n## Overview\n")}}
+		var sessionID string
+		files, err := runPhaseAgent(phase, ag, agent.RunOptions{CapturedSessionID: &sessionID}, "design it")
+		if err != nil {
+			t.Fatalf("runPhaseAgent: %v", err)
+		}
+		if !slices.Equal(files, []string{plan}) {
+			t.Errorf("files = %v, want [%s]", files, plan)
+		}
+		if len(ag.calls) != 2 {
+			t.Fatalf("agent ran %d times, want 2", len(ag.calls))
+		}
+		retry := ag.calls[1]
+		if !strings.HasPrefix(retry.TaskDescription, "design it") || !strings.Contains(retry.TaskDescription, "Overview") {
+			t.Errorf("corrective prompt = %q", retry.TaskDescription)
+		}
+		if retry.ResumeSessionID != "agent-session" {
+			t.Errorf("retry resume session = %q, want agent-session", retry.ResumeSessionID)
+		}
+	})
+
+	t.Run("fails when the retry falls short", func(t *testing.T) {
+		os.Remove(plan)
+		ag := &scriptedAgent{steps: []func(agent.RunOptions) error{noop, noop}}
+		if _, err := runPhaseAgent(phase, ag, agent.RunOptions{}, "design it"); err == nil {
+			t.Fatal("expected error for missing outputs")
+		}
+		if len(ag.calls) != 2 {
+			t.Errorf("agent ran %d times, want 2", len(ag.calls))
+		}
+	})
+
+	t.Run("agent error is not retried", func(t *testing.T) {
+		ag := &scriptedAgent{steps: []func(agent.RunOptions) error{func(agent.RunOptions) error { return errors.New("boom") }}}
+		if _, err := runPhaseAgent(phase, ag, agent.RunOptions{}, "design it"); err == nil || err.Error() != "boom" {
+			t.Fatalf("err = %v, want boom", err)
+		}
+	})
+}
+
+func TestAskApproval(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantFeedback string
+		wantViews    int
+		wantErr      error
+	}{
+		{name: "approve", input: "a\n"},
+		{name: "view then approve", input: "v\napprove\n", wantViews: 1},
+		{name: "reject with feedback", input: "r\nSplit the migration\ninto two steps\n\n", wantFeedback: "Split the migration\ninto two steps"},
+		{name: "reject without feedback asks again", input: "r\n\nx\na\n"},
+		{name: "abort", input: "b\n", wantErr: errPlayAborted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			views := 0
+			var out strings.Builder
+			feedback, err := askApproval(bufio.NewReader(strings.NewReader(tt.input)), &out, func() { views++ })
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if feedback != tt.wantFeedback {
+				t.Errorf("feedback = %q, want %q", feedback, tt.wantFeedback)
+			}
+			if views != tt.wantViews {
+				t.Errorf("views = %d, want %d", views, tt.wantViews)
+			}
+		})
+	}
+
+	t.Run("end of input", func(t *testing.T) {
+		if _, err := askApproval(bufio.NewReader(strings.NewReader("")), io.Discard, func() {}); err == nil {
+			t.Fatal("expected error at end of input")
+		}
+	})
+}
+
+// fakePlayScript has research write a research file, plan write a plan with an Overview
+// and implement fail.
+const fakePlayScript = `{
+  "auto_terminate_after": "200ms",
+  "steps": [{"output": "working\n"}, {"stay": true}],
+  "commands": {
+    "research": [
+      {"write": "thoughts/shared/research/r.md", "content": "# Research\n"},
+      {"output": "Wrote thoughts/shared/research/r.md\n"},
+      {"stay": true}
+    ],
+    "plan": [
+      {"output": "{prompt}\n"},
+      {"write": "thoughts/shared/plans/p.md", "content": "# Plan\n## Overview\n"},
+      {"output": "Wrote thoughts/shared/plans/p.md\n"},
+      {"stay": true}
+    ],
+    "implement": [{"output": "cannot implement\n"}, {"exit": 1}]
+  }
+}`
+
+func TestPlayWithFakeAgent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	scriptPath := filepath.Join(home, "script.json")
+	writeTestFile(t, scriptPath, fakePlayScript)
+	t.Setenv(fake.EnvScript, scriptPath)
+
+	database, err := db.Open(filepath.Join(home, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+	cli := &CLI{Agent: "fake"}
+	cli.SetDatabase(database)
+
+	tests := []struct {
+		name       string
+		playbook   string
+		wantErr    bool
+		wantStatus map[int]string
+		wantFiles  map[int][]string
+	}{
+		{
+			name:       "research then plan",
+			playbook:   "## Research\nLook around.\n\n## Plan\noutputs: thoughts/shared/plans/*.md; sections=Overview\nWrite a plan.\n",
+			wantStatus: map[int]string{0: playbook.StatusDone, 1: playbook.StatusDone},
+			wantFiles:  map[int][]string{0: {"thoughts/shared/research/r.md"}, 1: {"thoughts/shared/plans/p.md"}},
+		},
+		{
+			name:       "failing phase stops the play",
+			playbook:   "## Research\nLook around.\n\n## Implement\nBuild it.\n\n## Review\nCheck it.\n",
+			wantErr:    true,
+			wantStatus: map[int]string{0: playbook.StatusDone, 1: playbook.StatusFailed},
+			wantFiles:  map[int][]string{0: {"thoughts/shared/research/r.md"}},
+		},
+		{
+			name:       "jump into a parallel group runs the whole group",
+			playbook:   "## Goto\nb\n\n## Research\nparallel: scan\nLook here.\n\n## Research\nparallel: scan\ntag: b\nLook there.\n",
+			wantStatus: map[int]string{1: playbook.StatusDone, 2: playbook.StatusDone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(project, "pb.md")
+			writeTestFile(t, path, tt.playbook)
+
+			cmd := &PlayRunCmd{Playbook: path, SessionID: "fake-" + strings.Fields(tt.name)[0], Headless: true}
+			err := cmd.Run(cli)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			session, _ := database.GetSession(cmd.SessionID)
+			wantSession := db.StatusCompleted
+			if tt.wantErr {
+				wantSession = db.StatusAbandoned
+			}
+			if session == nil || session.Status != wantSession {
+				t.Fatalf("session = %+v, want status %s", session, wantSession)
+			}
+			state, err := playbook.DecodeState(session.PlayState)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range tt.wantStatus {
+				if got := state.PhaseStatus[i]; got != want {
+					t.Errorf("phase %d status = %q, want %q", i+1, got, want)
+				}
+			}
+			for i, want := range tt.wantFiles {
+				if got := state.PhaseFiles[i]; !slices.Equal(got, want) {
+					t.Errorf("phase %d files = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlayRerunKeepsResumePoint(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	scriptPath := filepath.Join(home, "script.json")
+	writeTestFile(t, scriptPath, fakePlayScript)
+	t.Setenv(fake.EnvScript, scriptPath)
+
+	database, err := db.Open(filepath.Join(home, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+	cli := &CLI{Agent: "fake"}
+	cli.SetDatabase(database)
+
+	path := filepath.Join(project, "pb.md")
+	writeTestFile(t, path, "## Research\nLook around.\n\n## Plan\nWrite a plan.\n\n## Implement\nBuild it.\n")
+	run := &PlayRunCmd{Playbook: path, SessionID: "rerun01", Headless: true}
+	if err := run.Run(cli); err == nil {
+		t.Fatal("Run() error = nil, want the implement phase to fail")
+	}
+	state := func() *playbook.State {
+		session, _ := database.GetSession("rerun01")
+		st, err := playbook.DecodeState(session.PlayState)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st
+	}
+	before := state()
+	if before.NextPhase != 2 {
+		t.Fatalf("NextPhase = %d, want 2 (the failed implement phase)", before.NextPhase)
+	}
+
+	// The rerun is interactive, so its research phase ends by exiting
+	writeTestFile(t, scriptPath, `{"steps": [{"exit": 0}], "commands": {"research": [
+	  {"write": "thoughts/shared/research/r2.md", "content": "# Research\n"},
+	  {"output": "Wrote thoughts/shared/research/r2.md\n"},
+	  {"exit": 0}
+	]}}`)
+	if err := (&PlayRerunCmd{ID: "rerun01", Phase: "1"}).Run(cli); err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	after := state()
+	if after.NextPhase != before.NextPhase {
+		t.Errorf("NextPhase after rerun = %d, want %d", after.NextPhase, before.NextPhase)
+	}
+	if !slices.Equal(after.Snapshots[1].PhaseFiles[0], before.Snapshots[1].PhaseFiles[0]) || after.Snapshots[1].PlanFile != before.Snapshots[1].PlanFile {
+		t.Errorf("snapshot of phase 2 changed: %+v, want %+v", after.Snapshots[1], before.Snapshots[1])
+	}
+	if want := "thoughts/shared/research/r2.md"; !slices.Equal(after.PhaseFiles[0], []string{want}) || !slices.Contains(after.ResearchFiles, want) {
+		t.Errorf("phase 1 files = %v, research files = %v, want the rerun's %s", after.PhaseFiles[0], after.ResearchFiles, want)
+	}
+	if after.PlanFile != before.PlanFile {
+		t.Errorf("PlanFile after rerun = %q, want %q", after.PlanFile, before.PlanFile)
+	}
+	if session, _ := database.GetSession("rerun01"); session.Status != db.StatusAbandoned {
+		t.Errorf("status after rerun = %s, want %s so the play can be resumed", session.Status, db.StatusAbandoned)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckAgents(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "pb.md")
+	writeTestFile(t, path, `## Research
+effort: xhigh
+Look around.
+
+## Plan
+agent: amp
+model: opus
+Write a plan.
+
+## Implement
+agent: pi
+effort: xhigh
+Build it.
+`)
+	pb, err := playbook.ParseWithParams(path, nil)
+	if err != nil {
+		t.Fatalf("ParseWithParams() error = %v", err)
+	}
+
+	r := &playRun{cli: &CLI{Agent: "claude"}, pb: pb, agents: make(map[string]agent.Agent)}
+	errs, warnings := r.checkAgents()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `phase 1 (research): claude does not support effort "xhigh"`) {
+		t.Errorf("errors = %v, want claude rejecting effort xhigh", errs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `phase 2 (plan): amp ignores model "opus"`) {
+		t.Errorf("warnings = %q, want amp ignoring the model", warnings)
+	}
+}
+
+func TestPlayChecksNestedAgents(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "nested.md")
+	writeTestFile(t, nested, "## Research\neffort: xhigh\nLook around.\n")
+	main := filepath.Join(dir, "main.md")
+	writeTestFile(t, main, "## Play\n"+nested+"\n")
+
+	database, err := db.Open(filepath.Join(home, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+	cli := &CLI{Agent: "claude"}
+	cli.SetDatabase(database)
+
+	err = (&PlayRunCmd{Playbook: main, SessionID: "nested01"}).Run(cli)
+	if err == nil || !strings.Contains(err.Error(), `claude does not support effort "xhigh"`) {
+		t.Fatalf("Run() error = %v, want the nested phase's effort rejected", err)
+	}
+	if session, _ := database.GetSession("nested01"); session != nil {
+		t.Errorf("session created: %+v, want the play refused before it starts", session)
+	}
+}
+
+func TestResolvePlaybook(t *testing.T) {
+	library := t.TempDir()
+	t.Setenv(playbook.EnvLibraryDir, library)
+	writeTestFile(t, filepath.Join(library, "review.md"), "# Review\n")
+	project := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	writeTestFile(t, "local.md", "# Local\n")
+	os.Mkdir("review", 0755)
+	os.Mkdir("plans", 0755)
+
+	tests := []struct {
+		arg     string
+		want    string
+		wantErr string
+	}{
+		{arg: "local.md", want: "local.md"},
+		{arg: "review", want: filepath.Join(library, "review.md")},
+		{arg: "missing/pb.md", want: "missing/pb.md"},
+		{arg: "./plans", wantErr: "is not a file"},
+		{arg: "plans", wantErr: "neither a file nor in the library"},
+	}
+	for _, tt := range tests {
+		got, err := resolvePlaybook(tt.arg)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolvePlaybook(%q) error = %v, want %q", tt.arg, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolvePlaybook(%q) = %q, %v, want %q", tt.arg, got, err, tt.want)
+		}
+	}
+
+	t.Run("unreadable library", func(t *testing.T) {
+		t.Setenv(playbook.EnvLibraryDir, "local.md")
+		_, err := resolvePlaybook("review")
+		if err == nil || !strings.Contains(err.Error(), "read playbook library") {
+			t.Errorf("resolvePlaybook() error = %v, want the library read error", err)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+)
+
+// errPlayAborted is returned when the reviewer aborts the play at an approval gate.
+var errPlayAborted = errors.New("aborted at approval gate")
+
+// approve pauses the play at the approve phase at index i: it shows the files captured by
+// the phases under review in a pager and asks the user to approve, reject with feedback or
+// abort. It returns the range of the reviewed phases, a single phase or a whole parallel
+// group, and, on rejection, the feedback.
+func (r *playRun) approve(i int) (start, end int, feedback string, err error) {
+	phases := r.pb.Phases
+	start, end = playbook.ReviewedPhases(phases, i)
+	if start < 0 {
+		return -1, -1, "", fmt.Errorf("no earlier agent phase to approve")
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return -1, -1, "", fmt.Errorf("approval needs an interactive terminal")
+	}
+
+	var files []string
+	for j := start; j < end; j++ {
+		files = mergeFiles(files, r.state.PhaseFiles[j])
+	}
+	fmt.Print("Review " + describePhases(phases, start, end))
+	if len(files) > 0 {
+		fmt.Printf(": %s", strings.Join(files, ", "))
+	}
+	fmt.Println()
+	if msg := phases[i].Content; msg != "" {
+		fmt.Println(msg)
+	}
+
+	// Mark the session as waiting on the user while the gate is open, so the dashboard shows it
+	r.database.UpdateSessionStatus(r.sessionID, db.StatusWaiting)       //nolint:errcheck
+	defer r.database.UpdateSessionStatus(r.sessionID, db.StatusWorking) //nolint:errcheck
+
+	view := func() {
+		if err := viewFiles(files); err != nil {
+			fmt.Printf("--- %v\n", err)
+		}
+	}
+	view()
+	feedback, err = askApproval(bufio.NewReader(os.Stdin), os.Stdout, view)
+	return start, end, feedback, err
+}
+
+// describePhases names the phases [start, end): "phase N (type)", or "phases N-M (parallel
+// group name)" for a parallel group.
+func describePhases(phases []playbook.Phase, start, end int) string {
+	if end-start > 1 {
+		return fmt.Sprintf("phases %d-%d (parallel group %s)", start+1, end, phases[start].Parallel)
+	}
+	return fmt.Sprintf("phase %d (%s)", start+1, phases[start].Type)
+}
+
+// askApproval prompts until the user approves (returning ""), rejects with feedback
+// (returning the feedback) or aborts (returning errPlayAborted). view shows the files
+// under review again.
+func askApproval(in *bufio.Reader, out io.Writer, view func()) (string, error) {
+	for {
+		fmt.Fprint(out, "[a]pprove, [r]eject with feedback, [v]iew again, a[b]ort: ")
+		line, err := in.ReadString('\n')
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if answer == "" && err != nil {
+			return "", fmt.Errorf("read approval: %w", err)
+		}
+
+		switch answer {
+		case "a", "approve":
+			return "", nil
+		case "v", "view":
+			view()
+		case "b", "abort":
+			return "", errPlayAborted
+		case "r", "reject":
+			fmt.Fprintln(out, "Feedback for the agent (end with an empty line):")
+			var lines []string
+			for {
+				line, err := in.ReadString('\n')
+				line = strings.TrimRight(line, "\r\n")
+				if line == "" {
+					break
+				}
+				lines = append(lines, line)
+				if err != nil {
+					break
+				}
+			}
+			if len(lines) > 0 {
+				return strings.Join(lines, "\n"), nil
+			}
+			fmt.Fprintln(out, "No feedback given.")
+		default:
+			fmt.Fprintf(out, "Unknown answer %q.\n", answer)
+		}
+	}
+}
+
+// viewFiles opens files in $PAGER (default less).
+func viewFiles(files []string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("the phase captured no files to show")
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	// Run through the shell so PAGER may carry its own arguments
+	cmd := exec.Command("sh", append([]string{"-c", pager + ` "$@"`, "sh"}, files...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pager: %w", err)
+	}
+	return nil
+}
+
+// feedbackPrompt appends a reviewer's rejection feedback to a phase task.
+func feedbackPrompt(task, feedback string) string {
+	return task + "\n\nA reviewer rejected the result of your previous attempt at this task. " +
+		"Revise your work to address their feedback:\n\n" + feedback
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agentic-camerata/cmt/internal/playbook"
+)
+
+// PlayListCmd lists the playbooks in the project and global libraries
+type PlayListCmd struct{}
+
+func (c *PlayListCmd) Run(cli *CLI) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	entries, err := playbook.Library(workDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		global, _ := playbook.GlobalLibraryDir()
+		fmt.Printf("Playbook library is empty (add playbooks to %s or %s)\n", playbook.ProjectLibraryDir, global)
+		return nil
+	}
+
+	// Project playbooks shadow global ones with the same name
+	project := make(map[string]bool)
+	for _, e := range entries {
+		if e.Scope == playbook.ScopeProject {
+			project[e.Name] = true
+		}
+	}
+	anyShadowed := false
+	for _, e := range entries {
+		scope := e.Scope
+		if e.Scope == playbook.ScopeGlobal && project[e.Name] {
+			scope += "*"
+			anyShadowed = true
+		}
+		fmt.Printf("%-30s  %-8s  %s\n", e.Name, scope, e.Description)
+	}
+	if anyShadowed {
+		fmt.Println("\n* shadowed by the project playbook with the same name")
+	}
+	return nil
+}
+
+// PlayShowCmd prints a library playbook
+type PlayShowCmd struct {
+	Name string `arg:"" optional:"" help:"Playbook name (omit to pick with fzf)"`
+}
+
+func (c *PlayShowCmd) Run(cli *CLI) error {
+	path, err := resolvePlaybook(c.Name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read playbook: %w", err)
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// resolvePlaybook turns a playbook argument into a file path. An existing regular file or
+// an argument containing a path separator is used as is, anything else is looked up by
+// name in the library, and an empty argument opens the fzf picker. A path that names
+// something other than a file, such as a directory, is an error.
+func resolvePlaybook(arg string) (string, error) {
+	if arg != "" {
+		info, err := os.Stat(arg)
+		isPath := strings.ContainsRune(arg, filepath.Separator)
+		switch {
+		case err == nil && info.Mode().IsRegular():
+			return arg, nil
+		case err == nil && isPath:
+			return "", fmt.Errorf("playbook %s is not a file", arg)
+		case isPath:
+			return arg, nil
+		}
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get working directory: %w", err)
+	}
+	if arg == "" {
+		entry, err := playbook.SelectFromLibrary(workDir)
+		if err != nil {
+			return "", err
+		}
+		return entry.Path, nil
+	}
+	entry, err := playbook.LookupLibrary(workDir, arg)
+	if errors.Is(err, playbook.ErrNotInLibrary) {
+		return "", fmt.Errorf("playbook %q is neither a file nor in the library (see cmt play list)", arg)
+	}
+	if err != nil {
+		return "", err
+	}
+	return entry.Path, nil
+}
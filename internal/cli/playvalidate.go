@@ -0,0 +1,261 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+)
+
+// PlayValidateCmd checks a playbook and the playbooks it plays, reporting every problem found
+type PlayValidateCmd struct {
+	Playbook string            `arg:"" help:"Path to playbook markdown file or library playbook name"`
+	Set      map[string]string `name:"set" placeholder:"NAME=VALUE" help:"Set a playbook parameter declared in its frontmatter (repeatable)"`
+}
+
+// Run executes the play validate command
+func (c *PlayValidateCmd) Run(cli *CLI) error {
+	path, err := resolvePlaybook(c.Playbook)
+	if err != nil {
+		return err
+	}
+	c.Playbook = path
+
+	fm, err := playbook.ReadFrontmatter(c.Playbook)
+	if err != nil {
+		return reportPlaybookErrors(err)
+	}
+	if err := checkPlayParamNames(fm, c.Set); err != nil {
+		return err
+	}
+
+	// Validation never prompts: parameters without a value are checked with a placeholder
+	values, missing := fm.Values(c.Set)
+	for _, name := range missing {
+		values[name] = "<" + name + ">"
+		fmt.Printf("note: no value for parameter %s; using placeholder %s\n", name, values[name])
+	}
+
+	pb, err := playbook.ParseWithParams(c.Playbook, values)
+	if err == nil {
+		pb, err = pb.Expand(values)
+	}
+	if err != nil {
+		return reportPlaybookErrors(err)
+	}
+
+	r := &playRun{cli: cli, database: cli.Database(), pb: pb, agents: make(map[string]agent.Agent)}
+	errs, warnings := r.checkAgents()
+	for _, w := range warnings {
+		fmt.Println("warning: " + w)
+	}
+	if len(errs) > 0 {
+		return reportPlaybookErrors(errs)
+	}
+
+	fmt.Printf("%s: ok (%d phases)\n", c.Playbook, len(pb.Phases))
+	return nil
+}
+
+// checkAgents checks the options of each agent phase against the capabilities of the
+// agent it runs on. It returns the options that agent would get wrong as errors and the
+// ones it ignores as warnings.
+func (r *playRun) checkAgents() (errs playbook.Errors, warnings []string) {
+	for i, phase := range r.pb.Phases {
+		if _, ok := phaseMapping[phase.Type]; !ok {
+			continue
+		}
+		agentType := phase.Agent
+		if agentType == "" {
+			agentType = r.cli.Agent
+		}
+		ag, err := r.getAgent(phase.Agent)
+		if err != nil {
+			errs = append(errs, &playbook.Error{File: r.pb.File, Msg: fmt.Sprintf("phase %d (%s): %v", i+1, phase.Type, err)})
+			continue
+		}
+		phaseErrs, phaseWarnings := ag.Capabilities().Check(r.phaseOptions(phase))
+		for _, e := range phaseErrs {
+			errs = append(errs, &playbook.Error{File: r.pb.File, Msg: fmt.Sprintf("phase %d (%s): %s %s", i+1, phase.Type, agentType, e)})
+		}
+		for _, w := range phaseWarnings {
+			warnings = append(warnings, fmt.Sprintf("phase %d (%s): %s %s", i+1, phase.Type, agentType, w))
+		}
+	}
+	return errs, warnings
+}
+
+// reportPlaybookErrors prints each problem in a playbook parse error on its own line
+// and returns a summary error.
+func reportPlaybookErrors(err error) error {
+	var errs playbook.Errors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	for _, e := range errs {
+		fmt.Println(e)
+	}
+	if len(errs) == 1 {
+		return fmt.Errorf("1 problem found")
+	}
+	return fmt.Errorf("%d problems found", len(errs))
+}
+
+// printDryRun prints what running pb would do without launching any agent: the phases
+// with nested plays inlined, the agent, model and effort each phase resolves to, the files
+// flowing between tagged phases and the prompt each phase would receive.
+func printDryRun(cli *CLI, pb *playbook.Playbook, params map[string]string) error {
+	expanded, err := pb.Expand(params)
+	if err != nil {
+		return err
+	}
+	phases := expanded.Phases
+
+	fmt.Printf("Playbook: %s (%d phases, dry run)\n", pb.File, len(phases))
+	if len(params) > 0 {
+		var pairs []string
+		for name, value := range params {
+			pairs = append(pairs, name+"="+value)
+		}
+		sort.Strings(pairs)
+		fmt.Printf("Params: %s\n", strings.Join(pairs, ", "))
+	}
+	fmt.Println("Files captured at run time are shown as <phase-N> placeholders.")
+
+	r := &playRun{cli: cli, database: cli.Database(), pb: expanded, agents: make(map[string]agent.Agent)}
+	state := playbook.State{Context: playbook.Context{TaggedFiles: make(map[string][]string)}}
+	producers := make(map[string]int) // tag → index of the phase that captures it
+
+	for i, phase := range phases {
+		header := fmt.Sprintf("\n=== Phase %d/%d: %s", i+1, len(phases), phase.Type)
+		if phase.Tag != "" {
+			header += " [tag: " + phase.Tag + "]"
+		}
+		if phase.Parallel != "" {
+			header += " [parallel: " + phase.Parallel + "]"
+		}
+		fmt.Println(header + " ===")
+		if phase.When != "" {
+			fmt.Printf("when:       %s\n", phase.When)
+		}
+
+		switch phase.Type {
+		case "exit":
+			fmt.Println("stops the playbook")
+			continue
+		case "goto":
+			fmt.Printf("jumps to:   %s\n", describeTag(phases, phase.Content))
+			continue
+		case "approve":
+			if start, end := playbook.ReviewedPhases(phases, i); end-start > 1 {
+				fmt.Printf("pauses for approval of phases %d-%d (parallel group %s); rejecting reruns the group with feedback\n",
+					start+1, end, phases[start].Parallel)
+			} else if start >= 0 {
+				fmt.Printf("pauses for approval of phase %d (%s); rejecting reruns it with feedback\n", start+1, phases[start].Type)
+			}
+			continue
+		}
+		if phase.OnFailure != "" {
+			fmt.Printf("on-failure: %s\n", describeTag(phases, phase.OnFailure))
+		}
+
+		ag, err := r.getAgent(phase.Agent)
+		if err != nil {
+			return fmt.Errorf("phase %d (%s): %w", i+1, phase.Type, err)
+		}
+		agentType := phase.Agent
+		if agentType == "" {
+			agentType = cli.Agent
+		}
+		opts := r.phaseOptions(phase)
+		model := opts.Model
+		if model == "" {
+			model = ag.DefaultModel(opts.Command)
+		}
+		effort := opts.Effort
+		if effort == "" {
+			effort = ag.DefaultEffort(opts.Command)
+		}
+		fmt.Printf("agent:      %s (model: %s, effort: %s, autonomous: %t",
+			agentType, orDefault(model), orDefault(effort), opts.AutonomousMode)
+		if phase.Timeout != "" {
+			fmt.Printf(", timeout: %s", phase.Timeout)
+		}
+		fmt.Println(")")
+		capErrs, capWarnings := ag.Capabilities().Check(opts)
+		for _, e := range append(capErrs, capWarnings...) {
+			fmt.Printf("warning:    %s %s\n", agentType, e)
+		}
+
+		for _, ref := range phase.Uses {
+			if j, ok := producers[ref]; ok {
+				fmt.Printf("uses:       %s <- phase %d (%s)\n", ref, j+1, phases[j].Type)
+			} else {
+				fmt.Printf("uses:       %s <- not captured by any earlier phase\n", ref)
+			}
+		}
+
+		task, rollbackTo, err := buildPhaseTask(phases, i, state, true)
+		switch {
+		case err != nil:
+			fmt.Printf("warning:    %v\n", err)
+		case rollbackTo >= 0:
+			fmt.Printf("warning:    missing prerequisite output; the run would roll back to phase %d (%s)\n",
+				rollbackTo+1, phases[rollbackTo].Type)
+		default:
+			opts.TaskDescription = task
+			fmt.Println("prompt:")
+			for _, line := range strings.Split(ag.Prompt(opts), "\n") {
+				fmt.Println("  " + line)
+			}
+		}
+
+		for _, spec := range playbook.ParseOutputSpecs(phase) {
+			fmt.Printf("outputs:    %s\n", spec)
+		}
+
+		output := dryRunOutput(phase.Type, i)
+		recordPhaseOutputs(i, phase, []string{output}, &state)
+		if phase.Tag != "" {
+			producers[phase.Tag] = i
+			fmt.Printf("captures:   %s -> tag %s\n", output, phase.Tag)
+		}
+	}
+	return nil
+}
+
+// dryRunOutput returns the placeholder path standing in for the file the phase at index i
+// would capture, shaped like the real path so plan files are recognised downstream.
+func dryRunOutput(phaseType string, i int) string {
+	name := fmt.Sprintf("<phase-%d>", i+1)
+	switch phaseType {
+	case "research":
+		return "thoughts/shared/research/" + name + ".md"
+	case "plan":
+		return "thoughts/shared/plans/" + name + ".md"
+	case "review":
+		return "thoughts/shared/reviews/" + name + ".md"
+	}
+	return name
+}
+
+// describeTag returns a tag with the number of the phase that defines it.
+func describeTag(phases []playbook.Phase, tag string) string {
+	for j, p := range phases {
+		if p.Tag == tag {
+			return fmt.Sprintf("%s (phase %d)", tag, j+1)
+		}
+	}
+	return tag + " (not found)"
+}
+
+// orDefault returns s, or a marker that the agent CLI's built-in default applies.
+func orDefault(s string) string {
+	if s == "" {
+		return "agent default"
+	}
+	return s
+}
@@ -0,0 +1,390 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/google/uuid"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/notify"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+	"github.com/agentic-camerata/cmt/internal/tmux"
+)
+
+// pinnedVenues is the --venues value that stands for every pinned venue.
+const pinnedVenues = "pinned"
+
+// venueResult is the outcome of the play run in one venue.
+type venueResult struct {
+	Dir          string
+	SessionID    string
+	OK           bool
+	FailingPhase string   // "N (type)" of the phase the play stopped at, if it failed
+	Captured     []string // files captured by the play's phases, in phase order
+	Err          error    // set when the play could not be started or its session not found
+}
+
+// runVenues fans the playbook out over the --venues directories: each venue gets its own
+// play session, run headless by a child cmt process in that directory, grouped under a
+// parent session. At most --parallel venues run at once. A summary table is printed at the end.
+// The parent session ends completed only if every venue's play did; an interrupt stops
+// the venue plays and leaves it abandoned.
+func (c *PlayRunCmd) runVenues(cli *CLI, database *db.DB, pb *playbook.Playbook, params map[string]string) (retErr error) {
+	venues, err := resolveVenues(database, c.Venues, c.VenuesFile)
+	if err != nil {
+		return err
+	}
+	if c.Parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	// Venue plays run unattended, so the playbook may not stop for input
+	expanded, err := pb.Expand(params)
+	if err != nil {
+		return err
+	}
+	for i, p := range expanded.Phases {
+		if p.Type == "approve" || p.Pick == "true" {
+			return fmt.Errorf("phase %d (%s): approval and pick: true need a terminal and cannot run across venues", i+1, p.Type)
+		}
+	}
+
+	playbookPath, err := filepath.Abs(c.Playbook)
+	if err != nil {
+		return fmt.Errorf("resolve playbook path: %w", err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("find cmt executable: %w", err)
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	parent := &db.Session{
+		ID:               uuid.New().String()[:8],
+		WorkflowType:     db.WorkflowPlay,
+		Status:           db.StatusWorking,
+		WorkingDirectory: workDir,
+		TaskDescription:  fmt.Sprintf("%s across %d venues", playbookPath, len(venues)),
+		Prefix:           os.Getenv("CMT_PREFIX"),
+		PID:              os.Getpid(),
+	}
+	if tmux.InTmux() {
+		if loc, err := tmux.CurrentLocation(); err == nil {
+			parent.TmuxSession, parent.TmuxWindow, parent.TmuxPane = loc.Session, loc.Window, loc.Pane
+		}
+	}
+	if err := database.CreateSession(parent); err != nil {
+		return fmt.Errorf("create play session: %w", err)
+	}
+	defer func() {
+		if retErr != nil {
+			database.UpdateSessionStatus(parent.ID, db.StatusAbandoned) //nolint:errcheck
+			notifyPlay(database, parent.ID, notify.EventAbandoned, retErr.Error())
+			return
+		}
+		database.UpdateSessionStatus(parent.ID, db.StatusCompleted) //nolint:errcheck
+		notifyPlay(database, parent.ID, notify.EventCompleted, "")
+	}()
+
+	// An interrupt is passed on to the venue plays, and venues not yet started are skipped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("=== Running %s in %d venues (session %s, %d at a time) ===\n",
+		filepath.Base(playbookPath), len(venues), parent.ID, c.Parallel)
+
+	labels := venueLabels(venues)
+	results := make([]venueResult, len(venues))
+	var out sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, c.Parallel)
+	for i, dir := range venues {
+		wg.Add(1)
+		go func(i int, dir string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				results[i] = venueResult{Dir: dir, Err: fmt.Errorf("not started: interrupted")}
+				return
+			}
+
+			sessionID := uuid.New().String()[:8]
+			args := append(globalArgs(cli), "play", playbookPath,
+				"--session-id", sessionID, "--parent-id", parent.ID, "--headless")
+			args = append(args, setArgs(params)...)
+			cmd := exec.CommandContext(ctx, exe, args...)
+			cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+			cmd.Dir = dir
+			w := &prefixWriter{prefix: "[" + labels[i] + "] ", out: os.Stdout, mu: &out}
+			cmd.Stdout = w
+			cmd.Stderr = w
+			runErr := cmd.Run()
+			w.Flush()
+
+			results[i] = venueSummary(database, dir, sessionID)
+			if runErr != nil {
+				results[i].OK = false
+			}
+		}(i, dir)
+	}
+	wg.Wait()
+
+	fmt.Println()
+	printVenueSummary(os.Stdout, labels, results)
+
+	failed := 0
+	for _, r := range results {
+		if !r.OK {
+			failed++
+		}
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted: %d of %d venues failed or did not run (resume one with: cmt play --resume SESSION)", failed, len(venues))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d venues failed (resume one with: cmt play --resume SESSION)", failed, len(venues))
+	}
+	return nil
+}
+
+// resolveVenues expands --venues entries and the lines of --venues-file into a list of
+// absolute directories, in order and without duplicates. Entries may be globs, and
+// "pinned" stands for the pinned venues.
+func resolveVenues(database *db.DB, specs []string, file string) ([]string, error) {
+	specs = append([]string(nil), specs...)
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read venues file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				specs = append(specs, line)
+			}
+		}
+	}
+
+	var venues []string
+	seen := make(map[string]bool)
+	add := func(dir string) error {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("resolve venue %s: %w", dir, err)
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return fmt.Errorf("venue is not a directory: %s", dir)
+		}
+		if !seen[abs] {
+			seen[abs] = true
+			venues = append(venues, abs)
+		}
+		return nil
+	}
+
+	for _, spec := range specs {
+		switch {
+		case spec == pinnedVenues:
+			pinned, err := database.ListVenues()
+			if err != nil {
+				return nil, err
+			}
+			for _, v := range pinned {
+				if err := add(v.Directory); err != nil {
+					return nil, err
+				}
+			}
+		case strings.ContainsAny(spec, "*?["):
+			matches, err := filepath.Glob(spec)
+			if err != nil {
+				return nil, fmt.Errorf("venue glob %q: %w", spec, err)
+			}
+			sort.Strings(matches)
+			for _, m := range matches {
+				if info, err := os.Stat(m); err != nil || !info.IsDir() {
+					fmt.Fprintf(os.Stderr, "warning: venue glob %q: skipping %s: not a directory\n", spec, m)
+					continue
+				}
+				if err := add(m); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if err := add(spec); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(venues) == 0 {
+		return nil, fmt.Errorf("no venue directories matched")
+	}
+	return venues, nil
+}
+
+// venueLabels returns short names for venues: their base names, or the full paths when
+// base names collide.
+func venueLabels(venues []string) []string {
+	labels := make([]string, len(venues))
+	seen := make(map[string]bool)
+	for i, dir := range venues {
+		labels[i] = filepath.Base(dir)
+		if seen[labels[i]] {
+			return append([]string(nil), venues...)
+		}
+		seen[labels[i]] = true
+	}
+	return labels
+}
+
+// globalArgs returns the global flags to pass on to a child cmt process. The database
+// path is absolute, since the child runs in another directory.
+func globalArgs(cli *CLI) []string {
+	dbPath := cli.Database().Path()
+	if abs, err := filepath.Abs(dbPath); err == nil {
+		dbPath = abs
+	}
+	args := []string{"--db", dbPath, "--agent", cli.Agent}
+	if cli.Model != "" {
+		args = append(args, "--model", cli.Model)
+	}
+	if cli.Effort != "" {
+		args = append(args, "--effort", cli.Effort)
+	}
+	if cli.Autonomous {
+		args = append(args, "--autonomous")
+	}
+	return args
+}
+
+// setArgs returns --set flags for the given parameter values, sorted by name.
+func setArgs(params map[string]string) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var args []string
+	for _, name := range names {
+		args = append(args, "--set", name+"="+params[name])
+	}
+	return args
+}
+
+// venueSummary reads the outcome of a venue's play session from the database.
+func venueSummary(database *db.DB, dir, sessionID string) venueResult {
+	res := venueResult{Dir: dir, SessionID: sessionID}
+	session, err := database.GetSession(sessionID)
+	if err != nil || session == nil {
+		res.Err = fmt.Errorf("play session was not created")
+		return res
+	}
+	res.OK = session.Status == db.StatusCompleted
+
+	state, err := playbook.DecodeState(session.PlayState)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	indexes := make([]int, 0, len(state.PhaseFiles))
+	for i := range state.PhaseFiles {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		res.Captured = mergeFiles(res.Captured, state.PhaseFiles[i])
+	}
+
+	if !res.OK {
+		failing := state.NextPhase
+		for i := range state.Phases {
+			if st := state.PhaseStatus[i]; st == playbook.StatusFailed || st == playbook.StatusRolledBack {
+				failing = i
+				break
+			}
+		}
+		if failing < len(state.Phases) {
+			res.FailingPhase = fmt.Sprintf("%d (%s)", failing+1, state.Phases[failing].Type)
+		}
+	}
+	return res
+}
+
+// printVenueSummary prints one row per venue: result, session, failing phase and captured files.
+func printVenueSummary(w io.Writer, labels []string, results []venueResult) {
+	width := len("VENUE")
+	for _, l := range labels {
+		width = max(width, len(l))
+	}
+	fmt.Fprintf(w, "%-*s  %-7s  %-8s  %-16s  %s\n", width, "VENUE", "RESULT", "SESSION", "FAILING PHASE", "CAPTURED")
+	for i, r := range results {
+		result := "ok"
+		if !r.OK {
+			result = "failed"
+		}
+		failing := r.FailingPhase
+		if r.Err != nil {
+			failing = r.Err.Error()
+		}
+		captured := strings.Join(r.Captured, ", ")
+		fmt.Fprintf(w, "%-*s  %-7s  %-8s  %-16s  %s\n", width, labels[i], result, r.SessionID, orDash(failing), orDash(captured))
+	}
+}
+
+// orDash returns s, or "-" if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// prefixWriter writes complete lines to out with a prefix, serialised by mu, so the
+// output of concurrent venue plays does not interleave mid-line.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Keep the partial line for the next write
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		w.writeLine(line)
+	}
+}
+
+// Flush writes any partial last line.
+func (w *prefixWriter) Flush() {
+	if w.buf.Len() > 0 {
+		w.writeLine(w.buf.String() + "\n")
+		w.buf.Reset()
+	}
+}
+
+func (w *prefixWriter) writeLine(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprint(w.out, w.prefix+line)
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+)
+
+func TestResolveVenues(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"svc-a", "svc-b", "svc-c", "other"} {
+		os.MkdirAll(filepath.Join(root, dir), 0o755)
+	}
+	os.WriteFile(filepath.Join(root, "svc-file"), nil, 0o644)
+	venuesFile := filepath.Join(root, "venues.txt")
+	os.WriteFile(venuesFile, []byte("# services\n"+filepath.Join(root, "other")+"\n\n"+filepath.Join(root, "svc-a")+"\n"), 0o644)
+
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+	database.AddVenue(filepath.Join(root, "svc-c"))
+
+	in := func(names ...string) []string {
+		var out []string
+		for _, n := range names {
+			out = append(out, filepath.Join(root, n))
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		specs   []string
+		file    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "list", specs: in("svc-b", "svc-a"), want: in("svc-b", "svc-a")},
+		{name: "glob skips files", specs: []string{filepath.Join(root, "svc-*")}, want: in("svc-a", "svc-b", "svc-c")},
+		{name: "pinned", specs: []string{"pinned"}, want: in("svc-c")},
+		{name: "file after flags without duplicates", specs: in("svc-a"), file: venuesFile, want: in("svc-a", "other")},
+		{name: "missing directory", specs: in("missing"), wantErr: true},
+		{name: "nothing matched", specs: []string{filepath.Join(root, "none-*")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveVenues(database, tt.specs, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveVenues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("resolveVenues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVenueLabels(t *testing.T) {
+	if got := venueLabels([]string{"/src/a", "/src/b"}); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("venueLabels() = %v, want base names", got)
+	}
+	venues := []string{"/src/x/api", "/src/y/api"}
+	if got := venueLabels(venues); !slices.Equal(got, venues) {
+		t.Errorf("venueLabels() = %v, want full paths for colliding names", got)
+	}
+}
+
+func TestGlobalArgsAbsoluteDB(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	database, err := db.Open("rel.db")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+	cli := &CLI{DB: "rel.db", Agent: "claude"}
+	cli.SetDatabase(database)
+
+	args := globalArgs(cli)
+	// The temp dir may be behind a symlink, so compare against the resolved working directory
+	cwd, _ := os.Getwd()
+	if want := filepath.Join(cwd, "rel.db"); len(args) < 2 || args[1] != want {
+		t.Errorf("globalArgs() = %v, want --db %s", args, want)
+	}
+}
+
+func TestVenueSummary(t *testing.T) {
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	state := playbook.State{
+		Context: playbook.Context{
+			PhaseStatus: map[int]string{0: playbook.StatusDone, 1: playbook.StatusFailed},
+			PhaseFiles:  map[int][]string{0: {"r.md"}},
+		},
+		NextPhase: 1,
+		Phases:    []playbook.Phase{{Type: "research"}, {Type: "plan"}, {Type: "implement"}},
+	}
+	data, _ := json.Marshal(state)
+	database.CreateSession(&db.Session{ID: "venue-1", WorkflowType: db.WorkflowPlay, Status: db.StatusAbandoned, WorkingDirectory: "/src/a", PlayState: string(data)})
+
+	res := venueSummary(database, "/src/a", "venue-1")
+	if res.OK || res.FailingPhase != "2 (plan)" || !slices.Equal(res.Captured, []string{"r.md"}) {
+		t.Errorf("venueSummary() = %+v", res)
+	}
+
+	if res := venueSummary(database, "/src/b", "missing"); res.OK || res.Err == nil {
+		t.Errorf("venueSummary() for a missing session = %+v, want an error", res)
+	}
+
+	var out strings.Builder
+	printVenueSummary(&out, []string{"a"}, []venueResult{res})
+	if !strings.Contains(out.String(), "FAILING PHASE") || !strings.Contains(out.String(), "failed") {
+		t.Errorf("printVenueSummary() =\n%s", out.String())
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out strings.Builder
+	w := &prefixWriter{prefix: "[a] ", out: &out, mu: &sync.Mutex{}}
+	w.Write([]byte("one\ntw"))
+	w.Write([]byte("o\nthree"))
+	w.Flush()
+
+	if want := "[a] one\n[a] two\n[a] three\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
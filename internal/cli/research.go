@@ -11,11 +11,16 @@ import (
 type ResearchCmd struct {
 	FileFlags
 	LoopFlags
+	TmuxFlags
 	Topic string `arg:"" help:"Topic or area to research"`
 }
 
 // Run executes the research command
 func (c *ResearchCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowResearch)
+	}
+
 	files, err := c.FileFlags.ResolveFiles()
 	if err != nil {
 		return err
@@ -33,6 +38,8 @@ func (c *ResearchCmd) Run(cli *CLI) error {
 		return ag.Run(ctx, agent.RunOptions{
 			Command:         agent.CommandResearch,
 			WorkflowType:    db.WorkflowResearch,
+			SessionID:       c.TmuxFlags.TakeSessionID(),
+			ParentID:        c.TmuxFlags.ParentID,
 			TaskDescription: topic,
 			Model:           cli.Model,
 			Effort:          cli.Effort,
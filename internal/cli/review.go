@@ -10,11 +10,16 @@ import (
 // ReviewCmd starts a session to review changes in the working directory
 type ReviewCmd struct {
 	FileFlags
+	TmuxFlags
 	Focus string `arg:"" optional:"" help:"Optional focus area or context for the review"`
 }
 
 // Run executes the review command
 func (c *ReviewCmd) Run(cli *CLI) error {
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(db.WorkflowReview)
+	}
+
 	files, err := c.FileFlags.ResolveFiles()
 	if err != nil {
 		return err
@@ -30,6 +35,8 @@ func (c *ReviewCmd) Run(cli *CLI) error {
 	return ag.Run(context.Background(), agent.RunOptions{
 		Command:         agent.CommandReview,
 		WorkflowType:    db.WorkflowReview,
+		SessionID:       c.TmuxFlags.TakeSessionID(),
+		ParentID:        c.TmuxFlags.ParentID,
 		TaskDescription: focus,
 		Model:           cli.Model,
 		Effort:          cli.Effort,
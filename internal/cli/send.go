@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/runner"
+)
+
+// SendCmd types a message into a running session, as if it was typed in its terminal
+type SendCmd struct {
+	Session string `arg:"" help:"Session ID to send to (or 'last' for most recent)"`
+	Message string `arg:"" help:"Text to type into the session ('-' reads stdin)"`
+	NoEnter bool   `name:"no-enter" help:"Type the text without pressing Enter"`
+}
+
+// Run executes the send command
+func (c *SendCmd) Run(cli *CLI) error {
+	message := c.Message
+	if message == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading stdin: %w", err)
+		}
+		message = strings.TrimRight(string(data), "\n")
+	}
+
+	session, err := findSession(cli.Database(), c.Session)
+	if err != nil {
+		return err
+	}
+	target, err := inputTarget(cli.Database(), session)
+	if err != nil {
+		return err
+	}
+
+	if err := runner.SendInput(target, message, !c.NoEnter); err != nil {
+		return fmt.Errorf("send to session %s: %w", target.ID, err)
+	}
+	fmt.Printf("Sent to session %s\n", target.ID)
+	return nil
+}
+
+// findSession looks up a session by ID, or the most recent one for "last".
+func findSession(database *db.DB, id string) (*db.Session, error) {
+	if id == "last" {
+		session, err := database.GetLastSession()
+		if err != nil {
+			return nil, fmt.Errorf("get last session: %w", err)
+		}
+		if session == nil {
+			return nil, fmt.Errorf("no sessions found")
+		}
+		return session, nil
+	}
+
+	session, err := database.GetSession(id)
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	if session == nil {
+		return nil, fmt.Errorf("session not found: %s", id)
+	}
+	return session, nil
+}
+
+// inputTarget returns the session that input for session goes to: the session itself, or
+// for a play session, its running phase session. The session must be running.
+func inputTarget(database *db.DB, session *db.Session) (*db.Session, error) {
+	if !isRunning(session) {
+		return nil, fmt.Errorf("session %s is not running (%s)", session.ID, session.Status)
+	}
+	if session.WorkflowType != db.WorkflowPlay {
+		return session, nil
+	}
+
+	// Sessions are listed newest first, so this finds the phase that started last
+	sessions, err := database.ListSessions("")
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		if s.ParentID == session.ID && isRunning(s) {
+			return inputTarget(database, s)
+		}
+	}
+	return nil, fmt.Errorf("play session %s has no running phase to send to", session.ID)
+}
+
+// isRunning reports whether a session's agent is still running.
+func isRunning(session *db.Session) bool {
+	return session.Status == db.StatusWaiting || session.Status == db.StatusWorking
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/agentic-camerata/cmt/internal/api"
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/tmux"
+)
+
+// serveWorkflows maps the commands the API starts sessions with to their workflows.
+var serveWorkflows = map[string]db.WorkflowType{
+	"new":       db.WorkflowGeneral,
+	"research":  db.WorkflowResearch,
+	"plan":      db.WorkflowPlan,
+	"implement": db.WorkflowImplement,
+	"review":    db.WorkflowReview,
+}
+
+// ServeCmd serves the local HTTP/JSON API
+type ServeCmd struct {
+	Listen    string `help:"Address to listen on: host:port, or unix:/path for a Unix socket" default:"127.0.0.1:7777"`
+	TokenFile string `name:"token-file" help:"File holding the API token, created if missing (default: ~/.config/cmt/api-token)" optional:""`
+}
+
+func (c *ServeCmd) Run(cli *CLI) error {
+	tokenFile := c.TokenFile
+	if tokenFile == "" {
+		path, err := api.TokenPath()
+		if err != nil {
+			return err
+		}
+		tokenFile = path
+	}
+	token, err := api.LoadToken(tokenFile)
+	if err != nil {
+		return err
+	}
+
+	ln, err := api.Listen(c.Listen)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Serving the cmt API on %s (token in %s)\n", c.Listen, tokenFile)
+	return serveUntilSignal(ln, api.New(cli.Database(), token, cli.launchSession))
+}
+
+// serveUntilSignal serves handler on ln until SIGINT or SIGTERM.
+func serveUntilSignal(ln net.Listener, handler http.Handler) error {
+	server := &http.Server{Handler: handler}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	go func() {
+		<-sigCh
+		server.Close()
+	}()
+
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
+
+// launchSession starts a session for the API in a new tmux window: of the request's
+// tmux session, of the current one when serving from inside tmux, or of the "cmt"
+// session otherwise. Agent, model, effort and autonomous mode default to cmt serve's.
+func (c *CLI) launchSession(req api.StartRequest) (api.Started, error) {
+	workflow, ok := serveWorkflows[req.Command]
+	if !ok {
+		return api.Started{}, fmt.Errorf("invalid command %q", req.Command)
+	}
+	placement := tmux.Placement{Session: req.TmuxSession, Dir: req.Dir}
+	if placement.Session == "" && !tmux.InTmux() {
+		placement.Session = "cmt"
+	}
+
+	sessionID, loc, err := spawnCmt(placement, workflow, c.launchArgs(req))
+	if err != nil {
+		return api.Started{}, err
+	}
+	return api.Started{ID: sessionID, Tmux: loc.String()}, nil
+}
+
+// launchArgs returns the cmt arguments that start the session req asks for.
+func (c *CLI) launchArgs(req api.StartRequest) []string {
+	args := []string{"--db", c.Database().Path()}
+	for _, opt := range []struct{ flag, value, fallback string }{
+		{"--agent", req.Agent, c.Agent},
+		{"--model", req.Model, c.Model},
+		{"--effort", req.Effort, c.Effort},
+	} {
+		if value := cmp.Or(opt.value, opt.fallback); value != "" {
+			args = append(args, opt.flag, value)
+		}
+	}
+	if req.Autonomous || c.Autonomous {
+		args = append(args, "--autonomous")
+	}
+	args = append(args, req.Command)
+	if req.ParentID != "" {
+		args = append(args, "--parent-id", req.ParentID)
+	}
+	if req.Task != "" {
+		args = append(args, "--", req.Task)
+	}
+	return args
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/tmux"
+)
+
+// TmuxFlags provides --window, --split and --tmux-session flags for session commands.
+// Embed this in a command struct to let it start the agent in a new tmux window or pane
+// instead of the current terminal.
+type TmuxFlags struct {
+	Window      bool   `help:"Start the session in a new tmux window"`
+	Split       string `help:"Start the session in a new tmux pane split from this one: h (side by side) or v (stacked)" enum:",h,v" default:""`
+	TmuxSession string `name:"tmux-session" help:"Start the session in a new window of this tmux session, created if needed" optional:""`
+
+	// SessionID is the ID the spawned command gives its session, so the window can be named after it
+	SessionID string `name:"session-id" hidden:""`
+	// ParentID makes the session a child of another, such as the session that asked for it over MCP
+	ParentID string `name:"parent-id" hidden:""`
+}
+
+// tmuxFlagNames are the TmuxFlags that take the command to a new window or pane.
+var tmuxFlagNames = map[string]bool{"--window": false, "--split": true, "--tmux-session": true} // flag → takes a value
+
+// Validate rejects asking for both a new window and a split.
+func (f *TmuxFlags) Validate() error {
+	if f.Window && f.Split != "" {
+		return fmt.Errorf("--window and --split can't be used together")
+	}
+	return nil
+}
+
+// Spawning reports whether the command should start its session in a new window or pane.
+func (f *TmuxFlags) Spawning() bool {
+	return f.Window || f.Split != "" || f.TmuxSession != ""
+}
+
+// Spawn runs this cmt command again in a new tmux window or pane, named after the
+// session ID and workflow, and returns once it started. The session it starts records
+// its own tmux location, like any other.
+func (f *TmuxFlags) Spawn(workflow db.WorkflowType) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	sessionID, loc, err := spawnCmt(tmux.Placement{
+		Session: f.TmuxSession,
+		Split:   f.Split,
+		Dir:     workDir,
+	}, workflow, stripTmuxFlags(os.Args[1:]))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Started session %s in %s\n", sessionID, loc)
+	return nil
+}
+
+// spawnCmt runs cmt with args in a new tmux window or pane at p, named after the
+// session ID it gives the session and workflow. It returns the session ID and where
+// the window or pane is.
+func spawnCmt(p tmux.Placement, workflow db.WorkflowType, args []string) (string, *tmux.Location, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("find cmt executable: %w", err)
+	}
+
+	sessionID := uuid.New().String()[:8]
+	p.Name = sessionID + "-" + string(workflow)
+	p.Env = cmtEnv(os.Environ())
+	loc, err := tmux.Spawn(p, append([]string{exe}, withSessionID(args, sessionID)...))
+	if err != nil {
+		return "", nil, err
+	}
+	return sessionID, loc, nil
+}
+
+// withSessionID adds --session-id to args, before the "--" that ends the flags if any.
+func withSessionID(args []string, sessionID string) []string {
+	flag := []string{"--session-id", sessionID}
+	for i, arg := range args {
+		if arg == "--" {
+			return append(append(append([]string{}, args[:i]...), flag...), args[i:]...)
+		}
+	}
+	return append(args, flag...)
+}
+
+// TakeSessionID returns the session ID given by Spawn, once: sessions a looping command
+// starts after the first get new IDs.
+func (f *TmuxFlags) TakeSessionID() string {
+	id := f.SessionID
+	f.SessionID = ""
+	return id
+}
+
+// stripTmuxFlags removes the TmuxFlags from command-line arguments, so the spawned
+// command runs in place.
+func stripTmuxFlags(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(out, args[i:]...)
+		}
+		name, _, hasValue := strings.Cut(arg, "=")
+		takesValue, ok := tmuxFlagNames[name]
+		if !ok {
+			out = append(out, arg)
+			continue
+		}
+		if takesValue && !hasValue {
+			i++ // skip the value
+		}
+	}
+	return out
+}
+
+// cmtEnv returns the CMT_ variables of env, which a new tmux window would otherwise not
+// inherit from this process.
+func cmtEnv(env []string) []string {
+	var out []string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "CMT_") {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripTmuxFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "window", args: []string{"--agent", "claude", "new", "--window", "fix it"}, want: []string{"--agent", "claude", "new", "fix it"}},
+		{name: "split with separate value", args: []string{"research", "--split", "h", "auth"}, want: []string{"research", "auth"}},
+		{name: "split with inline value", args: []string{"research", "--split=v", "auth"}, want: []string{"research", "auth"}},
+		{name: "tmux session", args: []string{"plan", "--tmux-session", "agents", "-f", "a.md", "api"}, want: []string{"plan", "-f", "a.md", "api"}},
+		{name: "after separator", args: []string{"new", "--window", "--", "--window"}, want: []string{"new", "--", "--window"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripTmuxFlags(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stripTmuxFlags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTakeSessionID(t *testing.T) {
+	f := TmuxFlags{SessionID: "ab12cd34"}
+	if got := f.TakeSessionID(); got != "ab12cd34" {
+		t.Errorf("TakeSessionID() = %q, want ab12cd34", got)
+	}
+	if got := f.TakeSessionID(); got != "" {
+		t.Errorf("second TakeSessionID() = %q, want empty", got)
+	}
+}
+
+func TestCmtEnv(t *testing.T) {
+	got := cmtEnv([]string{"HOME=/root", "CMT_DB=/tmp/x.db", "CMT_AGENT=claude", "PATH=/bin"})
+	want := []string{"CMT_DB=/tmp/x.db", "CMT_AGENT=claude"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cmtEnv() = %q, want %q", got, want)
+	}
+}
+
+func TestWithSessionID(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "no separator", args: []string{"new", "fix it"}, want: []string{"new", "fix it", "--session-id", "ab12cd34"}},
+		{name: "before separator", args: []string{"new", "--", "--fix it"}, want: []string{"new", "--session-id", "ab12cd34", "--", "--fix it"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withSessionID(tt.args, "ab12cd34"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("withSessionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
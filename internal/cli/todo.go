@@ -1,14 +1,20 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/api"
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/ingest"
 )
 
 // TodoCmd is the top-level todo command
@@ -20,54 +26,15 @@ type TodoCmd struct {
 	Undone TodoUndoneCmd `cmd:"" help:"Mark a todo as not done"`
 	Update TodoUpdateCmd `cmd:"" help:"Update a todo"`
 	Rm     TodoRmCmd     `cmd:"rm" help:"Remove a todo"`
+	Start  TodoStartCmd  `cmd:"" help:"Start an agent session on a todo"`
+	Serve  TodoServeCmd  `cmd:"" help:"Accept todos over HTTP from scripts and webhooks"`
 }
 
-// todoJSON is the JSON representation of a todo with all fields
-type todoJSON struct {
-	ID             string  `json:"id"`
-	CreatedAt      string  `json:"created_at"`
-	UpdatedAt      string  `json:"updated_at"`
-	Status         string  `json:"status"`
-	Summary        string  `json:"summary"`
-	Date           *string `json:"date,omitempty"`
-	Source         *string `json:"source,omitempty"`
-	URL            *string `json:"url,omitempty"`
-	Channel        *string `json:"channel,omitempty"`
-	Sender         *string `json:"sender,omitempty"`
-	IdempotencyKey *string `json:"idempotency_key,omitempty"`
-	FullMessage    *string `json:"full_message,omitempty"`
-	DeletedAt      *string `json:"deleted_at,omitempty"`
-}
-
-func todoToJSON(t *db.Todo) todoJSON {
-	j := todoJSON{
-		ID:             t.ID,
-		CreatedAt:      t.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:      t.UpdatedAt.Format(time.RFC3339),
-		Status:         string(t.Status),
-		Summary:        t.Summary,
-		Source:         t.Source,
-		URL:            t.URL,
-		Channel:        t.Channel,
-		Sender:         t.Sender,
-		IdempotencyKey: t.IdempotencyKey,
-		FullMessage:    t.FullMessage,
-	}
-	if t.Date != nil {
-		s := t.Date.Format(time.RFC3339)
-		j.Date = &s
-	}
-	if t.DeletedAt != nil {
-		s := t.DeletedAt.Format(time.RFC3339)
-		j.DeletedAt = &s
-	}
-	return j
-}
-
+// printTodosJSON prints todos as a JSON array
 func printTodosJSON(todos []*db.Todo) error {
-	out := make([]todoJSON, len(todos))
+	out := make([]api.Todo, len(todos))
 	for i, t := range todos {
-		out[i] = todoToJSON(t)
+		out[i] = api.NewTodo(t)
 	}
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
@@ -321,3 +288,116 @@ func (c *TodoRmCmd) Run(cli *CLI) error {
 	fmt.Printf("Todo %s removed.\n", c.ID)
 	return nil
 }
+
+// todoWorkflows maps the workflows a todo can be started with to their commands.
+var todoWorkflows = map[string]struct {
+	command  agent.CommandType
+	workflow db.WorkflowType
+}{
+	"new":      {agent.CommandNew, db.WorkflowGeneral},
+	"research": {agent.CommandResearch, db.WorkflowResearch},
+	"plan":     {agent.CommandPlan, db.WorkflowPlan},
+}
+
+// TodoStartCmd starts an agent session seeded with a todo's summary, URL and message
+type TodoStartCmd struct {
+	TmuxFlags
+	ID       string `arg:"" help:"Todo ID"`
+	Workflow string `short:"w" help:"Workflow to start: new, research or plan" enum:"new,research,plan" default:"new"`
+	Venue    string `help:"Directory to run the session in (default: the current one)" optional:""`
+	Done     bool   `help:"Mark the todo done when the session completes"`
+}
+
+func (c *TodoStartCmd) Run(cli *CLI) error {
+	t, err := cli.Database().GetTodo(c.ID)
+	if err != nil {
+		return fmt.Errorf("get todo: %w", err)
+	}
+	if t == nil || t.DeletedAt != nil {
+		return fmt.Errorf("todo %q not found", c.ID)
+	}
+	if c.Venue != "" {
+		if info, err := os.Stat(c.Venue); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", c.Venue)
+		}
+	}
+
+	wf := todoWorkflows[c.Workflow]
+	if c.TmuxFlags.Spawning() {
+		return c.TmuxFlags.Spawn(wf.workflow)
+	}
+
+	workDir := c.Venue
+	if workDir != "" {
+		if workDir, err = filepath.Abs(workDir); err != nil {
+			return fmt.Errorf("resolve venue: %w", err)
+		}
+	}
+
+	ag, err := newAgent(cli.Agent, cli.Database())
+	if err != nil {
+		return err
+	}
+
+	return ag.Run(context.Background(), agent.RunOptions{
+		Command:         wf.command,
+		WorkflowType:    wf.workflow,
+		SessionID:       c.TmuxFlags.TakeSessionID(),
+		ParentID:        c.TmuxFlags.ParentID,
+		TodoID:          t.ID,
+		CompleteTodo:    c.Done,
+		TaskDescription: todoTask(t),
+		WorkingDir:      workDir,
+		Model:           cli.Model,
+		Effort:          cli.Effort,
+		AutonomousMode:  cli.Autonomous,
+	})
+}
+
+// todoTask returns the task a session started on a todo is given: its summary, then
+// its URL and full message when it has them.
+func todoTask(t *db.Todo) string {
+	parts := []string{t.Summary}
+	if t.URL != nil && *t.URL != "" {
+		parts = append(parts, "URL: "+*t.URL)
+	}
+	if t.FullMessage != nil && strings.TrimSpace(*t.FullMessage) != "" && *t.FullMessage != t.Summary {
+		parts = append(parts, *t.FullMessage)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// TodoServeCmd accepts todos over HTTP: the generic JSON schema, and GitHub, Slack and
+// email-to-JSON webhook payloads
+type TodoServeCmd struct {
+	Listen       string `help:"Address to listen on: host:port, or unix:/path for a Unix socket" default:"127.0.0.1:7778"`
+	TokenFile    string `name:"token-file" help:"File holding the token, created if missing (default: ~/.config/cmt/ingest-token)" optional:""`
+	GitHubSecret string `name:"github-secret" help:"Secret GitHub webhook payloads must be signed with" env:"CMT_GITHUB_WEBHOOK_SECRET" optional:""`
+	SlackSecret  string `name:"slack-secret" help:"Signing secret Slack requests must be signed with" env:"CMT_SLACK_SIGNING_SECRET" optional:""`
+}
+
+func (c *TodoServeCmd) Run(cli *CLI) error {
+	tokenFile := c.TokenFile
+	if tokenFile == "" {
+		path, err := ingest.TokenPath()
+		if err != nil {
+			return err
+		}
+		tokenFile = path
+	}
+	token, err := api.LoadToken(tokenFile)
+	if err != nil {
+		return err
+	}
+
+	ln, err := api.Listen(c.Listen)
+	if err != nil {
+		return err
+	}
+	server := ingest.New(cli.Database(), token)
+	server.GitHubSecret = c.GitHubSecret
+	server.SlackSecret = c.SlackSecret
+
+	fmt.Printf("Accepting todos on %s (token in %s)\n", c.Listen, tokenFile)
+	return serveUntilSignal(ln, server)
+}
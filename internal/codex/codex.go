@@ -3,7 +3,10 @@ package codex
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/agentic-camerata/cmt/internal/agent"
@@ -25,9 +28,28 @@ func NewRunner(database *db.DB) (*Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	base.SessionIDs = agent.SessionFiles{
+		Dir:          sessionDir,
+		Ext:          ".jsonl",
+		IDRe:         rolloutIDRe,
+		MatchWorkDir: true,
+	}
 	return &Runner{base: base}, nil
 }
 
+// rolloutIDRe matches the session ID in the name of a Codex rollout file,
+// e.g. rollout-2025-01-02T03-04-05-<uuid>.jsonl.
+var rolloutIDRe = regexp.MustCompile(`^rollout-.*-([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\.jsonl$`)
+
+// sessionDir returns the directory Codex keeps its rollout files in, by date below it.
+// Rollouts of every working directory share it; the first line of each names its own.
+func sessionDir(string) string {
+	if home := os.Getenv("CODEX_HOME"); home != "" {
+		return filepath.Join(home, "sessions")
+	}
+	return agent.HomeDir(".codex", "sessions")
+}
+
 // Run starts a Codex session.
 func (r *Runner) Run(ctx context.Context, opts agent.RunOptions) error {
 	cmd := r.buildCommand(opts)
@@ -45,10 +67,34 @@ func (r *Runner) DefaultEffort(cmd agent.CommandType) string {
 	return ""
 }
 
+// Prompt returns the task description with the Codex prompt prefix applied;
+// Codex names slash commands with hyphens instead of underscores.
+func (r *Runner) Prompt(opts agent.RunOptions) string {
+	return applyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+}
+
+// Capabilities reports the options the Codex CLI honours.
+func (r *Runner) Capabilities() agent.Capabilities {
+	return agent.Capabilities{
+		Binary:           "codex",
+		Resume:           true,
+		ResumePicker:     true,
+		Models:           true,
+		PrintMode:        true,
+		Autonomous:       true,
+		SessionIDCapture: true,
+	}
+}
+
 // buildCommand constructs the codex CLI command from the given options.
 func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 	args := []string{}
 
+	// Codex resumes through its resume subcommand, which shows a picker without an ID.
+	if opts.ResumeSessionID != "" {
+		args = append(args, "resume")
+	}
+
 	if opts.Model != "" {
 		args = append(args, "--model", opts.Model)
 	}
@@ -63,7 +109,11 @@ func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 		args = append(args, "-q")
 	}
 
-	taskDescription := applyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+	if opts.ResumeSessionID != "" && opts.ResumeSessionID != "*" {
+		args = append(args, opts.ResumeSessionID)
+	}
+
+	taskDescription := r.Prompt(opts)
 	if taskDescription != "" {
 		args = append(args, taskDescription)
 	}
@@ -72,6 +72,23 @@ func TestBuildCommand(t *testing.T) {
 			wantArgs:    []string{"-q", "/review-code payments"},
 			notWantArgs: []string{"/review_code"},
 		},
+		{
+			name: "resume by session ID",
+			opts: agent.RunOptions{
+				TaskDescription: "carry on",
+				ResumeSessionID: "0199a213-81c0-7800-8aa1-bbab2a035a53",
+			},
+			wantArgs: []string{"codex resume 0199a213-81c0-7800-8aa1-bbab2a035a53 carry on"},
+		},
+		{
+			name: "resume picker",
+			opts: agent.RunOptions{
+				ResumeSessionID: "*",
+				AutonomousMode:  true,
+			},
+			wantArgs:    []string{"codex resume -a never"},
+			notWantArgs: []string{"*"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,184 @@
+// Package config loads user-defined agent backends from ~/.config/cmt/agents.json.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+)
+
+// EnvAgentsConfig overrides the path of the agents config file.
+const EnvAgentsConfig = "CMT_AGENTS_CONFIG"
+
+// Prompt delivery modes for AgentSpec.PromptMode.
+const (
+	PromptArg   = "arg"   // pass the prompt on the command line via PromptArgs
+	PromptInput = "input" // type the prompt into the session after InputDelay
+)
+
+// Slash command styles for AgentSpec.SlashCommands.
+const (
+	SlashUnderscore = "underscore" // /research_codebase, as Claude and Pi name them
+	SlashHyphen     = "hyphen"     // /research-codebase, as Codex names them
+	SlashNone       = "none"       // drop slash command prefixes and send the bare task
+)
+
+// defaultInputDelay is how long to wait before typing a PromptInput prompt.
+const defaultInputDelay = 4 * time.Second
+
+// Config is the contents of the agents config file.
+type Config struct {
+	Agents map[string]*AgentSpec `json:"agents"`
+}
+
+// AgentSpec describes a generic agent backend: the binary to run and how RunOptions map
+// to its arguments. Argument templates may use {model}, {effort}, {prompt} and {session}.
+type AgentSpec struct {
+	Binary           string            `json:"binary"`
+	Args             []string          `json:"args"`               // always passed first
+	ModelArgs        []string          `json:"model_args"`         // passed when a model is set, e.g. ["--model", "{model}"]
+	EffortArgs       []string          `json:"effort_args"`        // passed when an effort is set
+	Efforts          []string          `json:"efforts"`            // effort levels accepted with effort_args (default all)
+	PrintArgs        []string          `json:"print_args"`         // passed in print (non-interactive) mode
+	AutonomousArgs   []string          `json:"autonomous_args"`    // passed in autonomous mode
+	ResumeArgs       []string          `json:"resume_args"`        // passed to resume session {session}
+	ResumePickerArgs []string          `json:"resume_picker_args"` // passed to pick a session to resume
+	PromptArgs       []string          `json:"prompt_args"`        // how the prompt is passed in arg mode (default ["{prompt}"])
+	PromptMode       string            `json:"prompt_mode"`        // "arg" (default) or "input"
+	InputDelay       string            `json:"input_delay"`        // delay before typing an input-mode prompt (default "4s")
+	SlashCommands    string            `json:"slash_commands"`     // "underscore" (default), "hyphen" or "none"
+	DefaultModels    map[string]string `json:"default_models"`     // command type (or "default") → model
+	DefaultEfforts   map[string]string `json:"default_efforts"`    // command type (or "default") → effort
+	SessionIDPattern string            `json:"session_id_pattern"` // regex whose first group is the agent's session ID
+}
+
+// Path returns the agents config file path: $CMT_AGENTS_CONFIG or ~/.config/cmt/agents.json.
+func Path() (string, error) {
+	if p := os.Getenv(EnvAgentsConfig); p != "" {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "cmt", "agents.json"), nil
+}
+
+// Load reads the agents config file. A missing file is an empty config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads and validates an agents config file. A missing file is an empty config.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read agents config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse agents config %s: %w", path, err)
+	}
+	for name, spec := range cfg.Agents {
+		if err := validate(name, spec); err != nil {
+			return nil, fmt.Errorf("agents config %s: agent %q: %w", path, name, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// validNameRe matches agent names usable in --agent and agent: metadata.
+var validNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func validate(name string, spec *AgentSpec) error {
+	switch {
+	case !validNameRe.MatchString(name):
+		return fmt.Errorf("invalid name (use lowercase letters, digits, - and _)")
+	case slices.Contains(agent.Backends, name):
+		return fmt.Errorf("cannot redefine a built-in agent")
+	case spec == nil || spec.Binary == "":
+		return fmt.Errorf("binary is required")
+	}
+	switch spec.PromptMode {
+	case "", PromptArg, PromptInput:
+	default:
+		return fmt.Errorf("invalid prompt_mode %q (valid: %s, %s)", spec.PromptMode, PromptArg, PromptInput)
+	}
+	switch spec.SlashCommands {
+	case "", SlashUnderscore, SlashHyphen, SlashNone:
+	default:
+		return fmt.Errorf("invalid slash_commands %q (valid: %s, %s, %s)", spec.SlashCommands, SlashUnderscore, SlashHyphen, SlashNone)
+	}
+	if spec.InputDelay != "" {
+		if _, err := time.ParseDuration(spec.InputDelay); err != nil {
+			return fmt.Errorf("invalid input_delay %q: %w", spec.InputDelay, err)
+		}
+	}
+	if spec.SessionIDPattern != "" {
+		re, err := regexp.Compile(spec.SessionIDPattern)
+		if err != nil {
+			return fmt.Errorf("invalid session_id_pattern: %w", err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("session_id_pattern needs a capture group for the session ID")
+		}
+	}
+	return nil
+}
+
+// Delay returns the delay before typing an input-mode prompt.
+func (s *AgentSpec) Delay() time.Duration {
+	if d, err := time.ParseDuration(s.InputDelay); err == nil {
+		return d
+	}
+	return defaultInputDelay
+}
+
+// SessionIDRegexp returns the compiled session ID pattern, or nil if none is set.
+func (s *AgentSpec) SessionIDRegexp() *regexp.Regexp {
+	if s.SessionIDPattern == "" {
+		return nil
+	}
+	return regexp.MustCompile(s.SessionIDPattern)
+}
+
+// AgentNames returns the names of the configured agents, sorted.
+func (c *Config) AgentNames() []string {
+	names := make([]string, 0, len(c.Agents))
+	for name := range c.Agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidAgents returns the built-in agent names followed by the configured ones. A config
+// that fails to load contributes no names.
+func ValidAgents() []string {
+	names := slices.Clone(agent.Backends)
+	if cfg, err := Load(); err == nil {
+		names = append(names, cfg.AgentNames()...)
+	}
+	return names
+}
+
+// ValidAgentsText formats ValidAgents for error messages.
+func ValidAgentsText() string {
+	return strings.Join(ValidAgents(), ", ")
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "valid agent",
+			content: `{"agents": {"aider": {
+				"binary": "aider",
+				"model_args": ["--model", "{model}"],
+				"prompt_mode": "input",
+				"input_delay": "2s",
+				"session_id_pattern": "session ([a-z0-9]+)"
+			}}}`,
+		},
+		{name: "missing binary", content: `{"agents": {"aider": {}}}`, wantErr: "binary is required"},
+		{name: "built-in name", content: `{"agents": {"claude": {"binary": "claude"}}}`, wantErr: "built-in"},
+		{name: "invalid name", content: `{"agents": {"My Agent": {"binary": "x"}}}`, wantErr: "invalid name"},
+		{name: "invalid prompt mode", content: `{"agents": {"x": {"binary": "x", "prompt_mode": "stdin"}}}`, wantErr: "prompt_mode"},
+		{name: "invalid slash style", content: `{"agents": {"x": {"binary": "x", "slash_commands": "camel"}}}`, wantErr: "slash_commands"},
+		{name: "invalid delay", content: `{"agents": {"x": {"binary": "x", "input_delay": "soon"}}}`, wantErr: "input_delay"},
+		{name: "pattern without group", content: `{"agents": {"x": {"binary": "x", "session_id_pattern": "session \\w+"}}}`, wantErr: "capture group"},
+		{name: "invalid json", content: `{"agents": `, wantErr: "parse agents config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "agents.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFile() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+			spec := cfg.Agents["aider"]
+			if spec.Delay() != 2*time.Second || spec.SessionIDRegexp() == nil {
+				t.Errorf("spec = %+v", spec)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Setenv(EnvAgentsConfig, filepath.Join(t.TempDir(), "agents.json"))
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Agents) != 0 {
+		t.Errorf("Agents = %v, want none", cfg.Agents)
+	}
+	if got := ValidAgents(); !slices.Equal(got, []string{"claude", "codex", "amp", "pi", "fake"}) {
+		t.Errorf("ValidAgents() = %v, want the built-in agents", got)
+	}
+}
+
+func TestValidAgents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	os.WriteFile(path, []byte(`{"agents": {"opencode": {"binary": "opencode"}, "aider": {"binary": "aider"}}}`), 0o644)
+	t.Setenv(EnvAgentsConfig, path)
+
+	want := []string{"claude", "codex", "amp", "pi", "fake", "aider", "opencode"}
+	if got := ValidAgents(); !slices.Equal(got, want) {
+		t.Errorf("ValidAgents() = %v, want %v", got, want)
+	}
+}
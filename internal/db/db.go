@@ -31,6 +31,12 @@ func Open(path string) (*DB, error) {
 		}
 		path = filepath.Join(home, path[2:])
 	}
+	// Keep the path absolute, since it is passed on to cmt processes run in other directories
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve database path: %w", err)
+	}
+	path = abs
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -38,7 +44,9 @@ func Open(path string) (*DB, error) {
 		return nil, fmt.Errorf("create database directory: %w", err)
 	}
 
-	conn, err := sql.Open("sqlite", path)
+	// Concurrent writers, such as the branches of a parallel play, wait for the lock
+	// instead of failing with SQLITE_BUSY
+	conn, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
@@ -84,6 +92,12 @@ func Open(path string) (*DB, error) {
 	if err := addColumnIfNotExists(conn, `ALTER TABLE sessions ADD COLUMN loop_interval TEXT`, "sessions loop_interval column"); err != nil {
 		return nil, err
 	}
+	if err := addColumnIfNotExists(conn, `ALTER TABLE sessions ADD COLUMN agent TEXT`, "sessions agent column"); err != nil {
+		return nil, err
+	}
+	if err := addColumnIfNotExists(conn, `ALTER TABLE sessions ADD COLUMN todo_id TEXT`, "sessions todo_id column"); err != nil {
+		return nil, err
+	}
 
 	// Create todos table if it doesn't exist (for existing databases)
 	if _, err := conn.Exec(`CREATE TABLE IF NOT EXISTS todos (
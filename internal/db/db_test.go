@@ -53,6 +53,25 @@ func TestOpen(t *testing.T) {
 		}
 	})
 
+	t.Run("makes a relative path absolute", func(t *testing.T) {
+		wd, _ := os.Getwd()
+		if err := os.Chdir(t.TempDir()); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Chdir(wd)
+		cwd, _ := os.Getwd()
+
+		db, err := Open("test.db")
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer db.Close()
+
+		if want := filepath.Join(cwd, "test.db"); db.Path() != want {
+			t.Errorf("Path() = %v, want %v", db.Path(), want)
+		}
+	})
+
 	t.Run("expands tilde in path", func(t *testing.T) {
 		home, err := os.UserHomeDir()
 		if err != nil {
@@ -93,6 +112,7 @@ func TestSessionCRUD(t *testing.T) {
 			TmuxSession:      "main",
 			TmuxWindow:       0,
 			TmuxPane:         1,
+			Agent:            "claude",
 		}
 
 		if err := db.CreateSession(session); err != nil {
@@ -126,6 +146,9 @@ func TestSessionCRUD(t *testing.T) {
 		if got.TmuxSession != session.TmuxSession {
 			t.Errorf("TmuxSession = %v, want %v", got.TmuxSession, session.TmuxSession)
 		}
+		if got.Agent != session.Agent {
+			t.Errorf("Agent = %v, want %v", got.Agent, session.Agent)
+		}
 	})
 
 	t.Run("get non-existent session returns nil", func(t *testing.T) {
@@ -373,6 +396,47 @@ func TestGetLastSession(t *testing.T) {
 	})
 }
 
+func TestListTodoSessions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	sessions := []*Session{
+		{ID: "todo-1", WorkflowType: WorkflowResearch, Status: StatusCompleted, WorkingDirectory: "/tmp", TodoID: "t1"},
+		{ID: "todo-2", WorkflowType: WorkflowGeneral, Status: StatusWaiting, WorkingDirectory: "/tmp", TodoID: "t1"},
+		{ID: "todo-3", WorkflowType: WorkflowGeneral, Status: StatusWaiting, WorkingDirectory: "/tmp", TodoID: "t2"},
+		{ID: "todo-4", WorkflowType: WorkflowGeneral, Status: StatusWaiting, WorkingDirectory: "/tmp"},
+		{ID: "todo-5", WorkflowType: WorkflowPlan, Status: StatusWaiting, WorkingDirectory: "/tmp", TodoID: "t1"},
+	}
+	for _, s := range sessions {
+		if err := db.CreateSession(s); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := db.SoftDeleteSession("todo-5"); err != nil {
+		t.Fatalf("SoftDeleteSession() error = %v", err)
+	}
+
+	got, err := db.ListTodoSessions("t1")
+	if err != nil {
+		t.Fatalf("ListTodoSessions() error = %v", err)
+	}
+	if len(got) != 2 || got[0].ID != "todo-2" || got[1].ID != "todo-1" {
+		t.Fatalf("ListTodoSessions(t1) = %v, want todo-2, todo-1", got)
+	}
+	if got[0].TodoID != "t1" {
+		t.Errorf("TodoID = %q, want t1", got[0].TodoID)
+	}
+
+	got, err = db.ListTodoSessions("none")
+	if err != nil {
+		t.Fatalf("ListTodoSessions() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ListTodoSessions(none) returned %d sessions, want 0", len(got))
+	}
+}
+
 // setupTestDB creates a temporary database for testing
 func setupTestDB(t *testing.T) *DB {
 	t.Helper()
@@ -386,3 +450,36 @@ func setupTestDB(t *testing.T) *DB {
 
 	return db
 }
+
+func TestPruneDeletedSessions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	for _, s := range []*Session{
+		{ID: "old", WorkflowType: WorkflowGeneral, Status: StatusCompleted, WorkingDirectory: "/tmp", OutputFile: "/out/old.log"},
+		{ID: "recent", WorkflowType: WorkflowGeneral, Status: StatusCompleted, WorkingDirectory: "/tmp", OutputFile: "/out/recent.log"},
+		{ID: "kept", WorkflowType: WorkflowGeneral, Status: StatusCompleted, WorkingDirectory: "/tmp", OutputFile: "/out/kept.log"},
+	} {
+		if err := db.CreateSession(s); err != nil {
+			t.Fatalf("CreateSession() error = %v", err)
+		}
+	}
+	db.SoftDeleteSession("old")
+	db.SoftDeleteSession("recent")
+	if _, err := db.conn.Exec(`UPDATE sessions SET deleted_at = datetime('now', '-8 days') WHERE id = 'old'`); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := db.PruneDeletedSessions()
+	if err != nil {
+		t.Fatalf("PruneDeletedSessions() error = %v", err)
+	}
+	if len(files) != 1 || files[0] != "/out/old.log" {
+		t.Errorf("pruned output files = %v, want /out/old.log", files)
+	}
+	for id, want := range map[string]bool{"old": false, "recent": true, "kept": true} {
+		if s, _ := db.GetSession(id); (s != nil) != want {
+			t.Errorf("session %s exists = %v, want %v", id, s != nil, want)
+		}
+	}
+}
@@ -26,7 +26,9 @@ CREATE TABLE IF NOT EXISTS sessions (
     loop_interval TEXT,
     pid INTEGER,
     deleted_at DATETIME,
-    parent_id TEXT
+    parent_id TEXT,
+    agent TEXT,  -- agent backend that ran the session (claude, codex, amp, pi, fake, or a custom agent name)
+    todo_id TEXT  -- todo the session was started for
 );
 
 CREATE INDEX IF NOT EXISTS idx_sessions_status ON sessions(status);
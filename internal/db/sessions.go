@@ -53,6 +53,8 @@ type Session struct {
 	PID              int
 	DeletedAt        *time.Time // nil if not deleted
 	ParentID         string     // ID of parent play session (empty if top-level)
+	Agent            string     // Agent backend that ran the session (empty for play sessions and old rows)
+	TodoID           string     // ID of the todo the session was started for (empty if none)
 }
 
 // HasTmuxLocation reports whether this session has a recorded tmux location.
@@ -65,12 +67,12 @@ func (db *DB) CreateSession(s *Session) error {
 	query := `
 		INSERT INTO sessions (
 			id, workflow_type, status, working_directory, task_description, prefix,
-			claude_session_id, tmux_session, tmux_window, tmux_pane, output_file, playbook_file, play_state, loop_interval, pid, parent_id
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			claude_session_id, tmux_session, tmux_window, tmux_pane, output_file, playbook_file, play_state, loop_interval, pid, parent_id, agent, todo_id
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	_, err := db.conn.Exec(query,
 		s.ID, s.WorkflowType, s.Status, s.WorkingDirectory, s.TaskDescription, s.Prefix,
-		s.ClaudeSessionID, s.TmuxSession, s.TmuxWindow, s.TmuxPane, s.OutputFile, s.PlaybookFile, s.PlayState, s.LoopInterval, s.PID, s.ParentID,
+		s.ClaudeSessionID, s.TmuxSession, s.TmuxWindow, s.TmuxPane, s.OutputFile, s.PlaybookFile, s.PlayState, s.LoopInterval, s.PID, s.ParentID, s.Agent, s.TodoID,
 	)
 	if err != nil {
 		return fmt.Errorf("insert session: %w", err)
@@ -83,7 +85,7 @@ func (db *DB) GetSession(id string) (*Session, error) {
 	query := `
 		SELECT id, created_at, updated_at, workflow_type, status, working_directory,
 		       task_description, prefix, claude_session_id, tmux_session, tmux_window, tmux_pane,
-		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id
+		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id, agent, todo_id
 		FROM sessions WHERE id = ?
 	`
 	row := db.conn.QueryRow(query, id)
@@ -95,7 +97,7 @@ func (db *DB) GetLastSession() (*Session, error) {
 	query := `
 		SELECT id, created_at, updated_at, workflow_type, status, working_directory,
 		       task_description, prefix, claude_session_id, tmux_session, tmux_window, tmux_pane,
-		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id
+		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id, agent, todo_id
 		FROM sessions ORDER BY created_at DESC, rowid DESC LIMIT 1
 	`
 	row := db.conn.QueryRow(query)
@@ -112,7 +114,7 @@ func (db *DB) ListSessions(status SessionStatus) ([]*Session, error) {
 		query = `
 			SELECT id, created_at, updated_at, workflow_type, status, working_directory,
 			       task_description, prefix, claude_session_id, tmux_session, tmux_window, tmux_pane,
-			       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id
+			       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id, agent, todo_id
 			FROM sessions WHERE status = ? ORDER BY created_at DESC, rowid DESC
 		`
 		args = append(args, status)
@@ -120,7 +122,7 @@ func (db *DB) ListSessions(status SessionStatus) ([]*Session, error) {
 		query = `
 			SELECT id, created_at, updated_at, workflow_type, status, working_directory,
 			       task_description, prefix, claude_session_id, tmux_session, tmux_window, tmux_pane,
-			       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id
+			       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id, agent, todo_id
 			FROM sessions WHERE status != 'deleted' ORDER BY created_at DESC, rowid DESC
 		`
 	}
@@ -162,13 +164,15 @@ func (db *DB) UpdateSession(s *Session) error {
 			play_state = ?,
 			loop_interval = ?,
 			pid = ?,
-			parent_id = ?
+			parent_id = ?,
+			agent = ?,
+			todo_id = ?
 		WHERE id = ?
 	`
 	_, err := db.conn.Exec(query,
 		s.WorkflowType, s.Status, s.WorkingDirectory, s.TaskDescription, s.Prefix,
 		s.ClaudeSessionID, s.TmuxSession, s.TmuxWindow, s.TmuxPane, s.OutputFile, s.PlaybookFile, s.PlayState, s.LoopInterval,
-		s.PID, s.ParentID, s.ID,
+		s.PID, s.ParentID, s.Agent, s.TodoID, s.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update session: %w", err)
@@ -211,7 +215,7 @@ func (db *DB) ListAbandonedPlaySessions() ([]*Session, error) {
 	query := `
 		SELECT id, created_at, updated_at, workflow_type, status, working_directory,
 		       task_description, prefix, claude_session_id, tmux_session, tmux_window, tmux_pane,
-		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id
+		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id, agent, todo_id
 		FROM sessions
 		WHERE workflow_type = 'play' AND status = 'abandoned'
 		AND (parent_id IS NULL OR parent_id = '')
@@ -262,14 +266,14 @@ type scanner interface {
 // scanSessionFrom scans a session from any scanner (Row or Rows).
 func scanSessionFrom(s scanner) (*Session, error) {
 	var sess Session
-	var taskDesc, prefix, claudeID, outputFile, playbookFile, playState, loopInterval, parentID sql.NullString
+	var taskDesc, prefix, claudeID, outputFile, playbookFile, playState, loopInterval, parentID, agent, todoID sql.NullString
 	var pid sql.NullInt64
 	var deletedAt sql.NullTime
 
 	err := s.Scan(
 		&sess.ID, &sess.CreatedAt, &sess.UpdatedAt, &sess.WorkflowType, &sess.Status, &sess.WorkingDirectory,
 		&taskDesc, &prefix, &claudeID, &sess.TmuxSession, &sess.TmuxWindow, &sess.TmuxPane,
-		&outputFile, &playbookFile, &playState, &loopInterval, &pid, &deletedAt, &parentID,
+		&outputFile, &playbookFile, &playState, &loopInterval, &pid, &deletedAt, &parentID, &agent, &todoID,
 	)
 	if err != nil {
 		return nil, err
@@ -287,6 +291,8 @@ func scanSessionFrom(s scanner) (*Session, error) {
 		sess.DeletedAt = &deletedAt.Time
 	}
 	sess.ParentID = parentID.String
+	sess.Agent = agent.String
+	sess.TodoID = todoID.String
 
 	return &sess, nil
 }
@@ -317,7 +323,7 @@ func (db *DB) ListDeletedSessions() ([]*Session, error) {
 	query := `
 		SELECT id, created_at, updated_at, workflow_type, status, working_directory,
 		       task_description, prefix, claude_session_id, tmux_session, tmux_window, tmux_pane,
-		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id
+		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id, agent, todo_id
 		FROM sessions WHERE status = 'deleted' ORDER BY deleted_at DESC, rowid DESC
 	`
 
@@ -339,6 +345,32 @@ func (db *DB) ListDeletedSessions() ([]*Session, error) {
 	return sessions, rows.Err()
 }
 
+// ListTodoSessions retrieves the sessions started for a todo, excluding deleted ones, newest first
+func (db *DB) ListTodoSessions(todoID string) ([]*Session, error) {
+	query := `
+		SELECT id, created_at, updated_at, workflow_type, status, working_directory,
+		       task_description, prefix, claude_session_id, tmux_session, tmux_window, tmux_pane,
+		       output_file, playbook_file, play_state, loop_interval, pid, deleted_at, parent_id, agent, todo_id
+		FROM sessions WHERE todo_id = ? AND status != 'deleted' ORDER BY created_at DESC, rowid DESC
+	`
+
+	rows, err := db.conn.Query(query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("query todo sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+	for rows.Next() {
+		s, err := scanSessionRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
 // SoftDeleteSession marks a session as deleted with timestamp
 func (db *DB) SoftDeleteSession(id string) error {
 	query := `UPDATE sessions SET updated_at = CURRENT_TIMESTAMP, status = 'deleted', deleted_at = CURRENT_TIMESTAMP WHERE id = ?`
@@ -359,12 +391,25 @@ func (db *DB) RestoreSession(id string) error {
 	return nil
 }
 
-// PruneDeletedSessions permanently removes sessions deleted more than 7 days ago
-func (db *DB) PruneDeletedSessions() (int64, error) {
-	query := `DELETE FROM sessions WHERE status = 'deleted' AND deleted_at < datetime('now', '-7 days')`
-	result, err := db.conn.Exec(query)
+// PruneDeletedSessions permanently removes sessions deleted more than 7 days ago and
+// returns their output files, so the caller can remove what they left on disk
+func (db *DB) PruneDeletedSessions() ([]string, error) {
+	query := `DELETE FROM sessions WHERE status = 'deleted' AND deleted_at < datetime('now', '-7 days') RETURNING output_file`
+	rows, err := db.conn.Query(query)
 	if err != nil {
-		return 0, fmt.Errorf("prune deleted sessions: %w", err)
+		return nil, fmt.Errorf("prune deleted sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var outputFiles []string
+	for rows.Next() {
+		var outputFile sql.NullString
+		if err := rows.Scan(&outputFile); err != nil {
+			return nil, fmt.Errorf("prune deleted sessions: %w", err)
+		}
+		if outputFile.String != "" {
+			outputFiles = append(outputFiles, outputFile.String)
+		}
 	}
-	return result.RowsAffected()
+	return outputFiles, rows.Err()
 }
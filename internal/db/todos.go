@@ -36,21 +36,46 @@ type Todo struct {
 // If an IdempotencyKey is set and a todo with the same key already exists,
 // the existing todo's ID is copied back to t and nil is returned (no new row).
 func (db *DB) CreateTodo(t *Todo) error {
-	if t.IdempotencyKey != nil {
-		existing, err := db.GetTodoByIdempotencyKey(*t.IdempotencyKey)
-		if err != nil {
-			return fmt.Errorf("check idempotency key: %w", err)
-		}
-		if existing != nil {
-			t.ID = existing.ID
-			return nil
+	return insertTodo(db.conn, t)
+}
+
+// CreateTodos inserts several todos in one transaction: either all of them are
+// stored or none is. Todos whose IdempotencyKey is already taken get the existing
+// todo's ID copied back, as with CreateTodo.
+func (db *DB) CreateTodos(todos []*Todo) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op once committed
+
+	for _, t := range todos {
+		if err := insertTodo(tx, t); err != nil {
+			return err
 		}
 	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit todos: %w", err)
+	}
+	return nil
+}
+
+// execQuerier is the part of *sql.DB and *sql.Tx that insertTodo needs.
+type execQuerier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// insertTodo inserts t, or copies back the ID of the todo that already holds its
+// IdempotencyKey. The unique index on the key settles concurrent inserts, so the
+// check and the insert cannot race.
+func insertTodo(q execQuerier, t *Todo) error {
 	query := `
 		INSERT INTO todos (id, status, summary, date, source, url, channel, sender, idempotency_key, full_message)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
 	`
-	_, err := db.conn.Exec(query,
+	res, err := q.Exec(query,
 		t.ID, t.Status, t.Summary,
 		nullTime(t.Date), nullString(t.Source), nullString(t.URL),
 		nullString(t.Channel), nullString(t.Sender),
@@ -59,6 +84,16 @@ func (db *DB) CreateTodo(t *Todo) error {
 	if err != nil {
 		return fmt.Errorf("insert todo: %w", err)
 	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("insert todo: %w", err)
+	}
+	if n > 0 || t.IdempotencyKey == nil {
+		return nil
+	}
+	if err := q.QueryRow(`SELECT id FROM todos WHERE idempotency_key = ?`, *t.IdempotencyKey).Scan(&t.ID); err != nil {
+		return fmt.Errorf("check idempotency key: %w", err)
+	}
 	return nil
 }
 
@@ -283,6 +283,54 @@ func TestIdempotencyKey(t *testing.T) {
 	})
 }
 
+func TestCreateTodos(t *testing.T) {
+	t.Run("existing keys keep their IDs", func(t *testing.T) {
+		db := openTestDB(t)
+		key := "batch-key"
+		if err := db.CreateTodo(&Todo{ID: "bat00001", Status: TodoStatusTodo, Summary: "First", IdempotencyKey: &key}); err != nil {
+			t.Fatalf("CreateTodo() error = %v", err)
+		}
+
+		dup := &Todo{ID: "bat00002", Status: TodoStatusTodo, Summary: "Again", IdempotencyKey: &key}
+		fresh := &Todo{ID: "bat00003", Status: TodoStatusTodo, Summary: "New"}
+		if err := db.CreateTodos([]*Todo{dup, fresh}); err != nil {
+			t.Fatalf("CreateTodos() error = %v", err)
+		}
+		if dup.ID != "bat00001" {
+			t.Errorf("duplicate ID = %q, want %q", dup.ID, "bat00001")
+		}
+		if fresh.ID != "bat00003" {
+			t.Errorf("new ID = %q, want %q", fresh.ID, "bat00003")
+		}
+		all, err := db.ListTodos("")
+		if err != nil {
+			t.Fatalf("ListTodos() error = %v", err)
+		}
+		if len(all) != 2 {
+			t.Errorf("len(all) = %d, want 2", len(all))
+		}
+	})
+
+	t.Run("a failing insert stores nothing", func(t *testing.T) {
+		db := openTestDB(t)
+		k1, k2 := "key-a", "key-b"
+		err := db.CreateTodos([]*Todo{
+			{ID: "same0001", Status: TodoStatusTodo, Summary: "A", IdempotencyKey: &k1},
+			{ID: "same0001", Status: TodoStatusTodo, Summary: "B", IdempotencyKey: &k2},
+		})
+		if err == nil {
+			t.Fatal("CreateTodos() error = nil, want duplicate ID error")
+		}
+		all, err := db.ListTodos("")
+		if err != nil {
+			t.Fatalf("ListTodos() error = %v", err)
+		}
+		if len(all) != 0 {
+			t.Errorf("len(all) = %d, want 0 (rolled back)", len(all))
+		}
+	})
+}
+
 func TestSearchTodos(t *testing.T) {
 	t.Run("search by ID", func(t *testing.T) {
 		db := openTestDB(t)
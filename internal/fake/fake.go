@@ -0,0 +1,252 @@
+// Package fake implements the Agent interface with a scripted stand-in for an agent CLI,
+// so playbooks, the runner and the dashboard can be exercised without a real agent.
+//
+// The script is a JSON file named by $CMT_FAKE_SCRIPT:
+//
+//	{
+//	  "auto_terminate_after": "300ms",
+//	  "steps": [{"output": "Working on {prompt}\n"}, {"stay": true}],
+//	  "commands": {
+//	    "plan": [
+//	      {"output": "Planning...\n", "delay": "100ms"},
+//	      {"write": "thoughts/shared/plans/plan.md", "content": "# Plan\n"},
+//	      {"output": "Wrote thoughts/shared/plans/plan.md\n"},
+//	      {"session_id": "01FAKESESSION"},
+//	      {"exit": 0}
+//	    ]
+//	  }
+//	}
+//
+// Each command type runs its entry in commands, or steps when it has none. Without a
+// script a built-in demo script is used.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/runner"
+)
+
+// EnvScript names the script file the fake agent runs.
+const EnvScript = "CMT_FAKE_SCRIPT"
+
+// Step is one action of the fake agent. Delay applies before the action; a step may
+// combine a delay with one action.
+type Step struct {
+	Delay     string `json:"delay,omitempty"`      // wait this long first (Go duration)
+	Output    string `json:"output,omitempty"`     // print this; {prompt} is replaced by the prompt
+	Write     string `json:"write,omitempty"`      // write Content to this file (parent dirs are created)
+	Content   string `json:"content,omitempty"`    // content for Write
+	SessionID string `json:"session_id,omitempty"` // print a session ID line ("session_<id>")
+	WaitInput bool   `json:"wait_input,omitempty"` // block until a line arrives on stdin
+	Stay      bool   `json:"stay,omitempty"`       // keep running until killed, as an interactive agent does (last step)
+	Exit      *int   `json:"exit,omitempty"`       // exit with this code (last step)
+}
+
+// Script is a fake agent transcript.
+type Script struct {
+	AutoTerminateAfter string            `json:"auto_terminate_after,omitempty"` // idle time before auto-terminate (default the runner's)
+	Steps              []Step            `json:"steps"`
+	Commands           map[string][]Step `json:"commands,omitempty"` // command type → steps
+}
+
+// sessionLineRe matches the session ID line a session_id step prints.
+var sessionLineRe = regexp.MustCompile(`session_([A-Za-z0-9]{10,})`)
+
+// sessionIDRe matches the IDs a session_id step may print.
+var sessionIDRe = regexp.MustCompile(`^[A-Za-z0-9]{10,}$`)
+
+// demoScript is run when no script is configured.
+var demoScript = Script{
+	Steps: []Step{
+		{Output: "fake agent: {prompt}\n"},
+		{Delay: "500ms", Output: "Done.\n"},
+		{Stay: true},
+	},
+	Commands: map[string][]Step{
+		string(agent.CommandResearch): {
+			{Output: "fake agent researching: {prompt}\n"},
+			{Delay: "500ms", Write: "thoughts/shared/research/fake-research.md", Content: "# Research\n\nNothing to see here.\n"},
+			{Output: "Wrote thoughts/shared/research/fake-research.md\n"},
+			{Stay: true},
+		},
+		string(agent.CommandPlan): {
+			{Output: "fake agent planning: {prompt}\n"},
+			{Delay: "500ms", Write: "thoughts/shared/plans/fake-plan.md", Content: "# Plan\n\n## Overview\n\n## Testing Strategy\n"},
+			{Output: "Wrote thoughts/shared/plans/fake-plan.md\n"},
+			{Stay: true},
+		},
+	},
+}
+
+// LoadScript reads and validates a script file.
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fake agent script: %w", err)
+	}
+	var s Script
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse fake agent script %s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("fake agent script %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func (s *Script) validate() error {
+	if s.AutoTerminateAfter != "" {
+		if _, err := time.ParseDuration(s.AutoTerminateAfter); err != nil {
+			return fmt.Errorf("invalid auto_terminate_after %q: %w", s.AutoTerminateAfter, err)
+		}
+	}
+	if err := validateSteps(s.Steps); err != nil {
+		return fmt.Errorf("steps: %w", err)
+	}
+	for cmd, steps := range s.Commands {
+		if err := validateSteps(steps); err != nil {
+			return fmt.Errorf("commands.%s: %w", cmd, err)
+		}
+	}
+	return nil
+}
+
+func validateSteps(steps []Step) error {
+	for i, st := range steps {
+		if st.Delay != "" {
+			if _, err := time.ParseDuration(st.Delay); err != nil {
+				return fmt.Errorf("step %d: invalid delay %q", i+1, st.Delay)
+			}
+		}
+		if st.SessionID != "" && !sessionIDRe.MatchString(st.SessionID) {
+			return fmt.Errorf("step %d: session_id must be at least 10 letters or digits", i+1)
+		}
+		if (st.Stay || st.Exit != nil) && i != len(steps)-1 {
+			return fmt.Errorf("step %d: stay and exit must be the last step", i+1)
+		}
+	}
+	return nil
+}
+
+// StepsFor returns the steps run for a command type.
+func (s *Script) StepsFor(cmd agent.CommandType) []Step {
+	if steps, ok := s.Commands[string(cmd)]; ok {
+		return steps
+	}
+	return s.Steps
+}
+
+// Runner runs scripted fake agent sessions.
+type Runner struct {
+	base   *runner.Base
+	script *Script
+}
+
+// Ensure Runner implements agent.Agent at compile time.
+var _ agent.Agent = (*Runner)(nil)
+
+// NewRunner creates a fake runner for the script named by $CMT_FAKE_SCRIPT, or the demo
+// script if it is unset.
+func NewRunner(database *db.DB) (*Runner, error) {
+	script := &demoScript
+	if path := os.Getenv(EnvScript); path != "" {
+		var err error
+		if script, err = LoadScript(path); err != nil {
+			return nil, err
+		}
+	}
+	return NewScriptRunner(database, script)
+}
+
+// NewScriptRunner creates a fake runner for the given script.
+func NewScriptRunner(database *db.DB, script *Script) (*Runner, error) {
+	base, err := runner.NewBase(database)
+	if err != nil {
+		return nil, err
+	}
+	if script.AutoTerminateAfter != "" {
+		base.AutoTerminateAfter, _ = time.ParseDuration(script.AutoTerminateAfter)
+	}
+	base.SessionIDs = agent.OutputPattern{Re: sessionLineRe}
+	return &Runner{base: base, script: script}, nil
+}
+
+// Run starts a scripted session.
+func (r *Runner) Run(ctx context.Context, opts agent.RunOptions) error {
+	return r.base.Execute(ctx, r.buildCommand(opts), opts)
+}
+
+// DefaultModel returns "" because the fake agent has no models.
+func (r *Runner) DefaultModel(cmd agent.CommandType) string {
+	return ""
+}
+
+// DefaultEffort returns "" because the fake agent has no effort concept.
+func (r *Runner) DefaultEffort(cmd agent.CommandType) string {
+	return ""
+}
+
+// Prompt returns the task description with the prompt prefix applied.
+func (r *Runner) Prompt(opts agent.RunOptions) string {
+	return agent.ApplyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+}
+
+// Capabilities reports that the fake agent only prints session IDs; it runs its script
+// whatever else is asked of it.
+func (r *Runner) Capabilities() agent.Capabilities {
+	return agent.Capabilities{SessionIDCapture: true}
+}
+
+// buildCommand renders the script's steps for opts as a shell script.
+func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
+	return exec.Command("sh", "-c", shellScript(r.script.StepsFor(opts.Command), r.Prompt(opts)))
+}
+
+// shellScript renders steps as a POSIX shell script.
+func shellScript(steps []Step, prompt string) string {
+	var sb strings.Builder
+	for _, st := range steps {
+		if d, err := time.ParseDuration(st.Delay); err == nil && d > 0 {
+			fmt.Fprintf(&sb, "sleep %s\n", strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+		}
+		if st.Output != "" {
+			fmt.Fprintf(&sb, "printf '%%s' %s\n", quote(strings.ReplaceAll(st.Output, "{prompt}", prompt)))
+		}
+		if st.Write != "" {
+			if dir := filepath.Dir(st.Write); dir != "." {
+				fmt.Fprintf(&sb, "mkdir -p %s\n", quote(dir))
+			}
+			fmt.Fprintf(&sb, "printf '%%s' %s > %s\n", quote(st.Content), quote(st.Write))
+		}
+		if st.SessionID != "" {
+			fmt.Fprintf(&sb, "printf 'session_%%s\\n' %s\n", quote(st.SessionID))
+		}
+		if st.WaitInput {
+			sb.WriteString("read -r cmt_input || true\n")
+		}
+		if st.Stay {
+			sb.WriteString("exec sleep 86400\n")
+		}
+		if st.Exit != nil {
+			fmt.Fprintf(&sb, "exit %d\n", *st.Exit)
+		}
+	}
+	return sb.String()
+}
+
+// quote single-quotes s for the shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,159 @@
+package fake
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestLoadScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: `{"steps": [{"output": "hi\n", "delay": "10ms"}, {"exit": 0}], "commands": {"plan": [{"stay": true}]}}`},
+		{name: "invalid delay", content: `{"steps": [{"delay": "soon"}]}`, wantErr: "invalid delay"},
+		{name: "short session id", content: `{"steps": [{"session_id": "abc"}]}`, wantErr: "session_id"},
+		{name: "exit before last step", content: `{"commands": {"plan": [{"exit": 1}, {"output": "x"}]}}`, wantErr: "commands.plan"},
+		{name: "invalid auto-terminate", content: `{"auto_terminate_after": "x", "steps": []}`, wantErr: "auto_terminate_after"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "script.json")
+			os.WriteFile(path, []byte(tt.content), 0o644)
+			_, err := LoadScript(path)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("LoadScript() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("LoadScript() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShellScript(t *testing.T) {
+	dir := t.TempDir()
+	script := shellScript([]Step{
+		{Output: "it's {prompt}\n"},
+		{Delay: "10ms", Write: "out/a.md", Content: "# A\n$HOME `x`\n"},
+		{SessionID: "01FAKESESSION"},
+		{Exit: intPtr(3)},
+	}, "my task")
+
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 3 {
+		t.Fatalf("script error = %v, want exit status 3", err)
+	}
+	if want := "it's my task\nsession_01FAKESESSION\n"; string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out", "a.md")); string(data) != "# A\n$HOME `x`\n" {
+		t.Errorf("written file = %q", data)
+	}
+}
+
+func TestRunnerExecute(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	script := &Script{
+		AutoTerminateAfter: "200ms",
+		Steps:              []Step{{Output: "{prompt}\n"}, {Exit: intPtr(2)}},
+		Commands: map[string][]Step{
+			string(agent.CommandPlan): {
+				{Write: "thoughts/shared/plans/p.md", Content: "# Plan\n"},
+				{Output: "Wrote thoughts/shared/plans/p.md\n"},
+				{SessionID: "01FAKESESSION"},
+				{Stay: true},
+			},
+			string(agent.CommandNew): {
+				{WaitInput: true},
+				{Output: "got input\n"},
+				{Exit: intPtr(0)},
+			},
+		},
+	}
+	r, err := NewScriptRunner(database, script)
+	if err != nil {
+		t.Fatalf("NewScriptRunner() error = %v", err)
+	}
+	workDir := t.TempDir()
+
+	t.Run("auto-terminated plan session", func(t *testing.T) {
+		var files []string
+		var sessionID string
+		start := time.Now()
+		err := r.Run(context.Background(), agent.RunOptions{
+			Command:           agent.CommandPlan,
+			WorkflowType:      db.WorkflowPlan,
+			WorkingDir:        workDir,
+			Headless:          true,
+			AutoTerminate:     true,
+			Agent:             "fake",
+			CapturedFiles:     &files,
+			CapturedSessionID: &sessionID,
+		})
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Run() took %s, want the short auto-terminate threshold", elapsed)
+		}
+		if !slices.Equal(files, []string{"thoughts/shared/plans/p.md"}) || sessionID != "01FAKESESSION" {
+			t.Errorf("captured files %v, session ID %q", files, sessionID)
+		}
+		if _, err := os.Stat(filepath.Join(workDir, "thoughts/shared/plans/p.md")); err != nil {
+			t.Errorf("plan file not written: %v", err)
+		}
+	})
+
+	t.Run("failing exit", func(t *testing.T) {
+		err := r.Run(context.Background(), agent.RunOptions{
+			Command:         agent.CommandResearch,
+			WorkflowType:    db.WorkflowResearch,
+			TaskDescription: "auth",
+			WorkingDir:      workDir,
+			Headless:        true,
+		})
+		if err == nil || !strings.Contains(err.Error(), "exit status 2") {
+			t.Fatalf("Run() error = %v, want exit status 2", err)
+		}
+	})
+
+	t.Run("waits for initial input", func(t *testing.T) {
+		err := r.Run(context.Background(), agent.RunOptions{
+			Command:      agent.CommandNew,
+			WorkflowType: db.WorkflowGeneral,
+			WorkingDir:   workDir,
+			Headless:     true,
+			InitialInput: "hello",
+		})
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	})
+
+	sessions, _ := database.ListSessions("")
+	if len(sessions) != 3 || sessions[2].Agent != "fake" || sessions[2].Status != db.StatusCompleted {
+		t.Errorf("sessions = %+v", sessions)
+	}
+}
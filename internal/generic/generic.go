@@ -0,0 +1,168 @@
+// Package generic implements the Agent interface for agent CLIs described in the agents
+// config file rather than in code.
+package generic
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/config"
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/runner"
+)
+
+// Runner manages execution of a configured agent CLI.
+type Runner struct {
+	base *runner.Base
+	spec *config.AgentSpec
+}
+
+// Ensure Runner implements agent.Agent at compile time.
+var _ agent.Agent = (*Runner)(nil)
+
+// NewRunner creates a runner for the agent described by spec.
+func NewRunner(database *db.DB, spec *config.AgentSpec) (*Runner, error) {
+	base, err := runner.NewBase(database)
+	if err != nil {
+		return nil, err
+	}
+	if re := spec.SessionIDRegexp(); re != nil {
+		base.SessionIDs = agent.OutputPattern{Re: re}
+	}
+	return &Runner{base: base, spec: spec}, nil
+}
+
+// Run starts a session of the configured agent.
+func (r *Runner) Run(ctx context.Context, opts agent.RunOptions) error {
+	execOpts := r.prepareRunOptions(opts)
+	cmd := r.buildCommand(execOpts)
+	return r.base.Execute(ctx, cmd, execOpts)
+}
+
+func (r *Runner) prepareRunOptions(opts agent.RunOptions) agent.RunOptions {
+	execOpts := opts
+	if r.spec.PromptMode == config.PromptInput && !opts.PrintMode {
+		execOpts.InitialInput = r.Prompt(opts)
+		execOpts.InitialInputDelay = r.spec.Delay()
+	}
+	return execOpts
+}
+
+// DefaultModel returns the configured default model for a command type, falling back to
+// the "default" entry.
+func (r *Runner) DefaultModel(cmd agent.CommandType) string {
+	return lookup(r.spec.DefaultModels, cmd)
+}
+
+// DefaultEffort returns the configured default effort for a command type, falling back
+// to the "default" entry.
+func (r *Runner) DefaultEffort(cmd agent.CommandType) string {
+	return lookup(r.spec.DefaultEfforts, cmd)
+}
+
+func lookup(defaults map[string]string, cmd agent.CommandType) string {
+	if v, ok := defaults[string(cmd)]; ok {
+		return v
+	}
+	return defaults["default"]
+}
+
+// Prompt returns the task description with the prompt prefix applied in the configured
+// slash command style.
+func (r *Runner) Prompt(opts agent.RunOptions) string {
+	prefix := agent.GetPromptPrefix(opts.Command, opts.CommentTag)
+	if strings.HasPrefix(prefix, "/") {
+		switch r.spec.SlashCommands {
+		case config.SlashNone:
+			prefix = ""
+		case config.SlashHyphen:
+			command, rest, found := strings.Cut(prefix, " ")
+			prefix = strings.ReplaceAll(command, "_", "-")
+			if found {
+				prefix += " " + rest
+			}
+		}
+	}
+	switch {
+	case prefix == "":
+		return opts.TaskDescription
+	case opts.TaskDescription == "":
+		return prefix
+	}
+	return prefix + " " + opts.TaskDescription
+}
+
+// Capabilities reports the options the configured argument templates cover.
+func (r *Runner) Capabilities() agent.Capabilities {
+	caps := agent.Capabilities{
+		Binary:           r.spec.Binary,
+		Resume:           len(r.spec.ResumeArgs) > 0,
+		ResumePicker:     len(r.spec.ResumePickerArgs) > 0,
+		Models:           len(r.spec.ModelArgs) > 0,
+		PrintMode:        len(r.spec.PrintArgs) > 0,
+		Autonomous:       len(r.spec.AutonomousArgs) > 0,
+		SessionIDCapture: r.spec.SessionIDPattern != "",
+	}
+	if len(r.spec.EffortArgs) > 0 {
+		caps.Efforts = r.spec.Efforts
+		if len(caps.Efforts) == 0 {
+			caps.Efforts = agent.EffortLevels
+		}
+	}
+	return caps
+}
+
+// buildCommand constructs the agent command from the spec's argument templates.
+func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
+	model := opts.Model
+	if model == "" {
+		model = r.DefaultModel(opts.Command)
+	}
+	effort := opts.Effort
+	if effort == "" {
+		effort = r.DefaultEffort(opts.Command)
+	}
+	prompt := r.Prompt(opts)
+	vars := strings.NewReplacer("{model}", model, "{effort}", effort, "{prompt}", prompt, "{session}", opts.ResumeSessionID)
+	expand := func(templates []string) []string {
+		out := make([]string, len(templates))
+		for i, t := range templates {
+			out[i] = vars.Replace(t)
+		}
+		return out
+	}
+
+	args := expand(r.spec.Args)
+	if model != "" {
+		args = append(args, expand(r.spec.ModelArgs)...)
+	}
+	if effort != "" {
+		args = append(args, expand(r.spec.EffortArgs)...)
+	}
+	if opts.AutonomousMode {
+		args = append(args, expand(r.spec.AutonomousArgs)...)
+	}
+	if opts.PrintMode {
+		args = append(args, expand(r.spec.PrintArgs)...)
+	}
+	switch opts.ResumeSessionID {
+	case "":
+	case "*":
+		args = append(args, expand(r.spec.ResumePickerArgs)...)
+	default:
+		args = append(args, expand(r.spec.ResumeArgs)...)
+	}
+
+	// Input-mode prompts are typed into the session instead, except in print mode
+	if prompt != "" && (opts.PrintMode || r.spec.PromptMode != config.PromptInput) {
+		promptArgs := r.spec.PromptArgs
+		if len(promptArgs) == 0 {
+			promptArgs = []string{"{prompt}"}
+		}
+		args = append(args, expand(promptArgs)...)
+	}
+
+	return exec.Command(r.spec.Binary, args...)
+}
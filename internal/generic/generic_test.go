@@ -0,0 +1,129 @@
+package generic
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/config"
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+func newTestRunner(t *testing.T, spec *config.AgentSpec) *Runner {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	r, err := NewRunner(database, spec)
+	if err != nil {
+		t.Fatalf("NewRunner() error = %v", err)
+	}
+	return r
+}
+
+func TestBuildCommand(t *testing.T) {
+	spec := &config.AgentSpec{
+		Binary:           "aider",
+		Args:             []string{"--no-auto-commits"},
+		ModelArgs:        []string{"--model", "{model}"},
+		EffortArgs:       []string{"--reasoning-effort", "{effort}"},
+		PrintArgs:        []string{"--exit"},
+		AutonomousArgs:   []string{"--yes-always"},
+		ResumeArgs:       []string{"--restore-chat-history", "{session}"},
+		ResumePickerArgs: []string{"--restore-chat-history"},
+		PromptArgs:       []string{"--message", "{prompt}"},
+		DefaultModels:    map[string]string{"quick": "haiku", "default": "sonnet"},
+	}
+	r := newTestRunner(t, spec)
+
+	tests := []struct {
+		name string
+		opts agent.RunOptions
+		want []string
+	}{
+		{
+			name: "default model and prompt",
+			opts: agent.RunOptions{Command: agent.CommandNew, TaskDescription: "add dark mode"},
+			want: []string{"aider", "--no-auto-commits", "--model", "sonnet", "--message", "add dark mode"},
+		},
+		{
+			name: "per-command default model",
+			opts: agent.RunOptions{Command: agent.CommandQuick, TaskDescription: "hi"},
+			want: []string{"aider", "--no-auto-commits", "--model", "haiku", "--message", "hi"},
+		},
+		{
+			name: "all options",
+			opts: agent.RunOptions{
+				Command:         agent.CommandResearch,
+				TaskDescription: "auth flow",
+				Model:           "opus",
+				Effort:          "high",
+				AutonomousMode:  true,
+				PrintMode:       true,
+				ResumeSessionID: "abc",
+			},
+			want: []string{"aider", "--no-auto-commits", "--model", "opus", "--reasoning-effort", "high", "--yes-always", "--exit",
+				"--restore-chat-history", "abc", "--message", "/research_codebase auth flow"},
+		},
+		{
+			name: "resume picker",
+			opts: agent.RunOptions{Command: agent.CommandNew, ResumeSessionID: "*"},
+			want: []string{"aider", "--no-auto-commits", "--model", "sonnet", "--restore-chat-history"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := r.buildCommand(r.prepareRunOptions(tt.opts))
+			if !slices.Equal(cmd.Args, tt.want) {
+				t.Errorf("Args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+}
+
+func TestInputPromptMode(t *testing.T) {
+	r := newTestRunner(t, &config.AgentSpec{Binary: "gemini", PromptMode: config.PromptInput, InputDelay: "2s"})
+	opts := r.prepareRunOptions(agent.RunOptions{Command: agent.CommandPlan, TaskDescription: "checkout"})
+
+	if opts.InitialInput != "/create_plan checkout" || opts.InitialInputDelay != 2*time.Second {
+		t.Errorf("InitialInput = %q after %s", opts.InitialInput, opts.InitialInputDelay)
+	}
+	if cmd := r.buildCommand(opts); !slices.Equal(cmd.Args, []string{"gemini"}) {
+		t.Errorf("Args = %q, want no prompt argument", cmd.Args)
+	}
+
+	printOpts := r.prepareRunOptions(agent.RunOptions{Command: agent.CommandPlan, TaskDescription: "checkout", PrintMode: true})
+	if printOpts.InitialInput != "" {
+		t.Errorf("print mode InitialInput = %q, want the prompt passed as an argument", printOpts.InitialInput)
+	}
+}
+
+func TestPromptSlashCommands(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{style: "", want: "/research_codebase auth"},
+		{style: config.SlashHyphen, want: "/research-codebase auth"},
+		{style: config.SlashNone, want: "auth"},
+	}
+
+	for _, tt := range tests {
+		r := &Runner{spec: &config.AgentSpec{Binary: "x", SlashCommands: tt.style}}
+		if got := r.Prompt(agent.RunOptions{Command: agent.CommandResearch, TaskDescription: "auth"}); got != tt.want {
+			t.Errorf("Prompt() with slash_commands %q = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+
+	// Plain-language prefixes are kept even without slash commands
+	r := &Runner{spec: &config.AgentSpec{Binary: "x", SlashCommands: config.SlashNone}}
+	if got := r.Prompt(agent.RunOptions{Command: agent.CommandFixTest, TaskDescription: "a_test.go"}); got != "Analyze and fix the failing test at: a_test.go" {
+		t.Errorf("Prompt() = %q", got)
+	}
+}
@@ -0,0 +1,287 @@
+// Package hooks runs user scripts on session events. Hooks are executables named after
+// the event, in ~/.config/cmt/hooks/ for every session and in .cmt/hooks/ of a venue for
+// the sessions in that directory or below it. Venue hooks come with the repository, so
+// they only run once trusted with `cmt hooks trust`.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// EnvHooksDir overrides the directory of the global hooks.
+const EnvHooksDir = "CMT_HOOKS_DIR"
+
+// VenueDir is where a venue keeps its hooks, relative to the venue.
+const VenueDir = ".cmt/hooks"
+
+// hookTimeout bounds a hook run.
+const hookTimeout = 10 * time.Minute
+
+// Event is a session event hooks run on. A hook's file name is its event.
+type Event string
+
+// Events, as hook files are named.
+const (
+	PreSession      Event = "pre-session"       // before the agent starts; a failing hook refuses the session
+	PostSession     Event = "post-session"      // after the session ended, with its final status
+	OnWaiting       Event = "on-waiting"        // each time the agent stops working and waits for input
+	OnPhaseComplete Event = "on-phase-complete" // after a play phase finished
+	OnCapture       Event = "on-capture"        // when the session's output names a new captured file
+)
+
+// Events lists all events, in the order they happen.
+var Events = []Event{PreSession, OnWaiting, OnCapture, OnPhaseComplete, PostSession}
+
+// Payload is what hooks receive as JSON on stdin.
+type Payload struct {
+	Event   Event     `json:"event"`
+	Session Session   `json:"session"`
+	Phase   *Phase    `json:"phase,omitempty"` // on-phase-complete
+	File    string    `json:"file,omitempty"`  // on-capture
+	Time    time.Time `json:"time"`
+}
+
+// Session describes the session an event is about.
+type Session struct {
+	ID             string `json:"id"`
+	Workflow       string `json:"workflow"`
+	Status         string `json:"status"`
+	Directory      string `json:"directory"`
+	Task           string `json:"task"`
+	Agent          string `json:"agent,omitempty"`
+	AgentSessionID string `json:"agent_session_id,omitempty"`
+	ParentID       string `json:"parent_id,omitempty"`
+	OutputFile     string `json:"output_file,omitempty"`
+	Autonomous     bool   `json:"autonomous"`
+}
+
+// Phase describes a finished play phase.
+type Phase struct {
+	Number int      `json:"number"` // 1-based
+	Type   string   `json:"type"`
+	Files  []string `json:"files,omitempty"` // files the phase captured
+}
+
+// NewPayload returns the payload of event for session.
+func NewPayload(event Event, session *db.Session, autonomous bool) Payload {
+	return Payload{
+		Event: event,
+		Session: Session{
+			ID:             session.ID,
+			Workflow:       string(session.WorkflowType),
+			Status:         string(session.Status),
+			Directory:      session.WorkingDirectory,
+			Task:           session.TaskDescription,
+			Agent:          session.Agent,
+			AgentSessionID: session.ClaudeSessionID,
+			ParentID:       session.ParentID,
+			OutputFile:     session.OutputFile,
+			Autonomous:     autonomous,
+		},
+		Time: time.Now(),
+	}
+}
+
+// env returns the environment variables describing p, for hooks that would rather not
+// parse JSON. The agent and autonomous mode are not CMT_AGENT and CMT_AUTONOMOUS, which
+// would change how a cmt run by the hook behaves.
+func (p Payload) env() []string {
+	env := []string{
+		"CMT_HOOK=" + string(p.Event),
+		"CMT_SESSION_ID=" + p.Session.ID,
+		"CMT_STATUS=" + p.Session.Status,
+		"CMT_WORKDIR=" + p.Session.Directory,
+		"CMT_WORKFLOW=" + p.Session.Workflow,
+		"CMT_SESSION_AGENT=" + p.Session.Agent,
+		"CMT_SESSION_AUTONOMOUS=" + strconv.FormatBool(p.Session.Autonomous),
+		"CMT_PARENT_ID=" + p.Session.ParentID,
+	}
+	if p.Phase != nil {
+		env = append(env, fmt.Sprintf("CMT_PHASE=%d", p.Phase.Number), "CMT_PHASE_TYPE="+p.Phase.Type)
+	}
+	if p.File != "" {
+		env = append(env, "CMT_FILE="+p.File)
+	}
+	return env
+}
+
+// Hooks finds and runs hooks.
+type Hooks struct {
+	GlobalDir string // hooks run for every session
+	TrustFile string // hashes of the trusted venue hooks
+}
+
+// Default returns the hooks of the user: ~/.config/cmt/hooks/ (or $CMT_HOOKS_DIR) and
+// the venue hooks trusted in ~/.config/cmt/trusted-hooks.json.
+func Default() (*Hooks, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("get home directory: %w", err)
+	}
+	h := &Hooks{
+		GlobalDir: filepath.Join(home, ".config", "cmt", "hooks"),
+		TrustFile: filepath.Join(home, ".config", "cmt", "trusted-hooks.json"),
+	}
+	if dir := os.Getenv(EnvHooksDir); dir != "" {
+		h.GlobalDir = dir
+	}
+	return h, nil
+}
+
+// Hook is a hook file.
+type Hook struct {
+	Event   Event
+	Path    string
+	Venue   bool // from the venue's .cmt/hooks/ rather than the global directory
+	Trusted bool // always true for global hooks
+}
+
+// List returns the hooks for sessions in workDir: the global ones, then those of the
+// venue workDir is in, in event order. Files that are not executable are not hooks.
+func (h *Hooks) List(workDir string) ([]Hook, error) {
+	trusted, err := h.loadTrust()
+	if err != nil {
+		return nil, err
+	}
+	var list []Hook
+	for _, event := range Events {
+		if path := executable(filepath.Join(h.GlobalDir, string(event))); path != "" {
+			list = append(list, Hook{Event: event, Path: path, Trusted: true})
+		}
+	}
+	venueDir := FindVenueDir(workDir)
+	if venueDir == "" {
+		return list, nil
+	}
+	for _, event := range Events {
+		if path := executable(filepath.Join(venueDir, string(event))); path != "" {
+			sum, err := fileHash(path)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, Hook{Event: event, Path: path, Venue: true, Trusted: trusted[path] == sum})
+		}
+	}
+	return list, nil
+}
+
+// Has reports whether there is a hook for event for sessions in workDir, trusted or not.
+// It only looks for the files, so it is cheap enough to call before every event.
+func (h *Hooks) Has(event Event, workDir string) bool {
+	if h == nil {
+		return false
+	}
+	if executable(filepath.Join(h.GlobalDir, string(event))) != "" {
+		return true
+	}
+	venueDir := FindVenueDir(workDir)
+	return venueDir != "" && executable(filepath.Join(venueDir, string(event))) != ""
+}
+
+// FindVenueDir returns the hooks directory of the venue workDir is in, walking up from
+// workDir so sessions in a subdirectory get their repository's hooks, or "" if there is none.
+func FindVenueDir(workDir string) string {
+	if workDir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(workDir)
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, VenueDir)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Untrusted returns the venue hooks for workDir that will not run until trusted.
+func (h *Hooks) Untrusted(workDir string) ([]Hook, error) {
+	if h == nil {
+		return nil, nil
+	}
+	list, err := h.List(workDir)
+	if err != nil {
+		return nil, err
+	}
+	var untrusted []Hook
+	for _, hook := range list {
+		if !hook.Trusted {
+			untrusted = append(untrusted, hook)
+		}
+	}
+	return untrusted, nil
+}
+
+// executable returns path if it is an executable file, or "".
+func executable(path string) string {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		return ""
+	}
+	return path
+}
+
+// Run runs the hooks of p's event for its session, in its directory, with their output
+// going to out. Untrusted venue hooks are skipped (see Untrusted). A failing hook does
+// not keep the others from running; the error of the first is returned.
+func (h *Hooks) Run(p Payload, out io.Writer) error {
+	if h == nil {
+		return nil
+	}
+	list, err := h.List(p.Session.Directory)
+	if err != nil {
+		return err
+	}
+	input, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("encode hook payload: %w", err)
+	}
+	input = append(input, '\n')
+
+	var firstErr error
+	for _, hook := range list {
+		if hook.Event != p.Event || !hook.Trusted {
+			continue
+		}
+		if err := run(hook.Path, p, input, out); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s hook %s: %w", p.Event, hook.Path, err)
+		}
+	}
+	return firstErr
+}
+
+func run(path string, p Payload, input []byte, out io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = p.Session.Directory
+	cmd.Env = append(os.Environ(), p.env()...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", hookTimeout)
+	}
+	return err
+}
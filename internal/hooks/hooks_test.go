@@ -0,0 +1,156 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// writeHook writes an executable hook script for event into dir.
+func writeHook(t *testing.T, dir string, event Event, script string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, string(event))
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testHooks(t *testing.T) (*Hooks, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	h := &Hooks{GlobalDir: filepath.Join(tmp, "global"), TrustFile: filepath.Join(tmp, "trusted-hooks.json")}
+	venue := filepath.Join(tmp, "venue")
+	if err := os.MkdirAll(venue, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return h, venue
+}
+
+func TestListAndTrust(t *testing.T) {
+	h, venue := testHooks(t)
+	writeHook(t, h.GlobalDir, PostSession, "true")
+	venueHook := writeHook(t, filepath.Join(venue, VenueDir), PreSession, "true")
+	// Files that are not executable are not hooks
+	os.WriteFile(filepath.Join(venue, VenueDir, "README"), []byte("docs"), 0644)
+
+	untrusted, err := h.Untrusted(venue)
+	if err != nil {
+		t.Fatalf("Untrusted() error = %v", err)
+	}
+	if len(untrusted) != 1 || untrusted[0].Path != venueHook {
+		t.Fatalf("Untrusted() = %+v, want the venue's pre-session hook", untrusted)
+	}
+
+	trusted, err := h.Trust(venue)
+	if err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	if len(trusted) != 1 || trusted[0].Path != venueHook {
+		t.Fatalf("Trust() = %+v, want the venue's pre-session hook", trusted)
+	}
+	list, err := h.List(venue)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].Event != PostSession || list[0].Venue || !list[1].Venue || !list[1].Trusted {
+		t.Fatalf("List() = %+v, want the global post-session hook, then the trusted venue hook", list)
+	}
+
+	// Sessions in a subdirectory get the venue's hooks
+	sub := filepath.Join(venue, "internal", "cli")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := h.List(sub); err != nil || len(list) != 2 || list[1].Path != venueHook || !list[1].Trusted {
+		t.Fatalf("List(subdirectory) = %+v, %v, want the venue's trusted hook too", list, err)
+	}
+
+	// A changed hook needs trusting again
+	writeHook(t, filepath.Join(venue, VenueDir), PreSession, "rm -rf ~")
+	if untrusted, _ := h.Untrusted(venue); len(untrusted) != 1 {
+		t.Errorf("changed hook is still trusted")
+	}
+}
+
+func TestHas(t *testing.T) {
+	h, venue := testHooks(t)
+	writeHook(t, h.GlobalDir, PostSession, "true")
+	writeHook(t, filepath.Join(venue, VenueDir), OnCapture, "true")
+
+	tests := []struct {
+		event   Event
+		workDir string
+		want    bool
+	}{
+		{event: PostSession, want: true},
+		{event: OnCapture, workDir: venue, want: true},
+		{event: OnCapture, want: false},
+		{event: OnWaiting, workDir: venue, want: false},
+		{event: OnCapture, workDir: filepath.Join(venue, "internal", "cli"), want: true},
+	}
+	for _, tt := range tests {
+		if got := h.Has(tt.event, tt.workDir); got != tt.want {
+			t.Errorf("Has(%s, %q) = %v, want %v", tt.event, tt.workDir, got, tt.want)
+		}
+	}
+	if (*Hooks)(nil).Has(PostSession, venue) {
+		t.Error("nil Hooks has hooks")
+	}
+}
+
+func TestRun(t *testing.T) {
+	h, venue := testHooks(t)
+	out := filepath.Join(venue, "out")
+	writeHook(t, h.GlobalDir, PostSession, `cat > "$CMT_WORKDIR/out"; echo "$CMT_SESSION_ID $CMT_STATUS $CMT_HOOK" >> "$CMT_WORKDIR/out"`)
+	writeHook(t, filepath.Join(venue, VenueDir), PostSession, `echo untrusted >> "$CMT_WORKDIR/out"`)
+
+	session := &db.Session{ID: "abc", WorkflowType: db.WorkflowImplement, Status: db.StatusCompleted, WorkingDirectory: venue}
+	if err := h.Run(NewPayload(PostSession, session, true), &bytes.Buffer{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	payload, env, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	var p Payload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		t.Fatalf("hook stdin %q is not a payload: %v", payload, err)
+	}
+	if p.Event != PostSession || p.Session.ID != "abc" || p.Session.Workflow != "implement" || !p.Session.Autonomous {
+		t.Errorf("payload = %+v, want the implement session's post-session event", p)
+	}
+	if env != "abc completed post-session" {
+		t.Errorf("hook env = %q, want %q", env, "abc completed post-session")
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	h, venue := testHooks(t)
+	writeHook(t, h.GlobalDir, PreSession, `echo "tree is dirty" >&2; exit 1`)
+
+	var out bytes.Buffer
+	session := &db.Session{ID: "abc", WorkingDirectory: venue}
+	err := h.Run(NewPayload(PreSession, session, false), &out)
+	if err == nil || !strings.Contains(err.Error(), "pre-session hook") {
+		t.Fatalf("Run() error = %v, want the failing pre-session hook", err)
+	}
+	if !strings.Contains(out.String(), "tree is dirty") {
+		t.Errorf("hook output = %q, want its stderr", out.String())
+	}
+
+	// Other events' hooks do not run
+	if err := h.Run(NewPayload(PostSession, session, false), &out); err != nil {
+		t.Errorf("Run(post-session) error = %v, want no hooks run", err)
+	}
+}
@@ -0,0 +1,75 @@
+package hooks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// loadTrust reads the trusted venue hooks: hook path → SHA-256 of its contents. A hook
+// that changed since it was trusted is untrusted again.
+func (h *Hooks) loadTrust() (map[string]string, error) {
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(h.TrustFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read trusted hooks: %w", err)
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("parse trusted hooks %s: %w", h.TrustFile, err)
+	}
+	return trusted, nil
+}
+
+// Trust trusts the current contents of workDir's venue hooks and returns them.
+func (h *Hooks) Trust(workDir string) ([]Hook, error) {
+	list, err := h.List(workDir)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := h.loadTrust()
+	if err != nil {
+		return nil, err
+	}
+
+	var venue []Hook
+	for _, hook := range list {
+		if !hook.Venue {
+			continue
+		}
+		sum, err := fileHash(hook.Path)
+		if err != nil {
+			return nil, err
+		}
+		trusted[hook.Path] = sum
+		hook.Trusted = true
+		venue = append(venue, hook)
+	}
+
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode trusted hooks: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.TrustFile), 0755); err != nil {
+		return nil, fmt.Errorf("create config directory: %w", err)
+	}
+	if err := os.WriteFile(h.TrustFile, data, 0644); err != nil {
+		return nil, fmt.Errorf("write trusted hooks: %w", err)
+	}
+	return venue, nil
+}
+
+func fileHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read hook: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,84 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailFields are the names email-to-JSON services (Postmark, Mailgun, SendGrid and
+// others) give each part of a message, matched case-insensitively.
+var emailFields = map[string][]string{
+	"from":       {"from", "sender", "from_email"},
+	"to":         {"to", "recipient", "to_email"},
+	"subject":    {"subject"},
+	"text":       {"text", "textbody", "body-plain", "stripped-text", "plain", "body"},
+	"message_id": {"message_id", "messageid", "message-id"},
+	"date":       {"date", "timestamp"},
+}
+
+// Email reads a message from an email-to-JSON service: from, to, subject, a plain
+// text body, a message ID and a date, under any of the names such services use.
+func Email(_ http.Header, body []byte) ([]Item, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("invalid email payload: %w", err)
+	}
+	fields := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			fields[strings.ToLower(k)] = s
+		}
+	}
+	get := func(part string) string {
+		for _, name := range emailFields[part] {
+			if v := strings.TrimSpace(fields[name]); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	from, subject, text := get("from"), get("subject"), get("text")
+	if from == "" {
+		return nil, fmt.Errorf("email has no sender")
+	}
+	if addr, err := mail.ParseAddress(from); err == nil {
+		from = addr.Address
+	}
+	if subject == "" {
+		subject = summarize(text)
+	}
+	if subject == "" {
+		subject = "(no subject)"
+	}
+
+	item := Item{
+		Summary:     subject,
+		Source:      "email",
+		Channel:     get("to"),
+		Sender:      from,
+		FullMessage: text,
+	}
+	if date := get("date"); date != "" {
+		if sec, err := strconv.ParseInt(date, 10, 64); err == nil {
+			item.Date = time.Unix(sec, 0).UTC().Format(time.RFC3339)
+		} else if _, err := parseDate(date); err == nil {
+			item.Date = date
+		}
+	}
+	// Without a message ID, the same sender, subject and date are taken to be the same email
+	key := get("message_id")
+	if key == "" {
+		sum := sha256.Sum256([]byte(from + "\n" + subject + "\n" + get("date")))
+		key = hex.EncodeToString(sum[:8])
+	}
+	item.IdempotencyKey = "email:" + strings.Trim(key, "<>")
+	return []Item{item}, nil
+}
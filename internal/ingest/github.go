@@ -0,0 +1,133 @@
+package ingest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+// githubComment is an issue or PR comment, or a PR review.
+type githubComment struct {
+	ID          int64      `json:"id"`
+	HTMLURL     string     `json:"html_url"`
+	Body        string     `json:"body"`
+	User        githubUser `json:"user"`
+	CreatedAt   string     `json:"created_at"`
+	SubmittedAt string     `json:"submitted_at"`
+	State       string     `json:"state"`
+}
+
+type githubIssue struct {
+	Number      int             `json:"number"`
+	Title       string          `json:"title"`
+	PullRequest json.RawMessage `json:"pull_request"` // set when the issue is a PR
+}
+
+type githubPayload struct {
+	Action      string         `json:"action"`
+	Comment     *githubComment `json:"comment"`
+	Review      *githubComment `json:"review"`
+	Issue       *githubIssue   `json:"issue"`
+	PullRequest *githubIssue   `json:"pull_request"`
+	Repository  struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// GitHub reads issue and PR comments and PR reviews from GitHub webhooks
+// (issue_comment, pull_request_review_comment and pull_request_review events).
+func GitHub(header http.Header, body []byte) ([]Item, error) {
+	event := header.Get("X-GitHub-Event")
+	if event == "ping" {
+		return nil, Ignored("ping")
+	}
+	var p githubPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid GitHub payload: %w", err)
+	}
+
+	var (
+		comment *githubComment
+		issue   *githubIssue
+		verb    = "commented on"
+		kind    = "comment"
+	)
+	switch event {
+	case "issue_comment":
+		if p.Action != "created" {
+			return nil, Ignored("issue_comment " + p.Action)
+		}
+		comment, issue = p.Comment, p.Issue
+	case "pull_request_review_comment":
+		if p.Action != "created" {
+			return nil, Ignored("pull_request_review_comment " + p.Action)
+		}
+		comment, issue = p.Comment, p.PullRequest
+	case "pull_request_review":
+		if p.Action != "submitted" {
+			return nil, Ignored("pull_request_review " + p.Action)
+		}
+		comment, issue = p.Review, p.PullRequest
+		kind = "review"
+		verb = "reviewed"
+		if state := p.Review.reviewState(); state != "" {
+			verb = "reviewed (" + state + ")"
+		}
+	default:
+		return nil, Ignored(fmt.Sprintf("GitHub event %q", event))
+	}
+	if comment == nil || issue == nil {
+		return nil, fmt.Errorf("GitHub %s payload has no %s", event, kind)
+	}
+
+	date := comment.CreatedAt
+	if date == "" {
+		date = comment.SubmittedAt
+	}
+	return []Item{{
+		Summary:        fmt.Sprintf("@%s %s %s#%d: %s", comment.User.Login, verb, p.Repository.FullName, issue.Number, issue.Title),
+		Date:           date,
+		Source:         "github",
+		URL:            comment.HTMLURL,
+		Channel:        p.Repository.FullName,
+		Sender:         comment.User.Login,
+		IdempotencyKey: fmt.Sprintf("github:%s:%d", kind, comment.ID),
+		FullMessage:    comment.Body,
+	}}, nil
+}
+
+// reviewState returns a review's state in words ("changes requested"), or "" for a
+// review that only comments.
+func (c *githubComment) reviewState() string {
+	if c == nil || strings.EqualFold(c.State, "commented") {
+		return ""
+	}
+	return strings.ReplaceAll(strings.ToLower(c.State), "_", " ")
+}
+
+// VerifyGitHub checks the X-Hub-Signature-256 header GitHub signs payloads with when
+// the webhook has a secret.
+func VerifyGitHub(secret string, header http.Header, body []byte) error {
+	sig, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return fmt.Errorf("missing X-Hub-Signature-256 header")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("invalid X-Hub-Signature-256 header")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package ingest turns webhook payloads into todos: a generic JSON schema, and adapters
+// for GitHub comments, Slack messages and email-to-JSON services.
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// maxSummary is the longest summary adapters derive from a message, in runes.
+const maxSummary = 100
+
+// Item is a todo to create, in the generic JSON schema.
+type Item struct {
+	Summary        string `json:"summary"`
+	Date           string `json:"date,omitempty"` // YYYY-MM-DD or RFC 3339
+	Source         string `json:"source,omitempty"`
+	URL            string `json:"url,omitempty"`
+	Channel        string `json:"channel,omitempty"`
+	Sender         string `json:"sender,omitempty"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	FullMessage    string `json:"full_message,omitempty"`
+}
+
+// Todo returns the todo to create for the item.
+func (it *Item) Todo() (*db.Todo, error) {
+	if strings.TrimSpace(it.Summary) == "" {
+		return nil, fmt.Errorf("summary is required")
+	}
+	t := &db.Todo{
+		ID:      uuid.New().String()[:8],
+		Status:  db.TodoStatusTodo,
+		Summary: it.Summary,
+	}
+	if it.Date != "" {
+		date, err := parseDate(it.Date)
+		if err != nil {
+			return nil, err
+		}
+		t.Date = &date
+	}
+	t.Source = optional(it.Source)
+	t.URL = optional(it.URL)
+	t.Channel = optional(it.Channel)
+	t.Sender = optional(it.Sender)
+	t.IdempotencyKey = optional(it.IdempotencyKey)
+	t.FullMessage = optional(it.FullMessage)
+	return t, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, time.RFC1123Z, time.RFC1123} {
+		if date, err := time.Parse(layout, s); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC 3339", s)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Adapter turns a webhook request into the items it carries.
+type Adapter func(header http.Header, body []byte) ([]Item, error)
+
+// Ignored is returned by adapters for payloads that carry nothing to do, such as a
+// GitHub ping or an edited comment.
+type Ignored string
+
+func (e Ignored) Error() string { return "ignored: " + string(e) }
+
+// Generic reads items in the generic schema: one object, or an array of them.
+func Generic(_ http.Header, body []byte) ([]Item, error) {
+	var items []Item
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, fmt.Errorf("invalid todos: %w", err)
+		}
+		return items, nil
+	}
+	var item Item
+	if err := json.Unmarshal(body, &item); err != nil {
+		return nil, fmt.Errorf("invalid todo: %w", err)
+	}
+	return []Item{item}, nil
+}
+
+// summarize returns the first line of text, shortened to maxSummary runes.
+func summarize(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) <= maxSummary {
+		return line
+	}
+	runes := []rune(line)
+	return strings.TrimSpace(string(runes[:maxSummary-1])) + "…"
+}
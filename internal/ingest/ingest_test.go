@@ -0,0 +1,315 @@
+package ingest
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestAdapters(t *testing.T) {
+	tests := []struct {
+		name    string
+		adapt   Adapter
+		event   string // X-GitHub-Event
+		fixture string
+		want    []Item
+	}{
+		{
+			name:    "github issue comment",
+			adapt:   GitHub,
+			event:   "issue_comment",
+			fixture: "github_issue_comment.json",
+			want: []Item{{
+				Summary:        "@octocat commented on acme/widgets#42: Crash when the plan file is missing",
+				Date:           "2024-05-01T10:00:00Z",
+				Source:         "github",
+				URL:            "https://github.com/acme/widgets/issues/42#issuecomment-1001",
+				Channel:        "acme/widgets",
+				Sender:         "octocat",
+				IdempotencyKey: "github:comment:1001",
+				FullMessage:    "Still happening on main, can you take a look?",
+			}},
+		},
+		{
+			name:    "github PR review comment",
+			adapt:   GitHub,
+			event:   "pull_request_review_comment",
+			fixture: "github_pr_review_comment.json",
+			want: []Item{{
+				Summary:        "@hubot commented on acme/widgets#7: Add retry to the fetcher",
+				Date:           "2024-05-02T09:30:00Z",
+				Source:         "github",
+				URL:            "https://github.com/acme/widgets/pull/7#discussion_r2002",
+				Channel:        "acme/widgets",
+				Sender:         "hubot",
+				IdempotencyKey: "github:comment:2002",
+				FullMessage:    "This loop never backs off.",
+			}},
+		},
+		{
+			name:    "github PR review",
+			adapt:   GitHub,
+			event:   "pull_request_review",
+			fixture: "github_pr_review.json",
+			want: []Item{{
+				Summary:        "@hubot reviewed (changes requested) acme/widgets#7: Add retry to the fetcher",
+				Date:           "2024-05-02T10:00:00Z",
+				Source:         "github",
+				URL:            "https://github.com/acme/widgets/pull/7#pullrequestreview-3003",
+				Channel:        "acme/widgets",
+				Sender:         "hubot",
+				IdempotencyKey: "github:review:3003",
+				FullMessage:    "A couple of things to fix before merging.",
+			}},
+		},
+		{
+			name:    "slack event",
+			adapt:   Slack,
+			fixture: "slack_event.json",
+			want: []Item{{
+				Summary:        "@U0BOT please review PR 7 & the docs",
+				Date:           "2024-05-01T10:00:00Z",
+				Source:         "slack",
+				Channel:        "C0001",
+				Sender:         "U0001",
+				IdempotencyKey: "slack:T0001:C0001:1714557600.000200",
+				FullMessage:    "@U0BOT please review PR 7 & the docs\nno rush",
+			}},
+		},
+		{
+			name:    "slack simple message",
+			adapt:   Slack,
+			fixture: "slack_simple.json",
+			want: []Item{{
+				Summary:        "Deploy is stuck on staging",
+				Date:           "2024-05-01T10:01:00Z",
+				Source:         "slack",
+				URL:            "https://acme.slack.com/archives/C0002/p1714557660000100",
+				Channel:        "ops",
+				Sender:         "alice",
+				IdempotencyKey: "slack:ops:1714557660.000100",
+				FullMessage:    "Deploy is stuck on staging",
+			}},
+		},
+		{
+			name:    "email postmark",
+			adapt:   Email,
+			fixture: "email_postmark.json",
+			want: []Item{{
+				Summary:        "Renew the TLS certificate",
+				Date:           "Wed, 01 May 2024 10:00:00 +0000",
+				Source:         "email",
+				Channel:        "todo@example.com",
+				Sender:         "bob@example.com",
+				IdempotencyKey: "email:a8c1040e-1c7b-4b1c-8c50-3a1c2d0f0e11",
+				FullMessage:    "It expires on Friday.\n\nBob",
+			}},
+		},
+		{
+			name:    "email mailgun",
+			adapt:   Email,
+			fixture: "email_mailgun.json",
+			want: []Item{{
+				Summary:        "Can you send me the Q2 numbers?",
+				Date:           "2024-05-01T10:00:00Z",
+				Source:         "email",
+				Channel:        "todo@example.com",
+				Sender:         "carol@example.com",
+				IdempotencyKey: "email:20240501100000.1234@mail.example.com",
+				FullMessage:    "Can you send me the Q2 numbers?\nThanks",
+			}},
+		},
+		{
+			name:    "generic array",
+			adapt:   Generic,
+			fixture: "generic_array.json",
+			want: []Item{
+				{Summary: "Write release notes", Date: "2024-05-03", Source: "cron"},
+				{Summary: "Reply to Dana", Sender: "dana", IdempotencyKey: "inbox:17", FullMessage: "Are we still on for Thursday?"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.event != "" {
+				header.Set("X-GitHub-Event", tt.event)
+			}
+			got, err := tt.adapt(header, fixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("adapt: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("items =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			for _, it := range got {
+				if _, err := it.Todo(); err != nil {
+					t.Errorf("Todo(): %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAdaptersIgnore(t *testing.T) {
+	tests := []struct {
+		name  string
+		adapt Adapter
+		event string
+		body  string
+	}{
+		{name: "github ping", adapt: GitHub, event: "ping", body: `{"zen":"hi"}`},
+		{name: "github other event", adapt: GitHub, event: "push", body: `{}`},
+		{name: "github edited comment", adapt: GitHub, event: "issue_comment", body: `{"action":"edited"}`},
+		{name: "github dismissed review", adapt: GitHub, event: "pull_request_review", body: `{"action":"dismissed"}`},
+		{name: "slack bot message", adapt: Slack, body: `{"type":"event_callback","event":{"type":"message","bot_id":"B1","text":"hi","ts":"1"}}`},
+		{name: "slack edit", adapt: Slack, body: `{"type":"event_callback","event":{"type":"message","subtype":"message_changed","ts":"1"}}`},
+		{name: "slack other event", adapt: Slack, body: `{"type":"event_callback","event":{"type":"reaction_added"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("X-GitHub-Event", tt.event)
+			_, err := tt.adapt(header, []byte(tt.body))
+			var ignored Ignored
+			if !errors.As(err, &ignored) {
+				t.Errorf("err = %v, want Ignored", err)
+			}
+		})
+	}
+}
+
+func TestAdaptersInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		adapt Adapter
+		body  string
+	}{
+		{name: "generic not json", adapt: Generic, body: `nope`},
+		{name: "generic wrong type", adapt: Generic, body: `{"summary": 3}`},
+		{name: "slack no text", adapt: Slack, body: `{"channel":"ops","ts":"1"}`},
+		{name: "email no sender", adapt: Email, body: `{"subject":"hi"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.adapt(http.Header{}, []byte(tt.body)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestSlackChallenge(t *testing.T) {
+	_, err := Slack(nil, []byte(`{"type":"url_verification","challenge":"abc123"}`))
+	var challenge SlackChallenge
+	if !errors.As(err, &challenge) || challenge != "abc123" {
+		t.Errorf("err = %v, want challenge abc123", err)
+	}
+}
+
+func TestItemTodo(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     Item
+		wantErr  string
+		wantDate string
+	}{
+		{name: "minimal", item: Item{Summary: "Do it"}},
+		{name: "day", item: Item{Summary: "Do it", Date: "2024-05-03"}, wantDate: "2024-05-03"},
+		{name: "rfc3339", item: Item{Summary: "Do it", Date: "2024-05-03T22:00:00Z"}, wantDate: "2024-05-03"},
+		{name: "rfc1123", item: Item{Summary: "Do it", Date: "Fri, 03 May 2024 10:00:00 +0000"}, wantDate: "2024-05-03"},
+		{name: "no summary", item: Item{Summary: "  "}, wantErr: "summary is required"},
+		{name: "bad date", item: Item{Summary: "Do it", Date: "tomorrow"}, wantErr: "invalid date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo, err := tt.item.Todo()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Todo(): %v", err)
+			}
+			if todo.Summary != tt.item.Summary || todo.ID == "" {
+				t.Errorf("todo = %+v", todo)
+			}
+			if tt.wantDate == "" {
+				if todo.Date != nil {
+					t.Errorf("date = %v, want none", todo.Date)
+				}
+			} else if todo.Date == nil || todo.Date.Format("2006-01-02") != tt.wantDate {
+				t.Errorf("date = %v, want %s", todo.Date, tt.wantDate)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	long := strings.Repeat("word ", 30)
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "  first line\nsecond", want: "first line"},
+		{in: "", want: ""},
+		{in: long, want: strings.TrimSpace(long[:maxSummary-1]) + "…"},
+	}
+
+	for _, tt := range tests {
+		if got := summarize(tt.in); got != tt.want {
+			t.Errorf("summarize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVerifySlack(t *testing.T) {
+	now := time.Unix(1714557600, 0)
+	body := []byte(`{"text":"hi"}`)
+	header := func(ts, sig string) http.Header {
+		h := http.Header{}
+		h.Set("X-Slack-Request-Timestamp", ts)
+		h.Set("X-Slack-Signature", sig)
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr bool
+	}{
+		{name: "valid", header: header("1714557600", slackSignature("shh", "1714557600", body))},
+		{name: "wrong secret", header: header("1714557600", slackSignature("nope", "1714557600", body)), wantErr: true},
+		{name: "stale", header: header("1714550000", slackSignature("shh", "1714550000", body)), wantErr: true},
+		{name: "missing", header: http.Header{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySlack("shh", tt.header, body, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package ingest
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// DefaultListen is the address cmt todo serve listens on by default.
+const DefaultListen = "127.0.0.1:7778"
+
+// maxBody is the largest payload accepted, in bytes.
+const maxBody = 1 << 20
+
+// Result lists the IDs of the todos a request created, and of those that already
+// existed with the same idempotency key.
+type Result struct {
+	Created  []string `json:"created"`
+	Existing []string `json:"existing"`
+}
+
+// Server accepts todos over HTTP: the generic schema on POST /todos, and webhook
+// payloads on POST /webhooks/{github,slack,email}. Every request must carry the token,
+// as "Authorization: Bearer <token>" or, for webhook senders that cannot set headers,
+// a token query parameter.
+type Server struct {
+	db    *db.DB
+	token string
+	mux   *http.ServeMux
+
+	// GitHubSecret, when set, is the secret GitHub webhook payloads must be signed with.
+	GitHubSecret string
+	// SlackSecret, when set, is the signing secret Slack requests must be signed with.
+	SlackSecret string
+}
+
+// New returns a server adding todos to database, accepting token.
+func New(database *db.DB, token string) *Server {
+	s := &Server{db: database, token: token, mux: http.NewServeMux()}
+	s.mux.Handle("POST /todos", s.handle(Generic, nil))
+	s.mux.Handle("POST /webhooks/github", s.handle(GitHub, func(h http.Header, body []byte) error {
+		if s.GitHubSecret == "" {
+			return nil
+		}
+		return VerifyGitHub(s.GitHubSecret, h, body)
+	}))
+	s.mux.Handle("POST /webhooks/slack", s.handle(Slack, func(h http.Header, body []byte) error {
+		if s.SlackSecret == "" {
+			return nil
+		}
+		return VerifySlack(s.SlackSecret, h, body, time.Now())
+	}))
+	s.mux.Handle("POST /webhooks/email", s.handle(Email, nil))
+	return s
+}
+
+// ServeHTTP checks the token and serves the request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		writeError(w, http.StatusUnauthorized, "missing or invalid token")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle returns a handler reading items from requests with adapt, after checking
+// their signature with verify when it is set.
+func (s *Server) handle(adapt Adapter, verify func(http.Header, []byte) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("read body: %v", err))
+			return
+		}
+		if verify != nil {
+			if err := verify(r.Header, body); err != nil {
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+		}
+
+		items, err := adapt(r.Header, body)
+		var ignored Ignored
+		var challenge SlackChallenge
+		switch {
+		case errors.As(err, &challenge):
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, string(challenge)) //nolint:errcheck // the client went away
+			return
+		case errors.As(err, &ignored):
+			writeJSON(w, http.StatusAccepted, map[string]string{"ignored": string(ignored)})
+			return
+		case err != nil:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		result, err := s.add(items)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		status := http.StatusOK
+		if len(result.Created) > 0 {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, result)
+	})
+}
+
+// add creates a todo for each item, skipping those whose idempotency key is already
+// taken. Every item is checked before any is created, and the todos are created in one
+// transaction, so a failed request leaves nothing behind and can simply be retried.
+func (s *Server) add(items []Item) (Result, error) {
+	if len(items) == 0 {
+		return Result{}, fmt.Errorf("no todos in request")
+	}
+	todos := make([]*db.Todo, len(items))
+	ids := make([]string, len(items))
+	for i := range items {
+		t, err := items[i].Todo()
+		if err != nil {
+			if len(items) > 1 {
+				return Result{}, fmt.Errorf("todo %d: %w", i+1, err)
+			}
+			return Result{}, err
+		}
+		todos[i] = t
+		ids[i] = t.ID
+	}
+
+	if err := s.db.CreateTodos(todos); err != nil {
+		return Result{}, err
+	}
+	result := Result{Created: []string{}, Existing: []string{}}
+	for i, t := range todos {
+		if t.ID == ids[i] {
+			result.Created = append(result.Created, t.ID)
+		} else {
+			result.Existing = append(result.Existing, t.ID)
+		}
+	}
+	return result, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) //nolint:errcheck // the client went away
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// TokenPath returns the default token file path: ~/.config/cmt/ingest-token.
+func TokenPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "cmt", "ingest-token"), nil
+}
@@ -0,0 +1,271 @@
+package ingest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+const testToken = "secret"
+
+func testServer(t *testing.T, configure func(*Server)) (*httptest.Server, *db.DB) {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	s := New(database, testToken)
+	if configure != nil {
+		configure(s)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts, database
+}
+
+// post sends body to path with the token and headers, returning the status and body.
+func post(t *testing.T, ts *httptest.Server, path string, header http.Header, body []byte) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func githubSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func slackSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestServerAuth(t *testing.T) {
+	ts, _ := testServer(t, nil)
+	body := `{"summary":"Do it"}`
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   int
+	}{
+		{name: "no token", want: http.StatusUnauthorized},
+		{name: "wrong token", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "bearer token", header: "Bearer " + testToken, want: http.StatusCreated},
+		{name: "query token", query: "?token=" + testToken, want: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/todos"+tt.query, strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerDedupe(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		event   string
+		fixture string
+		count   int
+	}{
+		{name: "generic", path: "/todos", fixture: "generic_array.json", count: 2},
+		{name: "github", path: "/webhooks/github", event: "issue_comment", fixture: "github_issue_comment.json", count: 1},
+		{name: "slack", path: "/webhooks/slack", fixture: "slack_event.json", count: 1},
+		{name: "email", path: "/webhooks/email", fixture: "email_postmark.json", count: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, database := testServer(t, nil)
+			header := http.Header{}
+			if tt.event != "" {
+				header.Set("X-GitHub-Event", tt.event)
+			}
+			body := fixture(t, tt.fixture)
+
+			status, resp := post(t, ts, tt.path, header, body)
+			if status != http.StatusCreated {
+				t.Fatalf("first post: status = %d (%s)", status, resp)
+			}
+			var first Result
+			if err := json.Unmarshal([]byte(resp), &first); err != nil {
+				t.Fatal(err)
+			}
+			if len(first.Created) != tt.count || len(first.Existing) != 0 {
+				t.Errorf("first post: result = %+v", first)
+			}
+
+			status, resp = post(t, ts, tt.path, header, body)
+			var second Result
+			if err := json.Unmarshal([]byte(resp), &second); err != nil {
+				t.Fatal(err)
+			}
+			// Items without an idempotency key are created again
+			keyed := 0
+			todos, err := database.ListTodos("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, todo := range todos {
+				if todo.IdempotencyKey != nil {
+					keyed++
+				}
+			}
+			if len(second.Existing) != keyed || len(second.Created) != tt.count-keyed {
+				t.Errorf("second post: result = %+v, want %d existing", second, keyed)
+			}
+			if keyed == tt.count && status != http.StatusOK {
+				t.Errorf("second post: status = %d, want %d", status, http.StatusOK)
+			}
+			if want := 2*tt.count - keyed; len(todos) != want {
+				t.Errorf("todos = %d, want %d", len(todos), want)
+			}
+		})
+	}
+}
+
+func TestServerResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string // default POST
+		path   string
+		event  string
+		body   string
+		want   int
+		substr string
+	}{
+		{name: "github ping", path: "/webhooks/github", event: "ping", body: `{}`, want: http.StatusAccepted, substr: `"ignored":"ping"`},
+		{name: "slack challenge", path: "/webhooks/slack", body: `{"type":"url_verification","challenge":"abc123"}`, want: http.StatusOK, substr: "abc123"},
+		{name: "no summary", path: "/todos", body: `{"source":"cron"}`, want: http.StatusBadRequest, substr: "summary is required"},
+		{name: "bad item in array", path: "/todos", body: `[{"summary":"ok"},{"summary":"bad","date":"soon"}]`, want: http.StatusBadRequest, substr: "todo 2"},
+		{name: "empty array", path: "/todos", body: `[]`, want: http.StatusBadRequest, substr: "no todos"},
+		{name: "not json", path: "/webhooks/email", body: `nope`, want: http.StatusBadRequest, substr: "invalid email payload"},
+		{name: "wrong method", method: http.MethodGet, path: "/todos", want: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, database := testServer(t, nil)
+			var status int
+			var resp string
+			if tt.method == http.MethodGet {
+				r, err := http.Get(ts.URL + tt.path + "?token=" + testToken)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r.Body.Close()
+				status = r.StatusCode
+			} else {
+				header := http.Header{}
+				header.Set("X-GitHub-Event", tt.event)
+				status, resp = post(t, ts, tt.path, header, []byte(tt.body))
+			}
+			if status != tt.want {
+				t.Errorf("status = %d, want %d (%s)", status, tt.want, resp)
+			}
+			if !strings.Contains(resp, tt.substr) {
+				t.Errorf("response = %q, want %q", resp, tt.substr)
+			}
+			if tt.want != http.StatusCreated {
+				todos, err := database.ListTodos("")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(todos) != 0 {
+					t.Errorf("created %d todos, want none", len(todos))
+				}
+			}
+		})
+	}
+}
+
+func TestServerSignatures(t *testing.T) {
+	ts, _ := testServer(t, func(s *Server) {
+		s.GitHubSecret = "gh-secret"
+		s.SlackSecret = "slack-secret"
+	})
+	github := fixture(t, "github_issue_comment.json")
+	slack := fixture(t, "slack_simple.json")
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   []byte
+		header map[string]string
+		want   int
+	}{
+		{name: "github unsigned", path: "/webhooks/github", body: github, header: map[string]string{"X-GitHub-Event": "issue_comment"}, want: http.StatusUnauthorized},
+		{name: "github wrong secret", path: "/webhooks/github", body: github, header: map[string]string{
+			"X-GitHub-Event": "issue_comment", "X-Hub-Signature-256": githubSignature("nope", github),
+		}, want: http.StatusUnauthorized},
+		{name: "github signed", path: "/webhooks/github", body: github, header: map[string]string{
+			"X-GitHub-Event": "issue_comment", "X-Hub-Signature-256": githubSignature("gh-secret", github),
+		}, want: http.StatusCreated},
+		{name: "slack unsigned", path: "/webhooks/slack", body: slack, want: http.StatusUnauthorized},
+		{name: "slack signed", path: "/webhooks/slack", body: slack, header: map[string]string{
+			"X-Slack-Request-Timestamp": now, "X-Slack-Signature": slackSignature("slack-secret", now, slack),
+		}, want: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+			if status, resp := post(t, ts, tt.path, header, tt.body); status != tt.want {
+				t.Errorf("status = %d, want %d (%s)", status, tt.want, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package ingest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSlackSkew is how old a signed Slack request may be, against replays.
+const maxSlackSkew = 5 * time.Minute
+
+// SlackChallenge is returned for the url_verification request Slack sends when an
+// events URL is set up; it is answered with the challenge.
+type SlackChallenge string
+
+func (c SlackChallenge) Error() string { return "Slack URL verification" }
+
+type slackMessage struct {
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype"`
+	BotID     string `json:"bot_id"`
+	Channel   string `json:"channel"`
+	User      string `json:"user"`
+	Username  string `json:"username"`
+	Text      string `json:"text"`
+	TS        string `json:"ts"`
+	Permalink string `json:"permalink"`
+}
+
+type slackPayload struct {
+	Type      string        `json:"type"`
+	Challenge string        `json:"challenge"`
+	TeamID    string        `json:"team_id"`
+	Event     *slackMessage `json:"event"`
+	slackMessage
+}
+
+// slackLinkRe matches Slack's markup for links and mentions: <url|label>, <url>, <@U123>
+// and <#C123|name>.
+var slackLinkRe = regexp.MustCompile(`<([@#!]?)([^>|]+)(?:\|([^>]+))?>`)
+
+// Slack reads messages from Slack Events API callbacks (message and app_mention
+// events), and Slack-style messages with text, channel, user and ts fields.
+func Slack(_ http.Header, body []byte) ([]Item, error) {
+	var p slackPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid Slack payload: %w", err)
+	}
+
+	msg := &p.slackMessage
+	switch p.Type {
+	case "url_verification":
+		return nil, SlackChallenge(p.Challenge)
+	case "event_callback":
+		msg = p.Event
+		if msg == nil || (msg.Type != "message" && msg.Type != "app_mention") {
+			return nil, Ignored("Slack event is not a message")
+		}
+	}
+	switch {
+	case msg.Subtype != "" || msg.BotID != "":
+		return nil, Ignored("Slack message is an edit, bot message or other subtype")
+	case strings.TrimSpace(msg.Text) == "":
+		return nil, fmt.Errorf("Slack message has no text")
+	}
+
+	text := slackText(msg.Text)
+	item := Item{
+		Summary:     summarize(text),
+		Source:      "slack",
+		URL:         msg.Permalink,
+		Channel:     msg.Channel,
+		Sender:      msg.User,
+		FullMessage: text,
+	}
+	if item.Sender == "" {
+		item.Sender = msg.Username
+	}
+	if msg.TS != "" {
+		item.IdempotencyKey = "slack:" + strings.Trim(strings.Join([]string{p.TeamID, msg.Channel, msg.TS}, ":"), ":")
+		if ts, err := strconv.ParseFloat(msg.TS, 64); err == nil {
+			sec, frac := math.Modf(ts)
+			item.Date = time.Unix(int64(sec), int64(frac*1e9)).UTC().Format(time.RFC3339)
+		}
+	}
+	return []Item{item}, nil
+}
+
+// slackText turns Slack's link and mention markup into plain text.
+func slackText(text string) string {
+	text = slackLinkRe.ReplaceAllStringFunc(text, func(m string) string {
+		parts := slackLinkRe.FindStringSubmatch(m)
+		prefix, target, label := parts[1], parts[2], parts[3]
+		switch {
+		case prefix == "#" && label != "":
+			return "#" + label
+		case label != "":
+			return label
+		case prefix == "@" || prefix == "#":
+			return prefix + target
+		default:
+			return target
+		}
+	})
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(text)
+}
+
+// VerifySlack checks the X-Slack-Signature header Slack signs requests with, using
+// the app's signing secret.
+func VerifySlack(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing X-Slack-Request-Timestamp header")
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > maxSlackSkew || skew < -maxSlackSkew {
+		return fmt.Errorf("request timestamp is too old")
+	}
+	sig, ok := strings.CutPrefix(header.Get("X-Slack-Signature"), "v0=")
+	if !ok {
+		return fmt.Errorf("missing X-Slack-Signature header")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("invalid X-Slack-Signature header")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Package mcp serves tools to agents as a Model Context Protocol server over stdio:
+// newline-delimited JSON-RPC 2.0 messages on stdin, answered on stdout.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// protocolVersions are the MCP revisions the server speaks, newest first.
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Tool is a tool agents can call.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]any // JSON Schema of the arguments
+	// Call runs the tool. Its result and errors are both shown to the agent.
+	Call func(args json.RawMessage) (string, error)
+}
+
+// Server is an MCP server offering tools.
+type Server struct {
+	Name         string
+	Version      string
+	Instructions string // how to use the server, for the agent
+	Tools        []Tool
+
+	out io.Writer
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// textContent is a tool result's text.
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// Serve answers the messages read from in on out until in ends.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			s.handle(line)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read message: %w", err)
+		}
+	}
+}
+
+// handle answers one message. Notifications, which have no ID, get no answer.
+func (s *Server) handle(line []byte) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		if len(bytes.TrimSpace(line)) > 0 {
+			s.reply(response{ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+		}
+		return
+	}
+	if len(req.ID) == 0 {
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		s.reply(response{ID: req.ID, Error: &rpcError{Code: codeInvalidRequest, Message: "not a JSON-RPC 2.0 request"}})
+		return
+	}
+
+	result, rpcErr := s.dispatch(req)
+	s.reply(response{ID: req.ID, Result: result, Error: rpcErr})
+}
+
+func (s *Server) dispatch(req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params) //nolint:errcheck // an unknown version gets the newest
+		version := protocolVersions[0]
+		if slices.Contains(protocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+			"instructions":    s.Instructions,
+		}, nil
+
+	case "ping":
+		return struct{}{}, nil
+
+	case "tools/list":
+		tools := make([]map[string]any, len(s.Tools))
+		for i, tool := range s.Tools {
+			tools[i] = map[string]any{"name": tool.Name, "description": tool.Description, "inputSchema": tool.InputSchema}
+		}
+		return map[string]any{"tools": tools}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		i := slices.IndexFunc(s.Tools, func(t Tool) bool { return t.Name == params.Name })
+		if i < 0 {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+		}
+		if len(params.Arguments) == 0 || string(params.Arguments) == "null" {
+			params.Arguments = json.RawMessage("{}")
+		}
+		text, err := s.Tools[i].Call(params.Arguments)
+		if err != nil {
+			return toolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		return toolResult{Content: []textContent{{Type: "text", Text: text}}}, nil
+
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
+
+func (s *Server) reply(resp response) {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{Code: codeInvalidRequest, Message: err.Error()}})
+	}
+	s.out.Write(append(data, '\n')) //nolint:errcheck // the client went away
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// roundTrip serves the messages and returns the responses.
+func roundTrip(t *testing.T, s *Server, messages ...string) []map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(strings.NewReader(strings.Join(messages, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	var responses []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]any
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServer(t *testing.T) {
+	s := &Server{Name: "cmt", Version: "test", Tools: []Tool{
+		{
+			Name:        "echo",
+			InputSchema: object([]string{"text"}, map[string]any{"text": str("Text")}),
+			Call: func(args json.RawMessage) (string, error) {
+				var in struct{ Text string }
+				json.Unmarshal(args, &in)
+				if in.Text == "" {
+					return "", errors.New("nothing to echo")
+				}
+				return in.Text, nil
+			},
+		},
+	}}
+
+	responses := roundTrip(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"nope"}}`,
+		`{"jsonrpc":"2.0","id":"six","method":"resources/list"}`,
+		`not json`,
+	)
+	if len(responses) != 7 {
+		t.Fatalf("got %d responses, want 7 (none for the notification): %v", len(responses), responses)
+	}
+
+	init := responses[0]["result"].(map[string]any)
+	if init["protocolVersion"] != "2025-03-26" || init["serverInfo"].(map[string]any)["name"] != "cmt" {
+		t.Errorf("initialize = %v, want the client's protocol version and cmt", init)
+	}
+	tools := responses[1]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "echo" || tools[0].(map[string]any)["inputSchema"] == nil {
+		t.Errorf("tools/list = %v, want echo with its schema", tools)
+	}
+
+	tests := []struct {
+		name    string
+		resp    map[string]any
+		text    string
+		isError bool
+	}{
+		{name: "call", resp: responses[2], text: "hi"},
+		{name: "call failing", resp: responses[3], text: "nothing to echo", isError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.resp["result"].(map[string]any)
+			content := result["content"].([]any)[0].(map[string]any)
+			if content["text"] != tt.text || (result["isError"] == true) != tt.isError {
+				t.Errorf("result = %v, want text %q, isError %v", result, tt.text, tt.isError)
+			}
+		})
+	}
+
+	for i, code := range map[int]float64{4: codeInvalidParams, 5: codeMethodNotFound, 6: codeParseError} {
+		rpcErr, ok := responses[i]["error"].(map[string]any)
+		if !ok || rpcErr["code"] != code {
+			t.Errorf("response %d = %v, want error %v", i, responses[i], code)
+		}
+	}
+	if responses[5]["id"] != "six" {
+		t.Errorf("response id = %v, want the request's", responses[5]["id"])
+	}
+}
+
+func TestInitializeUnknownVersion(t *testing.T) {
+	responses := roundTrip(t, &Server{Name: "cmt"}, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+	if v := responses[0]["result"].(map[string]any)["protocolVersion"]; v != protocolVersions[0] {
+		t.Errorf("protocolVersion = %v, want the newest, %s", v, protocolVersions[0])
+	}
+}
@@ -0,0 +1,534 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/agentic-camerata/cmt/internal/api"
+	"github.com/agentic-camerata/cmt/internal/catalog"
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/plans"
+	"github.com/agentic-camerata/cmt/internal/vt"
+)
+
+const (
+	// defaultTranscriptBytes is how much of the end of a session's output log
+	// read_session_transcript reads by default.
+	defaultTranscriptBytes = 64 * 1024
+	// defaultSessionLimit is how many sessions list_sessions returns by default.
+	defaultSessionLimit = 20
+)
+
+// Tools are the tools cmt gives agents.
+type Tools struct {
+	DB *db.DB
+	// WorkDir is the directory relative paths, plans and subsessions are resolved against.
+	WorkDir string
+	// SessionID is the cmt session of the agent asking, if it runs in one.
+	SessionID string
+	// Launch starts subsessions.
+	Launch api.Launcher
+	// AllowAutonomous lets spawn_subsession start sessions that skip permission prompts.
+	// Without it an agent could grant its subsessions more than the user granted it.
+	AllowAutonomous bool
+}
+
+// Server returns an MCP server offering the tools.
+func (t *Tools) Server(version string) *Server {
+	instructions := "cmt tracks coding agent sessions, todos and a shared catalog of research " +
+		"files. Use these tools to check on other sessions, delegate work to a subsession, " +
+		"consult or add to the catalog, and file todos for the user."
+	if t.SessionID != "" {
+		instructions += fmt.Sprintf(" You are running in cmt session %s.", t.SessionID)
+	}
+	return &Server{Name: "cmt", Version: version, Instructions: instructions, Tools: t.List()}
+}
+
+// List returns the tools.
+func (t *Tools) List() []Tool {
+	return []Tool{
+		{
+			Name:        "list_sessions",
+			Description: "List cmt sessions, newest first: ID, workflow, status, directory, task and tmux location.",
+			InputSchema: object(nil, map[string]any{
+				"status":    enum("Only sessions with this status; running means waiting or working", "running", "waiting", "working", "completed", "abandoned", "killed"),
+				"directory": str("Only sessions run in this directory"),
+				"children":  boolean("Only the subsessions this session started"),
+				"limit":     integer(fmt.Sprintf("Most sessions to return (default %d)", defaultSessionLimit)),
+			}),
+			Call: t.listSessions,
+		},
+		{
+			Name:        "read_session_transcript",
+			Description: "Read the end of a session's output as plain text, with its status and task. Use it to follow a subsession or see what another session did.",
+			InputSchema: object([]string{"id"}, map[string]any{
+				"id":        str("Session ID"),
+				"max_bytes": integer(fmt.Sprintf("How much of the end of the output log to read (default %d)", defaultTranscriptBytes)),
+			}),
+			Call: t.readSessionTranscript,
+		},
+		{
+			Name:        "add_todo",
+			Description: "File a todo for the user in cmt's todo list, e.g. a follow-up you found but should not do now.",
+			InputSchema: object([]string{"summary"}, map[string]any{
+				"summary":         str("One-line summary"),
+				"full_message":    str("Details"),
+				"url":             str("Related URL"),
+				"date":            str("Due date (YYYY-MM-DD)"),
+				"source":          str("Where it came from (default: cmt)"),
+				"idempotency_key": str("Key that keeps the same todo from being filed twice"),
+			}),
+			Call: t.addTodo,
+		},
+		{
+			Name:        "search_catalog",
+			Description: "Search the shared catalog of research and reference .md files kept across projects. Without a query, lists every entry.",
+			InputSchema: object(nil, map[string]any{
+				"query": str("Words that must all appear in the entry's name or content (case-insensitive)"),
+			}),
+			Call: t.searchCatalog,
+		},
+		{
+			Name:        "read_catalog_entry",
+			Description: "Read a catalog entry's markdown.",
+			InputSchema: object([]string{"name"}, map[string]any{
+				"name": str("Entry name, as listed by search_catalog"),
+			}),
+			Call: t.readCatalogEntry,
+		},
+		{
+			Name:        "save_to_catalog",
+			Description: "Copy a .md file, such as research you wrote, into the shared catalog so other projects' sessions can use it.",
+			InputSchema: object([]string{"path"}, map[string]any{
+				"path":  str("Path of the .md file, absolute or relative to the working directory"),
+				"name":  str("Name to save it under (default: the file's name)"),
+				"force": boolean("Overwrite an entry with the same name"),
+			}),
+			Call: t.saveToCatalog,
+		},
+		{
+			Name: "spawn_subsession",
+			Description: "Start a child cmt session in a new tmux window, e.g. to delegate research. Returns its ID at once; " +
+				"follow it with read_session_transcript and list_sessions with children.",
+			InputSchema: object([]string{"command", "task"}, map[string]any{
+				"command":    enum("Kind of session", "research", "plan", "implement", "review", "new"),
+				"task":       str("What the session should do: the topic, task, plan file or review focus"),
+				"directory":  str("Directory to run it in (default: the working directory)"),
+				"agent":      str("Agent backend (default: cmt's)"),
+				"autonomous": boolean("Skip permission prompts, so it can run without the user"),
+			}),
+			Call: t.spawnSubsession,
+		},
+		{
+			Name:        "list_plans",
+			Description: "List the implementation plans in thoughts/shared/plans, newest first, with their titles.",
+			InputSchema: object(nil, map[string]any{
+				"directory": str("Project directory (default: the working directory)"),
+			}),
+			Call: t.listPlans,
+		},
+	}
+}
+
+func (t *Tools) listSessions(args json.RawMessage) (string, error) {
+	var in struct {
+		Status    string `json:"status"`
+		Directory string `json:"directory"`
+		Children  bool   `json:"children"`
+		Limit     int    `json:"limit"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if in.Children && t.SessionID == "" {
+		return "", fmt.Errorf("children needs a cmt session, and this agent does not run in one")
+	}
+	if in.Limit <= 0 {
+		in.Limit = defaultSessionLimit
+	}
+
+	sessions, err := t.DB.ListSessions("")
+	if err != nil {
+		return "", err
+	}
+	out := []api.Session{}
+	for _, s := range sessions {
+		switch {
+		case in.Status == "running" && s.Status != db.StatusWaiting && s.Status != db.StatusWorking,
+			in.Status != "" && in.Status != "running" && string(s.Status) != in.Status,
+			in.Directory != "" && s.WorkingDirectory != t.abs(in.Directory),
+			in.Children && s.ParentID != t.SessionID:
+			continue
+		}
+		out = append(out, api.NewSession(s))
+		if len(out) == in.Limit {
+			break
+		}
+	}
+	return toJSON(out)
+}
+
+func (t *Tools) readSessionTranscript(args json.RawMessage) (string, error) {
+	var in struct {
+		ID       string `json:"id"`
+		MaxBytes int64  `json:"max_bytes"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if in.MaxBytes <= 0 {
+		in.MaxBytes = defaultTranscriptBytes
+	}
+	s, err := t.DB.GetSession(in.ID)
+	if err != nil {
+		return "", err
+	}
+	if s == nil {
+		return "", fmt.Errorf("session %s not found", in.ID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session %s: %s, %s\nDirectory: %s\n", s.ID, s.WorkflowType, s.Status, s.WorkingDirectory)
+	if s.TaskDescription != "" {
+		fmt.Fprintf(&b, "Task: %s\n", s.TaskDescription)
+	}
+	b.WriteString("\n")
+
+	if s.OutputFile == "" {
+		b.WriteString("(no output recorded)")
+		return b.String(), nil
+	}
+	data, err := os.ReadFile(s.OutputFile)
+	if err != nil {
+		return "", fmt.Errorf("read output: %w", err)
+	}
+	if int64(len(data)) > in.MaxBytes {
+		data = data[int64(len(data))-in.MaxBytes:]
+		// Start at a line, not in the middle of one or of an escape sequence
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+		b.WriteString("[... earlier output omitted]\n")
+	}
+	b.WriteString(vt.Plain(data))
+	return b.String(), nil
+}
+
+func (t *Tools) addTodo(args json.RawMessage) (string, error) {
+	var in struct {
+		Summary        string `json:"summary"`
+		FullMessage    string `json:"full_message"`
+		URL            string `json:"url"`
+		Date           string `json:"date"`
+		Source         string `json:"source"`
+		IdempotencyKey string `json:"idempotency_key"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if in.Summary == "" {
+		return "", fmt.Errorf("summary is required")
+	}
+	if in.Source == "" {
+		in.Source = "cmt"
+	}
+
+	todo := &db.Todo{
+		ID:      uuid.New().String()[:8],
+		Status:  db.TodoStatusTodo,
+		Summary: in.Summary,
+		Source:  &in.Source,
+	}
+	if in.Date != "" {
+		parsed, err := time.Parse("2006-01-02", in.Date)
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q: expected YYYY-MM-DD format", in.Date)
+		}
+		todo.Date = &parsed
+	}
+	if in.FullMessage != "" {
+		todo.FullMessage = &in.FullMessage
+	}
+	if in.URL != "" {
+		todo.URL = &in.URL
+	}
+	if in.IdempotencyKey != "" {
+		todo.IdempotencyKey = &in.IdempotencyKey
+	}
+	if t.SessionID != "" {
+		sender := "session " + t.SessionID
+		todo.Sender = &sender
+	}
+
+	id := todo.ID
+	if err := t.DB.CreateTodo(todo); err != nil {
+		return "", err
+	}
+	if todo.ID != id {
+		return fmt.Sprintf("Todo %s already exists with this idempotency key", todo.ID), nil
+	}
+	return fmt.Sprintf("Added todo %s", todo.ID), nil
+}
+
+func (t *Tools) searchCatalog(args json.RawMessage) (string, error) {
+	var in struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	entries, err := catalog.List()
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ModTime > entries[j].ModTime })
+	words := strings.Fields(strings.ToLower(in.Query))
+
+	var b strings.Builder
+	for _, e := range entries {
+		snippet := ""
+		if len(words) > 0 {
+			path, err := catalog.Path(e.Name)
+			if err != nil {
+				continue
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var ok bool
+			if snippet, ok = match(e.Name, string(content), words); !ok {
+				continue
+			}
+		}
+		fmt.Fprintf(&b, "%s (%d bytes, %s)\n", e.Name, e.Size, time.Unix(0, e.ModTime).Format("2006-01-02"))
+		if snippet != "" {
+			fmt.Fprintf(&b, "  %s\n", snippet)
+		}
+	}
+	if b.Len() == 0 {
+		if len(words) > 0 {
+			return fmt.Sprintf("No catalog entries match %q", in.Query), nil
+		}
+		return "The catalog is empty", nil
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// match reports whether every word appears in the name or content, and returns the
+// first content line with one of them.
+func match(name, content string, words []string) (string, bool) {
+	haystack := strings.ToLower(name + "\n" + content)
+	for _, w := range words {
+		if !strings.Contains(haystack, w) {
+			return "", false
+		}
+	}
+	for _, line := range strings.Split(content, "\n") {
+		lower := strings.ToLower(line)
+		for _, w := range words {
+			if strings.Contains(lower, w) {
+				return strings.TrimSpace(line), true
+			}
+		}
+	}
+	return "", true
+}
+
+func (t *Tools) readCatalogEntry(args json.RawMessage) (string, error) {
+	var in struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	path, err := catalog.Path(in.Name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read catalog entry: %w", err)
+	}
+	return string(data), nil
+}
+
+func (t *Tools) saveToCatalog(args json.RawMessage) (string, error) {
+	var in struct {
+		Path  string `json:"path"`
+		Name  string `json:"name"`
+		Force bool   `json:"force"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if in.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	dest, err := catalog.Save(t.abs(in.Path), in.Name, in.Force)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Saved to the catalog as %s", filepath.Base(dest)), nil
+}
+
+func (t *Tools) spawnSubsession(args json.RawMessage) (string, error) {
+	var in struct {
+		Command    string `json:"command"`
+		Task       string `json:"task"`
+		Directory  string `json:"directory"`
+		Agent      string `json:"agent"`
+		Autonomous bool   `json:"autonomous"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if t.Launch == nil {
+		return "", fmt.Errorf("this server cannot start sessions")
+	}
+	if in.Autonomous && !t.AllowAutonomous {
+		return "", fmt.Errorf("autonomous subsessions are only allowed from an autonomous session or with cmt mcp --allow-autonomous")
+	}
+	req := api.StartRequest{
+		Command:    in.Command,
+		Task:       in.Task,
+		Dir:        t.WorkDir,
+		Agent:      in.Agent,
+		Autonomous: in.Autonomous,
+	}
+	if in.Directory != "" {
+		req.Dir = t.abs(in.Directory)
+	}
+	if err := req.Validate(); err != nil {
+		return "", err
+	}
+	if t.SessionID != "" {
+		if parent, err := t.DB.GetSession(t.SessionID); err == nil && parent != nil {
+			req.ParentID = parent.ID
+		}
+	}
+
+	started, err := t.Launch(req)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Started %s session %s in tmux %s. It records its session once the agent starts; "+
+		"follow it with read_session_transcript.", in.Command, started.ID, started.Tmux), nil
+}
+
+func (t *Tools) listPlans(args json.RawMessage) (string, error) {
+	var in struct {
+		Directory string `json:"directory"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	dir := t.WorkDir
+	if in.Directory != "" {
+		dir = t.abs(in.Directory)
+	}
+	plansDir := filepath.Join(dir, plans.PlansDir)
+
+	type plan struct {
+		path    string
+		title   string
+		modTime time.Time
+	}
+	var found []plan
+	err := filepath.WalkDir(plansDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".md") {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		found = append(found, plan{path: path, title: markdownTitle(path), modTime: info.ModTime()})
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return fmt.Sprintf("No plans in %s", plansDir), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("list plans: %w", err)
+	}
+	if len(found) == 0 {
+		return fmt.Sprintf("No plans in %s", plansDir), nil
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.After(found[j].modTime) })
+
+	var b strings.Builder
+	for _, p := range found {
+		fmt.Fprintf(&b, "%s (%s)", p.path, p.modTime.Format("2006-01-02 15:04"))
+		if p.title != "" {
+			fmt.Fprintf(&b, ": %s", p.title)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// markdownTitle returns the first heading of the markdown file at path.
+func markdownTitle(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// abs resolves path against the working directory.
+func (t *Tools) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(t.WorkDir, path)
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// JSON Schema helpers for tool arguments.
+
+func object(required []string, properties map[string]any) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func str(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+func integer(description string) map[string]any {
+	return map[string]any{"type": "integer", "description": description}
+}
+
+func boolean(description string) map[string]any {
+	return map[string]any{"type": "boolean", "description": description}
+}
+
+func enum(description string, values ...string) map[string]any {
+	return map[string]any{"type": "string", "description": description, "enum": values}
+}
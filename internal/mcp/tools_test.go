@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentic-camerata/cmt/internal/api"
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+func testTools(t *testing.T) *Tools {
+	t.Helper()
+	database, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	t.Setenv("CMT_CATALOG_DIR", t.TempDir())
+	return &Tools{DB: database, WorkDir: t.TempDir(), SessionID: "parent1"}
+}
+
+// callTool calls the named tool with args.
+func callTool(t *testing.T, tools *Tools, name, args string) (string, error) {
+	t.Helper()
+	for _, tool := range tools.List() {
+		if tool.Name == name {
+			return tool.Call(json.RawMessage(args))
+		}
+	}
+	t.Fatalf("no tool %s", name)
+	return "", nil
+}
+
+func TestListSessions(t *testing.T) {
+	tools := testTools(t)
+	for _, s := range []*db.Session{
+		{ID: "parent1", WorkflowType: db.WorkflowGeneral, Status: db.StatusWorking, WorkingDirectory: tools.WorkDir},
+		{ID: "child1", WorkflowType: db.WorkflowResearch, Status: db.StatusCompleted, WorkingDirectory: tools.WorkDir, ParentID: "parent1"},
+		{ID: "other1", WorkflowType: db.WorkflowPlan, Status: db.StatusWaiting, WorkingDirectory: "/elsewhere"},
+	} {
+		tools.DB.CreateSession(s)
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want []string
+	}{
+		{name: "all", args: `{}`, want: []string{"other1", "child1", "parent1"}},
+		{name: "running", args: `{"status":"running"}`, want: []string{"other1", "parent1"}},
+		{name: "completed", args: `{"status":"completed"}`, want: []string{"child1"}},
+		{name: "relative directory", args: `{"directory":"."}`, want: []string{"child1", "parent1"}},
+		{name: "children", args: `{"children":true}`, want: []string{"child1"}},
+		{name: "limit", args: `{"limit":1}`, want: []string{"other1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := callTool(t, tools, "list_sessions", tt.args)
+			if err != nil {
+				t.Fatalf("list_sessions error = %v", err)
+			}
+			var sessions []api.Session
+			json.Unmarshal([]byte(out), &sessions)
+			var got []string
+			for _, s := range sessions {
+				got = append(got, s.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("sessions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadSessionTranscript(t *testing.T) {
+	tools := testTools(t)
+	output := filepath.Join(tools.WorkDir, "s1.log")
+	os.WriteFile(output, []byte("\x1b[1mthinking\x1b[0m\r\n50%\r100%\r\nfound it\r\n"), 0644)
+	tools.DB.CreateSession(&db.Session{ID: "s1", WorkflowType: db.WorkflowResearch, Status: db.StatusCompleted, TaskDescription: "auth flow", OutputFile: output})
+
+	out, err := callTool(t, tools, "read_session_transcript", `{"id":"s1"}`)
+	if err != nil {
+		t.Fatalf("read_session_transcript error = %v", err)
+	}
+	if !strings.Contains(out, "Session s1: research, completed") || !strings.Contains(out, "Task: auth flow") ||
+		!strings.HasSuffix(out, "thinking\n100%\nfound it") {
+		t.Errorf("transcript = %q, want the header and plain output", out)
+	}
+
+	out, _ = callTool(t, tools, "read_session_transcript", `{"id":"s1","max_bytes":12}`)
+	if !strings.HasSuffix(out, "[... earlier output omitted]\nfound it") {
+		t.Errorf("truncated transcript = %q, want only the last whole line", out)
+	}
+	if _, err := callTool(t, tools, "read_session_transcript", `{"id":"missing"}`); err == nil {
+		t.Error("missing session: want an error")
+	}
+}
+
+func TestAddTodo(t *testing.T) {
+	tools := testTools(t)
+
+	out, err := callTool(t, tools, "add_todo", `{"summary":"Fix flaky test","idempotency_key":"flaky-1","date":"2026-11-01"}`)
+	if err != nil || !strings.HasPrefix(out, "Added todo ") {
+		t.Fatalf("add_todo = %q, %v", out, err)
+	}
+	todo, _ := tools.DB.GetTodoByIdempotencyKey("flaky-1")
+	if todo == nil || todo.Summary != "Fix flaky test" || *todo.Source != "cmt" || *todo.Sender != "session parent1" || todo.Date == nil {
+		t.Errorf("todo = %+v, want it filed by session parent1", todo)
+	}
+
+	if out, _ := callTool(t, tools, "add_todo", `{"summary":"Fix flaky test","idempotency_key":"flaky-1"}`); !strings.Contains(out, "already exists") {
+		t.Errorf("duplicate add_todo = %q, want already exists", out)
+	}
+	if _, err := callTool(t, tools, "add_todo", `{"summary":"x","date":"tomorrow"}`); err == nil {
+		t.Error("invalid date: want an error")
+	}
+}
+
+func TestCatalogTools(t *testing.T) {
+	tools := testTools(t)
+	os.WriteFile(filepath.Join(tools.WorkDir, "auth.md"), []byte("# Auth\n\nTokens are refreshed by the gateway.\n"), 0644)
+
+	if out, err := callTool(t, tools, "save_to_catalog", `{"path":"auth.md","name":"auth-research"}`); err != nil || out != "Saved to the catalog as auth-research.md" {
+		t.Fatalf("save_to_catalog = %q, %v", out, err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: "auth-research.md ("},
+		{query: "Gateway tokens", want: "auth-research.md (45 bytes, "},
+		{query: "kubernetes", want: `No catalog entries match "kubernetes"`},
+	}
+	for _, tt := range tests {
+		out, err := callTool(t, tools, "search_catalog", `{"query":"`+tt.query+`"}`)
+		if err != nil || !strings.HasPrefix(out, tt.want) {
+			t.Errorf("search_catalog(%q) = %q, %v, want prefix %q", tt.query, out, err, tt.want)
+		}
+	}
+	if out, _ := callTool(t, tools, "search_catalog", `{"query":"gateway"}`); !strings.HasSuffix(out, "  Tokens are refreshed by the gateway.") {
+		t.Errorf("search_catalog snippet = %q, want the matching line", out)
+	}
+
+	if out, err := callTool(t, tools, "read_catalog_entry", `{"name":"auth-research"}`); err != nil || !strings.HasPrefix(out, "# Auth") {
+		t.Errorf("read_catalog_entry = %q, %v", out, err)
+	}
+}
+
+func TestSpawnSubsession(t *testing.T) {
+	tools := testTools(t)
+	tools.DB.CreateSession(&db.Session{ID: "parent1", WorkflowType: db.WorkflowGeneral, Status: db.StatusWorking})
+	tools.AllowAutonomous = true
+	var launched api.StartRequest
+	tools.Launch = func(req api.StartRequest) (api.Started, error) {
+		launched = req
+		return api.Started{ID: "child1", Tmux: "work:3.0"}, nil
+	}
+
+	out, err := callTool(t, tools, "spawn_subsession", `{"command":"research","task":"how auth works","autonomous":true}`)
+	if err != nil || !strings.HasPrefix(out, "Started research session child1 in tmux work:3.0.") {
+		t.Fatalf("spawn_subsession = %q, %v", out, err)
+	}
+	want := api.StartRequest{Command: "research", Task: "how auth works", Dir: tools.WorkDir, Autonomous: true, ParentID: "parent1"}
+	if launched != want {
+		t.Errorf("launched %+v, want %+v", launched, want)
+	}
+
+	if _, err := callTool(t, tools, "spawn_subsession", `{"command":"deploy","task":"x"}`); err == nil {
+		t.Error("unknown command: want an error")
+	}
+}
+
+func TestSpawnSubsessionRejectsAutonomous(t *testing.T) {
+	tools := testTools(t)
+	launched := false
+	tools.Launch = func(req api.StartRequest) (api.Started, error) {
+		launched = true
+		return api.Started{ID: "child1"}, nil
+	}
+
+	_, err := callTool(t, tools, "spawn_subsession", `{"command":"research","task":"how auth works","autonomous":true}`)
+	if err == nil || !strings.Contains(err.Error(), "autonomous subsessions are only allowed") || launched {
+		t.Fatalf("spawn_subsession error = %v, launched %v, want it refused", err, launched)
+	}
+	if _, err := callTool(t, tools, "spawn_subsession", `{"command":"research","task":"how auth works"}`); err != nil || !launched {
+		t.Errorf("spawn_subsession without autonomy = %v, launched %v", err, launched)
+	}
+}
+
+func TestListPlans(t *testing.T) {
+	tools := testTools(t)
+	if out, _ := callTool(t, tools, "list_plans", `{}`); !strings.HasPrefix(out, "No plans in ") {
+		t.Errorf("list_plans without plans = %q", out)
+	}
+
+	dir := filepath.Join(tools.WorkDir, "thoughts", "shared", "plans")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "auth.md"), []byte("---\ndate: x\n---\n# Refresh tokens\n"), 0644)
+	out, err := callTool(t, tools, "list_plans", `{}`)
+	if err != nil || !strings.HasPrefix(out, filepath.Join(dir, "auth.md")) || !strings.HasSuffix(out, ": Refresh tokens") {
+		t.Errorf("list_plans = %q, %v, want auth.md with its title", out, err)
+	}
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// EnvNotifyConfig overrides the path of the notifications config file.
+const EnvNotifyConfig = "CMT_NOTIFY_CONFIG"
+
+// Terminal notification escapes for Config.Terminal.
+const (
+	TerminalOSC9   = "osc9"   // ESC ] 9 ; message BEL (iTerm2, WezTerm, Windows Terminal, kitty)
+	TerminalOSC777 = "osc777" // ESC ] 777 ; notify ; title ; message BEL (urxvt, foot, Ghostty)
+)
+
+// defaultDebounce is how long a session must wait before a waiting notification is sent.
+// It is longer than the runner's auto-terminate idle time, so play phases that are about
+// to be ended do not notify.
+const defaultDebounce = 10 * time.Second
+
+// Config is the contents of the notifications config file.
+type Config struct {
+	Events   []Event  `json:"events"`   // events to notify (default all)
+	Debounce string   `json:"debounce"` // how long a session must wait before notifying (default "10s")
+	Tmux     bool     `json:"tmux"`     // show a tmux display-message
+	Bell     bool     `json:"bell"`     // ring the terminal bell, which tmux flags on the window
+	Desktop  bool     `json:"desktop"`  // desktop notification via notify-send (osascript on macOS)
+	Terminal string   `json:"terminal"` // terminal notification escape: "osc9" or "osc777"
+	Webhook  *Webhook `json:"webhook"`  // POST the notification as JSON
+}
+
+// Webhook is an HTTP endpoint notifications are posted to.
+type Webhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"` // e.g. {"Authorization": "Bearer ..."}
+}
+
+// Path returns the notifications config file path: $CMT_NOTIFY_CONFIG or
+// ~/.config/cmt/notify.json.
+func Path() (string, error) {
+	if p := os.Getenv(EnvNotifyConfig); p != "" {
+		return p, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "cmt", "notify.json"), nil
+}
+
+// Load reads the notifications config file. A missing file is an empty config, which
+// notifies nothing.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads and validates a notifications config file. A missing file is an empty
+// config.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read notify config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse notify config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("notify config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (c *Config) validate() error {
+	for _, e := range c.Events {
+		if !slices.Contains(Events, e) {
+			return fmt.Errorf("invalid event %q (valid: %s)", e, EventNames())
+		}
+	}
+	if c.Debounce != "" {
+		if d, err := time.ParseDuration(c.Debounce); err != nil || d < 0 {
+			return fmt.Errorf("invalid debounce %q (use a duration such as 10s)", c.Debounce)
+		}
+	}
+	switch c.Terminal {
+	case "", TerminalOSC9, TerminalOSC777:
+	default:
+		return fmt.Errorf("invalid terminal %q (valid: %s, %s)", c.Terminal, TerminalOSC9, TerminalOSC777)
+	}
+	if c.Webhook != nil {
+		u, err := url.Parse(c.Webhook.URL)
+		if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid webhook url %q", c.Webhook.URL)
+		}
+	}
+	return nil
+}
+
+func (c *Config) debounce() time.Duration {
+	if d, err := time.ParseDuration(c.Debounce); err == nil {
+		return d
+	}
+	return defaultDebounce
+}
+
+// sinks returns the sinks the config selects.
+func (c *Config) sinks() []Sink {
+	var sinks []Sink
+	if c.Tmux {
+		sinks = append(sinks, tmuxSink{})
+	}
+	// The bell and escapes are written to stderr, which is the terminal unless redirected
+	if c.Bell && term.IsTerminal(int(os.Stderr.Fd())) {
+		sinks = append(sinks, bellSink{w: os.Stderr})
+	}
+	if c.Desktop {
+		sinks = append(sinks, desktopSink{})
+	}
+	if c.Terminal != "" && term.IsTerminal(int(os.Stderr.Fd())) {
+		sinks = append(sinks, terminalSink{w: os.Stderr, style: c.Terminal, tmux: os.Getenv("TMUX") != ""})
+	}
+	if c.Webhook != nil {
+		sinks = append(sinks, newWebhookSink(c.Webhook))
+	}
+	return sinks
+}
@@ -0,0 +1,254 @@
+// Package notify tells the user when sessions change state: when an agent stops and waits
+// for input, when a session ends, and when a play finishes a phase. Notifications go to
+// the sinks selected in ~/.config/cmt/notify.json.
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+// Event is a session state change that can be notified.
+type Event string
+
+// Events, as named in the config file.
+const (
+	EventWaiting   Event = "waiting"    // the agent stopped working and waits for input
+	EventCompleted Event = "completed"  // the session finished
+	EventAbandoned Event = "abandoned"  // the session failed or was stopped
+	EventBudget    Event = "budget"     // a session ran out of its time budget, such as a play phase's timeout:
+	EventPhaseDone Event = "phase_done" // a play phase finished
+)
+
+// Events lists all events, in the order they are documented.
+var Events = []Event{EventWaiting, EventCompleted, EventAbandoned, EventBudget, EventPhaseDone}
+
+// Notification is what sinks are given. Webhooks receive it as JSON.
+type Notification struct {
+	Event     Event     `json:"event"`
+	SessionID string    `json:"session_id"`
+	Workflow  string    `json:"workflow"`
+	Directory string    `json:"directory"`
+	Task      string    `json:"task,omitempty"`
+	Tmux      string    `json:"tmux,omitempty"`   // session:window.pane of the session
+	Detail    string    `json:"detail,omitempty"` // e.g. the phase that finished
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// ForSession returns the notification of event for session; detail adds to the message.
+func ForSession(session *db.Session, event Event, detail string) Notification {
+	n := Notification{
+		Event:     event,
+		SessionID: session.ID,
+		Workflow:  string(session.WorkflowType),
+		Directory: session.WorkingDirectory,
+		Task:      session.TaskDescription,
+		Detail:    detail,
+		Time:      time.Now(),
+	}
+	if session.HasTmuxLocation() {
+		n.Tmux = fmt.Sprintf("%s:%d.%d", session.TmuxSession, session.TmuxWindow, session.TmuxPane)
+	}
+	n.Message = message(n)
+	return n
+}
+
+func message(n Notification) string {
+	var what string
+	switch n.Event {
+	case EventWaiting:
+		what = "is waiting for input"
+	case EventCompleted:
+		what = "completed"
+	case EventAbandoned:
+		what = "was abandoned"
+	case EventBudget:
+		what = "ran out of time"
+	case EventPhaseDone:
+		what = "finished a phase"
+	default:
+		what = string(n.Event)
+	}
+	msg := fmt.Sprintf("Session %s (%s) %s", n.SessionID, n.Workflow, what)
+	if n.Detail != "" {
+		msg += ": " + n.Detail
+	}
+	return msg
+}
+
+// Sink delivers notifications somewhere.
+type Sink interface {
+	Send(n Notification) error
+}
+
+// queueSize is how many notifications may wait for delivery before more are dropped.
+const queueSize = 64
+
+// Notifier filters notifications by event and hands them to its sinks. Waiting
+// notifications are debounced per session: they are only sent once the session has been
+// waiting for the debounce period. Delivery happens in the background, in order, so a
+// slow webhook never holds up a session. A nil Notifier sends nothing.
+type Notifier struct {
+	events   map[Event]bool
+	debounce time.Duration
+	sinks    []Sink
+
+	mu      sync.Mutex
+	pending map[string]*time.Timer // debounced waiting notifications by session
+
+	start    sync.Once
+	queue    chan Notification
+	inFlight sync.WaitGroup // notifications queued and not yet delivered
+}
+
+// New returns a notifier for cfg, sending to the sinks it selects and to extra.
+func New(cfg *Config, extra ...Sink) *Notifier {
+	n := &Notifier{
+		events:   make(map[Event]bool),
+		debounce: cfg.debounce(),
+		sinks:    append(cfg.sinks(), extra...),
+		pending:  make(map[string]*time.Timer),
+		queue:    make(chan Notification, queueSize),
+	}
+	events := cfg.Events
+	if len(events) == 0 {
+		events = Events
+	}
+	for _, e := range events {
+		n.events[e] = true
+	}
+	return n
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultMu       sync.Mutex
+	defaultNotifier *Notifier
+	defaultErr      error
+)
+
+// Default returns the notifier for the user's config file, loading it once. It is nil
+// when no sinks are configured.
+func Default() (*Notifier, error) {
+	defaultOnce.Do(func() {
+		cfg, err := Load()
+		if err != nil {
+			defaultErr = err
+			return
+		}
+		if n := New(cfg); len(n.sinks) > 0 {
+			defaultMu.Lock()
+			defaultNotifier = n
+			defaultMu.Unlock()
+		}
+	})
+	return defaultNotifier, defaultErr
+}
+
+// WaitDefault waits for the default notifier, if it was loaded, to deliver what it was
+// given, so cmt does not exit before the notifications of the sessions it ran go out.
+func WaitDefault() {
+	defaultMu.Lock()
+	n := defaultNotifier
+	defaultMu.Unlock()
+	n.Wait()
+}
+
+// Notify sends n, or for a waiting notification, schedules it for when the debounce
+// period has passed. Any other notification for the session cancels a scheduled one.
+func (nt *Notifier) Notify(n Notification) {
+	if nt == nil {
+		return
+	}
+	nt.Cancel(n.SessionID)
+	if !nt.events[n.Event] {
+		return
+	}
+	if n.Event != EventWaiting || nt.debounce <= 0 {
+		nt.send(n)
+		return
+	}
+
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	var timer *time.Timer
+	timer = time.AfterFunc(nt.debounce, func() {
+		nt.mu.Lock()
+		current := nt.pending[n.SessionID] == timer
+		if current {
+			delete(nt.pending, n.SessionID)
+		}
+		nt.mu.Unlock()
+		if current {
+			n.Time = time.Now()
+			nt.send(n)
+		}
+	})
+	nt.pending[n.SessionID] = timer
+}
+
+// Cancel drops a scheduled waiting notification of a session, e.g. because it is working
+// again.
+func (nt *Notifier) Cancel(sessionID string) {
+	if nt == nil {
+		return
+	}
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	if timer, ok := nt.pending[sessionID]; ok {
+		timer.Stop()
+		delete(nt.pending, sessionID)
+	}
+}
+
+// Wait waits until every notification sent so far was delivered or failed.
+func (nt *Notifier) Wait() {
+	if nt == nil {
+		return
+	}
+	nt.inFlight.Wait()
+}
+
+// send queues n for delivery without waiting for it. When the queue is full, n is
+// dropped rather than holding up the caller.
+func (nt *Notifier) send(n Notification) {
+	nt.start.Do(func() { go nt.deliver() })
+	nt.inFlight.Add(1)
+	select {
+	case nt.queue <- n:
+	default:
+		nt.inFlight.Done()
+	}
+}
+
+// deliver hands queued notifications to every sink, one notification at a time.
+// Notifications are best effort: a failing sink does not keep the others from being
+// tried, and its error is dropped.
+func (nt *Notifier) deliver() {
+	for n := range nt.queue {
+		var wg sync.WaitGroup
+		for _, sink := range nt.sinks {
+			wg.Add(1)
+			go func(sink Sink) {
+				defer wg.Done()
+				sink.Send(n) //nolint:errcheck
+			}(sink)
+		}
+		wg.Wait()
+		nt.inFlight.Done()
+	}
+}
+
+// EventNames formats Events for help and error messages.
+func EventNames() string {
+	names := make([]string, len(Events))
+	for i, e := range Events {
+		names[i] = string(e)
+	}
+	return strings.Join(names, ", ")
+}
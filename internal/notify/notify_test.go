@@ -0,0 +1,243 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "valid config",
+			content: `{
+				"events": ["waiting", "phase_done"],
+				"debounce": "30s",
+				"tmux": true,
+				"terminal": "osc777",
+				"webhook": {"url": "https://hooks.example.com/cmt", "headers": {"Authorization": "Bearer x"}}
+			}`,
+		},
+		{name: "invalid event", content: `{"events": ["started"]}`, wantErr: "invalid event"},
+		{name: "invalid debounce", content: `{"debounce": "soon"}`, wantErr: "invalid debounce"},
+		{name: "invalid terminal", content: `{"terminal": "osc99"}`, wantErr: "invalid terminal"},
+		{name: "invalid webhook url", content: `{"webhook": {"url": "hooks.example.com"}}`, wantErr: "invalid webhook url"},
+		{name: "invalid json", content: `{"tmux": `, wantErr: "parse notify config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notify.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadFile(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadFile() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg, err := LoadFile(filepath.Join(t.TempDir(), "notify.json"))
+		if err != nil {
+			t.Fatalf("LoadFile() error = %v", err)
+		}
+		if sinks := cfg.sinks(); len(sinks) != 0 {
+			t.Errorf("missing config has %d sinks, want none", len(sinks))
+		}
+	})
+}
+
+// recordSink records the notifications it is sent.
+type recordSink struct {
+	mu   sync.Mutex
+	sent []Notification
+}
+
+func (s *recordSink) Send(n Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, n)
+	return nil
+}
+
+func (s *recordSink) events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []Event
+	for _, n := range s.sent {
+		events = append(events, n.Event)
+	}
+	return events
+}
+
+// blockingSink blocks every send until release is closed.
+type blockingSink struct {
+	release chan struct{}
+}
+
+func (s blockingSink) Send(Notification) error {
+	<-s.release
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	session := &db.Session{ID: "abc", WorkflowType: db.WorkflowResearch}
+
+	t.Run("waiting is debounced", func(t *testing.T) {
+		sink := &recordSink{}
+		n := New(&Config{Debounce: "50ms"}, sink)
+		n.Notify(ForSession(session, EventWaiting, ""))
+		if got := sink.events(); len(got) != 0 {
+			t.Fatalf("sent %v before the debounce period", got)
+		}
+		time.Sleep(150 * time.Millisecond)
+		if got := sink.events(); len(got) != 1 || got[0] != EventWaiting {
+			t.Fatalf("sent %v, want [waiting]", got)
+		}
+	})
+
+	t.Run("working again cancels waiting", func(t *testing.T) {
+		sink := &recordSink{}
+		n := New(&Config{Debounce: "50ms"}, sink)
+		n.Notify(ForSession(session, EventWaiting, ""))
+		n.Cancel(session.ID)
+		time.Sleep(150 * time.Millisecond)
+		if got := sink.events(); len(got) != 0 {
+			t.Fatalf("sent %v, want nothing", got)
+		}
+	})
+
+	t.Run("completion replaces waiting", func(t *testing.T) {
+		sink := &recordSink{}
+		n := New(&Config{Debounce: "50ms"}, sink)
+		n.Notify(ForSession(session, EventWaiting, ""))
+		n.Notify(ForSession(session, EventCompleted, ""))
+		time.Sleep(150 * time.Millisecond)
+		if got := sink.events(); len(got) != 1 || got[0] != EventCompleted {
+			t.Fatalf("sent %v, want [completed]", got)
+		}
+	})
+
+	t.Run("unselected events are dropped", func(t *testing.T) {
+		sink := &recordSink{}
+		n := New(&Config{Events: []Event{EventAbandoned}}, sink)
+		n.Notify(ForSession(session, EventCompleted, ""))
+		n.Notify(ForSession(session, EventAbandoned, "exit status 1"))
+		n.Wait()
+		if got := sink.events(); len(got) != 1 || got[0] != EventAbandoned {
+			t.Fatalf("sent %v, want [abandoned]", got)
+		}
+	})
+
+	t.Run("slow sinks do not block", func(t *testing.T) {
+		release := make(chan struct{})
+		sink := &recordSink{}
+		n := New(&Config{Debounce: "0s"}, blockingSink{release}, sink)
+		start := time.Now()
+		n.Notify(ForSession(session, EventWaiting, ""))
+		n.Notify(ForSession(session, EventCompleted, ""))
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Fatalf("Notify() took %s with a blocked sink", elapsed)
+		}
+		close(release)
+		n.Wait()
+		if got := sink.events(); len(got) != 2 || got[0] != EventWaiting || got[1] != EventCompleted {
+			t.Fatalf("sent %v, want [waiting completed] in order", got)
+		}
+	})
+
+	t.Run("nil notifier", func(t *testing.T) {
+		var n *Notifier
+		n.Notify(ForSession(session, EventCompleted, ""))
+		n.Cancel(session.ID)
+		n.Wait()
+	})
+}
+
+func TestForSession(t *testing.T) {
+	session := &db.Session{ID: "abc", WorkflowType: db.WorkflowPlay, TmuxSession: "work", TmuxWindow: 2, TmuxPane: 1}
+	n := ForSession(session, EventPhaseDone, "phase 2/3 (plan)")
+	if want := "Session abc (play) finished a phase: phase 2/3 (plan)"; n.Message != want {
+		t.Errorf("Message = %q, want %q", n.Message, want)
+	}
+	if n.Tmux != "work:2.1" {
+		t.Errorf("Tmux = %q, want work:2.1", n.Tmux)
+	}
+}
+
+func TestTerminalSink(t *testing.T) {
+	n := Notification{Message: "Session abc; done\n"}
+	tests := []struct {
+		name  string
+		style string
+		tmux  bool
+		want  string
+	}{
+		{name: "osc9", style: TerminalOSC9, want: "\x1b]9;Session abc; done \a"},
+		{name: "osc777", style: TerminalOSC777, want: "\x1b]777;notify;cmt;Session abc, done \a"},
+		{name: "osc9 in tmux", style: TerminalOSC9, tmux: true, want: "\x1bPtmux;\x1b\x1b]9;Session abc; done \a\x1b\\"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (terminalSink{w: &buf, style: tt.style, tmux: tt.tmux}).Send(n); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Notification
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	sink := newWebhookSink(&Webhook{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}})
+	n := ForSession(&db.Session{ID: "abc", WorkflowType: db.WorkflowResearch}, EventWaiting, "")
+	if err := sink.Send(n); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.Event != EventWaiting || got.SessionID != "abc" || got.Message != n.Message {
+		t.Errorf("webhook received %+v, want %+v", got, n)
+	}
+	if auth != "Bearer x" {
+		t.Errorf("Authorization = %q, want the configured header", auth)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := newWebhookSink(&Webhook{URL: failing.URL}).Send(n); err == nil {
+		t.Error("Send() to a failing webhook succeeded")
+	}
+}
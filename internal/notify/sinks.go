@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// title is the title of desktop and terminal notifications.
+const title = "cmt"
+
+// webhookTimeout bounds a webhook request.
+const webhookTimeout = 5 * time.Second
+
+// tmuxSink shows the notification in the status line of the tmux client.
+type tmuxSink struct{}
+
+func (tmuxSink) Send(n Notification) error {
+	if os.Getenv("TMUX") == "" {
+		return nil
+	}
+	// display-message expands formats; ## is a literal #
+	msg := strings.ReplaceAll(n.Message, "#", "##")
+	return exec.Command("tmux", "display-message", msg).Run()
+}
+
+// bellSink rings the bell of the terminal cmt runs in. tmux flags the window, so it can
+// be spotted from other windows.
+type bellSink struct {
+	w io.Writer
+}
+
+func (s bellSink) Send(Notification) error {
+	_, err := io.WriteString(s.w, "\a")
+	return err
+}
+
+// desktopSink shows a desktop notification.
+type desktopSink struct{}
+
+func (desktopSink) Send(n Notification) error {
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(n.Message), appleScriptQuote(title))
+		return exec.Command("osascript", "-e", script).Run()
+	}
+	return exec.Command("notify-send", "--app-name", title, title, n.Message).Run()
+}
+
+func appleScriptQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// terminalSink writes a notification escape sequence to the terminal cmt runs in. Inside
+// tmux the sequence is wrapped for passthrough, which needs tmux's allow-passthrough option.
+type terminalSink struct {
+	w     io.Writer
+	style string
+	tmux  bool
+}
+
+func (s terminalSink) Send(n Notification) error {
+	// Control characters would end the sequence early
+	msg := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, n.Message)
+
+	var seq string
+	if s.style == TerminalOSC777 {
+		seq = fmt.Sprintf("\x1b]777;notify;%s;%s\a", title, strings.ReplaceAll(msg, ";", ","))
+	} else {
+		seq = fmt.Sprintf("\x1b]9;%s\a", msg)
+	}
+	if s.tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := io.WriteString(s.w, seq)
+	return err
+}
+
+// webhookSink posts notifications as JSON.
+type webhookSink struct {
+	hook   *Webhook
+	client *http.Client
+}
+
+func newWebhookSink(hook *Webhook) webhookSink {
+	return webhookSink{hook: hook, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (s webhookSink) Send(n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("encode notification: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.hook.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -3,7 +3,10 @@ package pi
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/agentic-camerata/cmt/internal/agent"
 	"github.com/agentic-camerata/cmt/internal/db"
@@ -24,9 +27,21 @@ func NewRunner(database *db.DB) (*Runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	base.SessionIDs = agent.SessionFiles{Dir: sessionDir, Ext: ".jsonl"}
 	return &Runner{base: base}, nil
 }
 
+// sessionDir returns the directory Pi keeps the session files of workDir in. Pi resumes
+// a session by the path of its file, so the path is the session ID.
+func sessionDir(workDir string) string {
+	root := os.Getenv("PI_CODING_AGENT_DIR")
+	if root == "" {
+		root = agent.HomeDir(".pi", "agent")
+	}
+	name := "--" + strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(strings.TrimPrefix(workDir, "/")) + "--"
+	return filepath.Join(root, "sessions", name)
+}
+
 // Run starts a Pi session.
 func (r *Runner) Run(ctx context.Context, opts agent.RunOptions) error {
 	cmd := r.buildCommand(opts)
@@ -79,6 +94,28 @@ func (r *Runner) DefaultModel(cmd agent.CommandType) string {
 	return opusVersioned
 }
 
+// Prompt returns the task description with the Pi prompt prefix applied.
+func (r *Runner) Prompt(opts agent.RunOptions) string {
+	return agent.ApplyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+}
+
+// Capabilities reports the options the Pi CLI honours. Pi never asks for permission, so
+// it is always autonomous.
+func (r *Runner) Capabilities() agent.Capabilities {
+	return agent.Capabilities{
+		Binary:       "pi",
+		Resume:       true,
+		ResumePicker: true,
+		Models:       true,
+		Efforts:      []string{"low", "normal", "medium", "high", "xhigh"},
+		PrintMode:    true,
+		Autonomous:   true,
+		NoPersist:    true,
+
+		SessionIDCapture: true,
+	}
+}
+
 // buildCommand constructs the pi CLI command from the given options.
 func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 	args := []string{}
@@ -120,7 +157,7 @@ func (r *Runner) buildCommand(opts agent.RunOptions) *exec.Cmd {
 	}
 
 	// Build task with prompt prefix (uses underscore slash commands, same as Claude)
-	taskDescription := agent.ApplyPromptPrefix(opts.Command, opts.TaskDescription, opts.CommentTag)
+	taskDescription := r.Prompt(opts)
 	if taskDescription != "" {
 		args = append(args, taskDescription)
 	}
@@ -0,0 +1,154 @@
+package playbook
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Phase outcome statuses recorded while a playbook runs.
+const (
+	StatusDone       = "done"
+	StatusFailed     = "failed"
+	StatusSkipped    = "skipped"
+	StatusRolledBack = "rolled-back" // a prerequisite was missing, so the play moved back to an earlier phase
+)
+
+// PreviousPhase is the pseudo-tag that refers to the most recently run phase in a condition.
+const PreviousPhase = "previous"
+
+// Condition is a parsed when: predicate. Supported forms:
+//
+//	exists <path or glob>      a matching file exists
+//	captured <tag>             the tagged phase captured at least one file
+//	contains <tag> <text>      the latest file captured by the tag contains text
+//	succeeded <tag|previous>   the phase ran and succeeded
+//	failed <tag|previous>      the phase ran and failed
+//	sh <command>               the shell command exits with status 0
+//
+// Any form can be negated with a leading "not " or "!".
+type Condition struct {
+	Negate bool
+	Kind   string
+	Arg    string // path/glob, tag, or shell command
+	Text   string // search text (contains only)
+}
+
+// ConditionEnv holds the outcomes of previous phases that conditions are evaluated against.
+type ConditionEnv struct {
+	WorkDir     string              // directory for relative paths and shell predicates
+	TaggedFiles map[string][]string // tag → captured files
+	TagStatus   map[string]string   // tag → status of the last run of the tagged phase
+	LastStatus  string              // status of the most recently run phase
+}
+
+// ParseCondition parses a when: expression.
+func ParseCondition(expr string) (*Condition, error) {
+	c := &Condition{}
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "!") {
+		c.Negate = true
+		s = strings.TrimSpace(s[1:])
+	} else if strings.HasPrefix(strings.ToLower(s), "not ") {
+		c.Negate = true
+		s = strings.TrimSpace(s[4:])
+	}
+
+	kind, rest, _ := strings.Cut(s, " ")
+	c.Kind = strings.ToLower(kind)
+	rest = strings.TrimSpace(rest)
+
+	switch c.Kind {
+	case "exists", "captured", "succeeded", "failed", "sh":
+		if rest == "" {
+			return nil, fmt.Errorf("%s: missing argument", c.Kind)
+		}
+		c.Arg = rest
+	case "contains":
+		tag, text, _ := strings.Cut(rest, " ")
+		text = unquote(strings.TrimSpace(text))
+		if tag == "" || text == "" {
+			return nil, fmt.Errorf("contains: expected <tag> <text>")
+		}
+		c.Arg = tag
+		c.Text = text
+	case "":
+		return nil, fmt.Errorf("empty condition")
+	default:
+		return nil, fmt.Errorf("unknown condition %q (valid: exists, captured, contains, succeeded, failed, sh)", kind)
+	}
+	return c, nil
+}
+
+// Eval evaluates the condition against env.
+func (c *Condition) Eval(env ConditionEnv) (bool, error) {
+	result, err := c.eval(env)
+	if err != nil {
+		return false, err
+	}
+	return result != c.Negate, nil
+}
+
+func (c *Condition) eval(env ConditionEnv) (bool, error) {
+	switch c.Kind {
+	case "exists":
+		matches, err := filepath.Glob(resolvePath(env.WorkDir, c.Arg))
+		if err != nil {
+			return false, fmt.Errorf("exists: %w", err)
+		}
+		return len(matches) > 0, nil
+	case "captured":
+		return len(env.TaggedFiles[c.Arg]) > 0, nil
+	case "contains":
+		files := env.TaggedFiles[c.Arg]
+		if len(files) == 0 {
+			return false, nil
+		}
+		data, err := os.ReadFile(resolvePath(env.WorkDir, files[len(files)-1]))
+		if err != nil {
+			return false, nil
+		}
+		return strings.Contains(string(data), c.Text), nil
+	case "succeeded":
+		return c.status(env) == StatusDone, nil
+	case "failed":
+		return c.status(env) == StatusFailed, nil
+	case "sh":
+		cmd := exec.Command("sh", "-c", c.Arg)
+		cmd.Dir = env.WorkDir
+		if err := cmd.Run(); err != nil {
+			if _, ok := err.(*exec.ExitError); ok {
+				return false, nil
+			}
+			return false, fmt.Errorf("sh: %w", err)
+		}
+		return true, nil
+	}
+	return false, fmt.Errorf("unknown condition %q", c.Kind)
+}
+
+// status returns the recorded status of the phase the condition refers to.
+func (c *Condition) status(env ConditionEnv) string {
+	if c.Arg == PreviousPhase {
+		return env.LastStatus
+	}
+	return env.TagStatus[c.Arg]
+}
+
+// resolvePath joins a relative path onto dir. Absolute paths and an empty dir are left unchanged.
+func resolvePath(dir, path string) string {
+	if dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// unquote strips one pair of matching surrounding quotes.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
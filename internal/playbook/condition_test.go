@@ -0,0 +1,107 @@
+package playbook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Condition
+		wantErr bool
+	}{
+		{expr: "exists thoughts/shared/plans/*.md", want: Condition{Kind: "exists", Arg: "thoughts/shared/plans/*.md"}},
+		{expr: "not captured research", want: Condition{Negate: true, Kind: "captured", Arg: "research"}},
+		{expr: "!failed previous", want: Condition{Negate: true, Kind: "failed", Arg: "previous"}},
+		{expr: `contains review "LGTM"`, want: Condition{Kind: "contains", Arg: "review", Text: "LGTM"}},
+		{expr: "contains review looks good", want: Condition{Kind: "contains", Arg: "review", Text: "looks good"}},
+		{expr: "sh test -f go.mod", want: Condition{Kind: "sh", Arg: "test -f go.mod"}},
+		{expr: "SUCCEEDED impl", want: Condition{Kind: "succeeded", Arg: "impl"}},
+		{expr: "", wantErr: true},
+		{expr: "exists", wantErr: true},
+		{expr: "contains review", wantErr: true},
+		{expr: "maybe later", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseCondition(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("ParseCondition(%q) = %+v, want %+v", tt.expr, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionEval(t *testing.T) {
+	dir := t.TempDir()
+	plansDir := filepath.Join(dir, "thoughts", "shared", "plans")
+	if err := os.MkdirAll(plansDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(plansDir, "plan.md"), []byte("# Plan"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reviewsDir := filepath.Join(dir, "thoughts", "shared", "reviews")
+	if err := os.MkdirAll(reviewsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(reviewsDir, "r1.md"), []byte("Needs work"), 0644)    //nolint:errcheck
+	os.WriteFile(filepath.Join(reviewsDir, "r2.md"), []byte("Verdict: LGTM"), 0644) //nolint:errcheck
+
+	env := ConditionEnv{
+		WorkDir: dir,
+		TaggedFiles: map[string][]string{
+			"review": {"thoughts/shared/reviews/r1.md", "thoughts/shared/reviews/r2.md"},
+		},
+		TagStatus:  map[string]string{"impl": StatusFailed, "review": StatusDone},
+		LastStatus: StatusDone,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"exists thoughts/shared/plans/*.md", true},
+		{"not exists thoughts/shared/plans/*.md", false},
+		{"exists thoughts/shared/research/*.md", false},
+		{"captured review", true},
+		{"captured research", false},
+		{"contains review LGTM", true},
+		{"contains review Needs work", false}, // only the latest capture is checked
+		{"succeeded review", true},
+		{"failed impl", true},
+		{"succeeded impl", false},
+		{"succeeded previous", true},
+		{"failed never-ran", false},
+		{"sh test -f thoughts/shared/plans/plan.md", true},
+		{"sh exit 3", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := ParseCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCondition(%q): %v", tt.expr, err)
+			}
+			got, err := cond.Eval(env)
+			if err != nil {
+				t.Fatalf("Eval(%q): %v", tt.expr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
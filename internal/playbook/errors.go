@@ -0,0 +1,56 @@
+package playbook
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error is a single problem found in a playbook.
+type Error struct {
+	File string // playbook file; empty for content parsed from a string
+	Line int    // 1-based line number; 0 when not tied to a line
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.File != "" && e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	case e.File != "":
+		return fmt.Sprintf("%s: %s", e.File, e.Msg)
+	}
+	return e.Msg
+}
+
+// Errors is every problem found while parsing a playbook, in file order.
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// add records a problem found on the given line.
+func (errs *Errors) add(line int, format string, args ...any) {
+	*errs = append(*errs, &Error{Line: line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// withFile sets the file of every error in err that has none. Errors that are not
+// playbook Errors are wrapped with the file name.
+func withFile(err error, file string) error {
+	errs, ok := err.(Errors)
+	if !ok {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	for _, e := range errs {
+		if e.File == "" {
+			e.File = file
+		}
+	}
+	return errs
+}
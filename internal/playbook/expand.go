@@ -0,0 +1,77 @@
+package playbook
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Expand returns a copy of the playbook with every play phase replaced by the phases
+// of the playbook it names, recursively, parsing nested playbooks with values. Problems
+// in nested playbooks are collected rather than stopping at the first, and a playbook
+// that plays itself, directly or indirectly, is reported as an error.
+func (pb *Playbook) Expand(values map[string]string) (*Playbook, error) {
+	var stack []string
+	if pb.File != "" {
+		if abs, err := filepath.Abs(pb.File); err == nil {
+			stack = append(stack, abs)
+		}
+	}
+	var errs Errors
+	var files []string
+	phases := expandPhases(pb.Phases, pb.File, values, stack, &files, &errs)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Tag references can cross playbook boundaries, so check them on the expanded phases
+	for i, p := range phases {
+		for j := 0; j < i; j++ {
+			if p.Tag != "" && phases[j].Tag == p.Tag && files[j] != files[i] {
+				errs = append(errs, &Error{File: files[i], Line: p.Line,
+					Msg: fmt.Sprintf("duplicate phase tag: %q (also in %s)", p.Tag, files[j])})
+			}
+		}
+	}
+	errs = append(errs, checkRefs(phases, files)...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &Playbook{Frontmatter: pb.Frontmatter, File: pb.File, Phases: phases}, nil
+}
+
+// expandPhases inlines the play phases of phases, which were read from file, appending
+// the file of each returned phase to files. stack holds the absolute paths of the
+// playbooks being expanded, outermost first.
+func expandPhases(phases []Phase, file string, values map[string]string, stack []string, files *[]string, errs *Errors) []Phase {
+	var out []Phase
+	for _, p := range phases {
+		if p.Type != "play" {
+			out = append(out, p)
+			*files = append(*files, file)
+			continue
+		}
+		abs, err := filepath.Abs(p.Content)
+		if err != nil {
+			*errs = append(*errs, &Error{File: file, Line: p.Line, Msg: err.Error()})
+			continue
+		}
+		if slices.Contains(stack, abs) {
+			*errs = append(*errs, &Error{File: file, Line: p.Line,
+				Msg: "play cycle: " + strings.Join(append(slices.Clone(stack), abs), " -> ")})
+			continue
+		}
+		nested, err := ParseNested(p.Content, values)
+		if err != nil {
+			if nestedErrs, ok := err.(Errors); ok {
+				*errs = append(*errs, nestedErrs...)
+			} else {
+				*errs = append(*errs, &Error{File: file, Line: p.Line, Msg: err.Error()})
+			}
+			continue
+		}
+		out = append(out, expandPhases(nested.Phases, p.Content, values, append(slices.Clone(stack), abs), files, errs)...)
+	}
+	return out
+}
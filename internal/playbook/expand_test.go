@@ -0,0 +1,66 @@
+package playbook
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	nested := write("nested.md", "## Plan\ntag: design\nuses: notes\nDesign {{.ticket}}\n")
+	mainPath := write("main.md", "---\nparams: [ticket]\n---\n## Research\ntag: notes\n{{.ticket}}\n\n## Play\n"+nested+"\n\n## Implement\nuses: design\n")
+	self := write("self.md", "## Play\n"+filepath.Join(dir, "self.md")+"\n")
+	dangling := write("dangling.md", "## Research\ntag: notes\nx\n\n## Play\n"+write("bad.md", "## Plan\nuses: nowhere\n")+"\n")
+
+	t.Run("inlines nested phases with parent params", func(t *testing.T) {
+		pb, err := ParseWithParams(mainPath, map[string]string{"ticket": "ABC-1"})
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		expanded, err := pb.Expand(map[string]string{"ticket": "ABC-1"})
+		if err != nil {
+			t.Fatalf("expand: %v", err)
+		}
+		var types []string
+		for _, p := range expanded.Phases {
+			types = append(types, p.Type)
+		}
+		if got := strings.Join(types, ","); got != "research,plan,implement" {
+			t.Fatalf("phases = %s, want research,plan,implement", got)
+		}
+		if got := expanded.Phases[1].Content; got != "Design ABC-1" {
+			t.Errorf("nested content = %q, want %q", got, "Design ABC-1")
+		}
+	})
+
+	t.Run("play cycle", func(t *testing.T) {
+		pb, err := Parse(self)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if _, err := pb.Expand(nil); err == nil || !strings.Contains(err.Error(), "play cycle") {
+			t.Fatalf("error = %v, want play cycle", err)
+		}
+	})
+
+	t.Run("unknown tag in nested playbook", func(t *testing.T) {
+		pb, err := Parse(dangling)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		_, err = pb.Expand(nil)
+		if err == nil || !strings.Contains(err.Error(), `bad.md:1: phase 2 (plan): uses unknown tag "nowhere"`) {
+			t.Fatalf("error = %v, want unknown tag in bad.md", err)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package playbook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvLibraryDir is the environment variable that overrides the global playbook library directory.
+const EnvLibraryDir = "CMT_PLAYBOOKS_DIR"
+
+// ProjectLibraryDir is the per-project playbook library, looked up from the working
+// directory and its parents.
+const ProjectLibraryDir = ".cmt/playbooks"
+
+// Library scopes. Project playbooks shadow global ones with the same name.
+const (
+	ScopeProject = "project"
+	ScopeGlobal  = "global"
+)
+
+// ErrNotInLibrary is returned by LookupLibrary when no playbook has the name.
+var ErrNotInLibrary = errors.New("not found in the library")
+
+// LibraryEntry describes a playbook in the library.
+type LibraryEntry struct {
+	Name        string // file name without .md
+	Path        string // absolute path
+	Scope       string // ScopeProject or ScopeGlobal
+	Description string // from the frontmatter, if any
+}
+
+// GlobalLibraryDir returns the absolute global library directory (CMT_PLAYBOOKS_DIR or the
+// default ~/.agentic-camerata/playbooks), with a leading ~ expanded. It does not create
+// the directory.
+func GlobalLibraryDir() (string, error) {
+	dir := os.Getenv(EnvLibraryDir)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("get home directory: %w", err)
+		}
+		return filepath.Join(home, ".agentic-camerata", "playbooks"), nil
+	}
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("get home directory: %w", err)
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	return filepath.Abs(dir)
+}
+
+// FindProjectLibraryDir returns the nearest .cmt/playbooks directory in workDir or one of
+// its parents, or "" if there is none.
+func FindProjectLibraryDir(workDir string) string {
+	dir, err := filepath.Abs(workDir)
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(dir, ProjectLibraryDir)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Library lists the playbooks available from workDir: the project library first, then the
+// global one, each sorted by name. Missing directories are treated as empty.
+func Library(workDir string) ([]LibraryEntry, error) {
+	global, err := GlobalLibraryDir()
+	if err != nil {
+		return nil, err
+	}
+	var out []LibraryEntry
+	if dir := FindProjectLibraryDir(workDir); dir != "" {
+		entries, err := libraryEntries(dir, ScopeProject)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entries...)
+	}
+	entries, err := libraryEntries(global, ScopeGlobal)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, entries...), nil
+}
+
+// libraryEntries lists the .md playbooks directly in dir.
+func libraryEntries(dir, scope string) ([]LibraryEntry, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read playbook library: %w", err)
+	}
+	var out []LibraryEntry
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasSuffix(name, ".md") {
+			continue
+		}
+		entry := LibraryEntry{
+			Name:  strings.TrimSuffix(name, ".md"),
+			Path:  filepath.Join(dir, name),
+			Scope: scope,
+		}
+		if fm, err := ReadFrontmatter(entry.Path); err == nil {
+			entry.Description = fm.Description
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// LookupLibrary finds a playbook by name (with or without .md), preferring the project
+// library over the global one.
+func LookupLibrary(workDir, name string) (*LibraryEntry, error) {
+	entries, err := Library(workDir)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSuffix(name, ".md")
+	for _, e := range entries {
+		if e.Name == name {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("playbook %q %w", name, ErrNotInLibrary)
+}
+
+// SelectFromLibrary opens fzf on the playbooks available from workDir and returns the chosen entry.
+func SelectFromLibrary(workDir string) (*LibraryEntry, error) {
+	entries, err := Library(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("the playbook library is empty (add playbooks to %s or $%s)", ProjectLibraryDir, EnvLibraryDir)
+	}
+	if _, err := exec.LookPath("fzf"); err != nil {
+		return nil, fmt.Errorf("fzf is required but not installed. Install with: brew install fzf")
+	}
+
+	// One tab-separated line per entry; the index in the first field identifies the choice
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = fmt.Sprintf("%d\t%s\t%s\t%s", i, e.Name, e.Scope, e.Description)
+	}
+	cmd := exec.Command("fzf",
+		"--header", "Select playbook:",
+		"--header-first",
+		"--delimiter", "\t",
+		"--with-nth", "2..",
+	)
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n"))
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 130 {
+			return nil, fmt.Errorf("no playbook selected")
+		}
+		return nil, fmt.Errorf("fzf failed: %w", err)
+	}
+	idx, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	var i int
+	if _, err := fmt.Sscan(idx, &i); err != nil || i < 0 || i >= len(entries) {
+		return nil, fmt.Errorf("no playbook selected")
+	}
+	return &entries[i], nil
+}
@@ -0,0 +1,79 @@
+package playbook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	global := t.TempDir()
+	t.Setenv(EnvLibraryDir, global)
+	project := t.TempDir()
+	workDir := filepath.Join(project, "sub", "dir")
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(global, "ship.md"), "---\ndescription: Global ship\n---\n## Research\nx\n")
+	writeFile(t, filepath.Join(global, "review.md"), "## Review\nx\n")
+	writeFile(t, filepath.Join(global, "20241018-release.md"), "## Research\nx\n")
+	writeFile(t, filepath.Join(global, "notes.txt"), "not a playbook")
+	writeFile(t, filepath.Join(project, ProjectLibraryDir, "ship.md"), "---\ndescription: Project ship\n---\n## Research\nx\n")
+
+	entries, err := Library(workDir)
+	if err != nil {
+		t.Fatalf("Library: %v", err)
+	}
+	want := []LibraryEntry{
+		{Name: "ship", Scope: ScopeProject, Description: "Project ship"},
+		{Name: "20241018-release", Scope: ScopeGlobal},
+		{Name: "review", Scope: ScopeGlobal},
+		{Name: "ship", Scope: ScopeGlobal, Description: "Global ship"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Library() = %+v, want %d entries", entries, len(want))
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Name != w.Name || e.Scope != w.Scope || e.Description != w.Description {
+			t.Errorf("entries[%d] = %+v, want %+v", i, e, w)
+		}
+	}
+
+	t.Run("project shadows global", func(t *testing.T) {
+		e, err := LookupLibrary(workDir, "ship.md")
+		if err != nil {
+			t.Fatalf("LookupLibrary: %v", err)
+		}
+		if e.Scope != ScopeProject {
+			t.Errorf("scope = %q, want %q", e.Scope, ScopeProject)
+		}
+	})
+
+	t.Run("global only", func(t *testing.T) {
+		e, err := LookupLibrary(t.TempDir(), "ship")
+		if err != nil {
+			t.Fatalf("LookupLibrary: %v", err)
+		}
+		if e.Path != filepath.Join(global, "ship.md") {
+			t.Errorf("path = %q", e.Path)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, err := LookupLibrary(workDir, "missing"); err == nil {
+			t.Error("expected error for a missing playbook")
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package playbook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutputSpec is a parsed outputs: contract, declaring files a phase must write:
+//
+//	outputs: thoughts/shared/plans/*.md; count=1; sections=Overview, Testing Strategy
+//
+// The glob is matched relative to the working directory (filepath.Match syntax, no **).
+// At least count files matching it must be created or modified by the phase (default 1),
+// and each of them must have a markdown heading for every listed section.
+type OutputSpec struct {
+	Glob     string
+	Count    int
+	Sections []string
+}
+
+// ParseOutputSpec parses an outputs: value.
+func ParseOutputSpec(raw string) (*OutputSpec, error) {
+	parts := strings.Split(raw, ";")
+	spec := &OutputSpec{Glob: strings.TrimSpace(parts[0]), Count: 1}
+	if spec.Glob == "" {
+		return nil, fmt.Errorf("missing glob")
+	}
+	if _, err := filepath.Match(spec.Glob, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", spec.Glob, err)
+	}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected key=value, got %q", part)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "count":
+			n, err := strconv.Atoi(strings.TrimSpace(val))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid count %q (must be a positive number)", strings.TrimSpace(val))
+			}
+			spec.Count = n
+		case "sections":
+			spec.Sections = splitList(val)
+			if len(spec.Sections) == 0 {
+				return nil, fmt.Errorf("sections: expected a comma-separated list of headings")
+			}
+		default:
+			return nil, fmt.Errorf("unknown key %q (valid: count, sections)", strings.TrimSpace(key))
+		}
+	}
+	return spec, nil
+}
+
+// String formats the contract for display.
+func (s *OutputSpec) String() string {
+	out := fmt.Sprintf("%s (at least %d", s.Glob, s.Count)
+	if len(s.Sections) > 0 {
+		out += "; sections: " + strings.Join(s.Sections, ", ")
+	}
+	return out + ")"
+}
+
+// ParseOutputSpecs parses every outputs: contract of a phase. The contracts were
+// validated when the playbook was parsed.
+func ParseOutputSpecs(phase Phase) []*OutputSpec {
+	var specs []*OutputSpec
+	for _, raw := range phase.Outputs {
+		if spec, err := ParseOutputSpec(raw); err == nil {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// OutputSnapshot records the modification times of the files matching a set of
+// contracts, so files the phase creates or modifies can be told apart afterwards.
+type OutputSnapshot map[string]time.Time
+
+// SnapshotOutputs records the files currently matching specs.
+func SnapshotOutputs(specs []*OutputSpec) OutputSnapshot {
+	snap := make(OutputSnapshot)
+	for _, spec := range specs {
+		matches, _ := filepath.Glob(spec.Glob)
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				snap[path] = info.ModTime()
+			}
+		}
+	}
+	return snap
+}
+
+// CheckOutputs returns the files matching specs that were created or modified since
+// before, and a description of every contract they do not satisfy.
+func CheckOutputs(specs []*OutputSpec, before OutputSnapshot) (files, problems []string) {
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		var produced []string
+		matches, _ := filepath.Glob(spec.Glob)
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if mtime, ok := before[path]; ok && !info.ModTime().After(mtime) {
+				continue
+			}
+			produced = append(produced, path)
+		}
+
+		if len(produced) < spec.Count {
+			problems = append(problems, fmt.Sprintf("expected at least %d new or modified file(s) matching %s, found %d",
+				spec.Count, spec.Glob, len(produced)))
+		}
+		for _, path := range produced {
+			if missing := missingSections(path, spec.Sections); len(missing) > 0 {
+				problems = append(problems, fmt.Sprintf("%s is missing section(s): %s", path, strings.Join(missing, ", ")))
+			}
+			if !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+		}
+	}
+	return files, problems
+}
+
+// globsOverlap reports whether some path could match both globs. It errs on the side of
+// overlap: two segments with wildcards overlap unless their literal prefixes or suffixes
+// conflict.
+func globsOverlap(a, b string) bool {
+	as := strings.Split(filepath.ToSlash(filepath.Clean(a)), "/")
+	bs := strings.Split(filepath.ToSlash(filepath.Clean(b)), "/")
+	// A wildcard never matches a separator, so paths of different depths never match both
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if !segmentsOverlap(as[i], bs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// segmentsOverlap reports whether some path segment could match both glob segments.
+func segmentsOverlap(a, b string) bool {
+	aMeta, bMeta := hasGlobMeta(a), hasGlobMeta(b)
+	switch {
+	case !aMeta && !bMeta:
+		return a == b
+	case !aMeta:
+		ok, _ := filepath.Match(b, a)
+		return ok
+	case !bMeta:
+		ok, _ := filepath.Match(a, b)
+		return ok
+	}
+	aPrefix, aSuffix := globLiterals(a)
+	bPrefix, bSuffix := globLiterals(b)
+	return (strings.HasPrefix(aPrefix, bPrefix) || strings.HasPrefix(bPrefix, aPrefix)) &&
+		(strings.HasSuffix(aSuffix, bSuffix) || strings.HasSuffix(bSuffix, aSuffix))
+}
+
+// hasGlobMeta reports whether a glob segment contains any wildcard or escape.
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, `*?[\`)
+}
+
+// globLiterals returns the literal text of a glob segment before its first and after
+// its last wildcard.
+func globLiterals(s string) (prefix, suffix string) {
+	first := strings.IndexAny(s, `*?[\`)
+	last := strings.LastIndexAny(s, `*?]\`)
+	return s[:first], s[last+1:]
+}
+
+// missingSections returns the sections that have no matching markdown heading in the file.
+// Headings match case-insensitively at any level.
+func missingSections(path string, sections []string) []string {
+	if len(sections) == 0 {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sections
+	}
+	headings := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		heading := strings.TrimSpace(strings.TrimLeft(line, "#"))
+		headings[strings.ToLower(heading)] = true
+	}
+	var missing []string
+	for _, s := range sections {
+		if !headings[strings.ToLower(s)] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
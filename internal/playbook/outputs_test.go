@@ -0,0 +1,115 @@
+package playbook
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseOutputSpec(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    OutputSpec
+		wantErr bool
+	}{
+		{raw: "docs/*.md", want: OutputSpec{Glob: "docs/*.md", Count: 1}},
+		{raw: "docs/*.md; count=2", want: OutputSpec{Glob: "docs/*.md", Count: 2}},
+		{raw: "plans/*.md; sections=Overview, Testing Strategy", want: OutputSpec{Glob: "plans/*.md", Count: 1, Sections: []string{"Overview", "Testing Strategy"}}},
+		{raw: "", wantErr: true},
+		{raw: "docs/[.md", wantErr: true},
+		{raw: "docs/*.md; count=0", wantErr: true},
+		{raw: "docs/*.md; size=2", wantErr: true},
+		{raw: "docs/*.md; sections", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseOutputSpec(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOutputSpec(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Glob != tt.want.Glob || got.Count != tt.want.Count || !slices.Equal(got.Sections, tt.want.Sections) {
+				t.Errorf("ParseOutputSpec(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"docs/*.md", "docs/*.md", true},
+		{"docs/*.md", "./docs/*.md", true},
+		{"docs/*.md", "docs/api.md", true},
+		{"docs/api-*.md", "docs/db-*.md", false},
+		{"docs/*-api.md", "docs/*-db.md", false},
+		{"docs/*.md", "docs/*.txt", false},
+		{"docs/*.md", "notes/*.md", false},
+		{"docs/*.md", "docs/sub/*.md", false},
+		{"docs/*", "docs/api-*.md", true},
+		{"*/plan.md", "docs/*.md", true},
+		{"docs/api.md", "docs/db.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := globsOverlap(tt.a, tt.b); got != tt.want {
+				t.Errorf("globsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := globsOverlap(tt.b, tt.a); got != tt.want {
+				t.Errorf("globsOverlap(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOutputs(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.md")
+	writeFile(t, old, "# Old\n")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(old, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := ParseOutputSpec(filepath.Join(dir, "*.md") + "; sections=Overview, Testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs := []*OutputSpec{spec}
+	before := SnapshotOutputs(specs)
+
+	// Nothing new yet: the pre-existing file does not count
+	files, problems := CheckOutputs(specs, before)
+	if len(files) != 0 || len(problems) != 1 {
+		t.Fatalf("before writing: files = %v, problems = %v", files, problems)
+	}
+
+	plan := filepath.Join(dir, "plan.md")
+	writeFile(t, plan, "# Plan\n## Overview\ntext\n")
+	files, problems = CheckOutputs(specs, before)
+	if !slices.Equal(files, []string{plan}) {
+		t.Errorf("files = %v, want [%s]", files, plan)
+	}
+	if len(problems) != 1 {
+		t.Errorf("problems = %v, want the missing Testing section", problems)
+	}
+
+	writeFile(t, plan, "# Plan\n## Overview\ntext\n### testing\nmore\n")
+	if _, problems = CheckOutputs(specs, before); len(problems) != 0 {
+		t.Errorf("problems = %v, want none", problems)
+	}
+
+	// Modifying a pre-existing file counts as producing it
+	writeFile(t, old, "# Old\n## Overview\n## Testing\n")
+	if files, _ = CheckOutputs(specs, before); len(files) != 2 {
+		t.Errorf("files = %v, want both files", files)
+	}
+}
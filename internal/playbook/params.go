@@ -0,0 +1,174 @@
+package playbook
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Frontmatter holds the optional header of a playbook, delimited by --- lines:
+//
+//	---
+//	description: Research a ticket, then plan and implement it
+//	params: [ticket, area]
+//	defaults:
+//	  area: backend
+//	---
+//
+// params may also be written as a block list ("- ticket") or as a block of
+// "name: default" lines. Only this small subset of YAML is understood.
+type Frontmatter struct {
+	Description string
+	Params      []string          // declared parameter names, in order
+	Defaults    map[string]string // parameter name → default value
+}
+
+// paramNameRe matches parameter names usable as {{.name}} in templates.
+var paramNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ReadFrontmatter reads only the frontmatter of a playbook file.
+func ReadFrontmatter(path string) (*Frontmatter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read playbook: %w", err)
+	}
+	fm, _, err := splitFrontmatter(string(data))
+	if err != nil {
+		return nil, withFile(err, path)
+	}
+	return &fm, nil
+}
+
+// Values merges the defaults with set and returns the resulting parameter values,
+// along with the declared parameters that still have no value.
+func (fm *Frontmatter) Values(set map[string]string) (values map[string]string, missing []string) {
+	values = make(map[string]string)
+	for _, name := range fm.Params {
+		if v, ok := set[name]; ok {
+			values[name] = v
+		} else if v, ok := fm.Defaults[name]; ok {
+			values[name] = v
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return values, missing
+}
+
+// splitFrontmatter separates the frontmatter from the playbook body. The frontmatter
+// lines are blanked rather than removed so line numbers in the body stay the same.
+func splitFrontmatter(content string) (fm Frontmatter, body string, err error) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return fm, content, nil
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return fm, "", frontmatterError(1, "missing closing ---")
+	}
+
+	var block string // key of the block being read ("params" or "defaults")
+	for i := 1; i < end; i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		if indented && block != "" {
+			if item, ok := strings.CutPrefix(trimmed, "- "); ok && block == "params" {
+				fm.Params = append(fm.Params, unquote(strings.TrimSpace(item)))
+				continue
+			}
+			key, val, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return fm, "", frontmatterError(i+1, "expected name: value under %s", block)
+			}
+			key = strings.TrimSpace(key)
+			if block == "params" {
+				fm.Params = append(fm.Params, key)
+			}
+			if val = unquote(strings.TrimSpace(val)); val != "" || block == "defaults" {
+				if fm.Defaults == nil {
+					fm.Defaults = make(map[string]string)
+				}
+				fm.Defaults[key] = val
+			}
+			continue
+		}
+
+		key, val, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return fm, "", frontmatterError(i+1, "expected key: value")
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+		block = ""
+		switch key {
+		case "description":
+			fm.Description = unquote(val)
+		case "params":
+			if val == "" {
+				block = "params"
+				continue
+			}
+			val = strings.TrimSuffix(strings.TrimPrefix(val, "["), "]")
+			for _, name := range splitList(val) {
+				fm.Params = append(fm.Params, unquote(name))
+			}
+		case "defaults":
+			if val != "" {
+				return fm, "", frontmatterError(i+1, "defaults must be an indented block of name: value lines")
+			}
+			block = "defaults"
+		default:
+			return fm, "", frontmatterError(i+1, "unknown key %q (valid: description, params, defaults)", key)
+		}
+	}
+
+	for _, name := range fm.Params {
+		if !paramNameRe.MatchString(name) {
+			return fm, "", frontmatterError(0, "invalid parameter name %q (use letters, digits and _)", name)
+		}
+	}
+	for name := range fm.Defaults {
+		if !slices.Contains(fm.Params, name) {
+			return fm, "", frontmatterError(0, "default for undeclared parameter %q", name)
+		}
+	}
+
+	for i := 0; i <= end; i++ {
+		lines[i] = ""
+	}
+	return fm, strings.Join(lines, "\n"), nil
+}
+
+// frontmatterError returns a single-entry Errors for a problem in the frontmatter.
+func frontmatterError(line int, format string, args ...any) Errors {
+	var errs Errors
+	errs.add(line, "frontmatter: "+format, args...)
+	return errs
+}
+
+// render substitutes {{.name}} references in the playbook body with parameter values.
+func render(body string, values map[string]string) (string, error) {
+	tmpl, err := template.New("playbook").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("playbook template: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, values); err != nil {
+		return "", fmt.Errorf("playbook template: %w", err)
+	}
+	return sb.String(), nil
+}
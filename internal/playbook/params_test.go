@@ -0,0 +1,170 @@
+package playbook
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontmatter(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantDesc     string
+		wantParams   []string
+		wantDefaults map[string]string
+		wantErr      bool
+	}{
+		{
+			name:    "no frontmatter",
+			content: "## Research\ntopic\n",
+		},
+		{
+			name:         "inline params and defaults block",
+			content:      "---\ndescription: Ticket workflow\nparams: [ticket, area]\ndefaults:\n  area: backend\n---\n## Research\n{{.ticket}}\n",
+			wantDesc:     "Ticket workflow",
+			wantParams:   []string{"ticket", "area"},
+			wantDefaults: map[string]string{"area": "backend"},
+		},
+		{
+			name:       "block list params",
+			content:    "---\nparams:\n  - ticket\n  - area\n---\n",
+			wantParams: []string{"ticket", "area"},
+		},
+		{
+			name:         "params with defaults",
+			content:      "---\nparams:\n  ticket:\n  area: \"api layer\"\n---\n",
+			wantParams:   []string{"ticket", "area"},
+			wantDefaults: map[string]string{"area": "api layer"},
+		},
+		{
+			name:    "unterminated",
+			content: "---\nparams: [ticket]\n## Research\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown key",
+			content: "---\nauthor: me\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid param name",
+			content: "---\nparams: [my-ticket]\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "default for undeclared param",
+			content: "---\nparams: [ticket]\ndefaults:\n  area: api\n---\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := splitFrontmatter(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fm.Description != tt.wantDesc {
+				t.Errorf("description = %q, want %q", fm.Description, tt.wantDesc)
+			}
+			if !slices.Equal(fm.Params, tt.wantParams) {
+				t.Errorf("params = %v, want %v", fm.Params, tt.wantParams)
+			}
+			if !maps.Equal(fm.Defaults, tt.wantDefaults) {
+				t.Errorf("defaults = %v, want %v", fm.Defaults, tt.wantDefaults)
+			}
+			if got, want := strings.Count(body, "\n"), strings.Count(tt.content, "\n"); got != want {
+				t.Errorf("body has %d lines, want %d (line numbers must be preserved)", got, want)
+			}
+		})
+	}
+}
+
+func TestParseContentWithParams(t *testing.T) {
+	content := `---
+params: [ticket, area]
+defaults:
+  area: backend
+---
+## Research
+tag: {{.ticket}}
+Investigate {{.ticket}} in the {{.area}} code.
+
+## Plan
+uses: {{.ticket}}
+`
+
+	tests := []struct {
+		name        string
+		values      map[string]string
+		wantTag     string
+		wantContent string
+		wantErr     bool
+	}{
+		{
+			name:        "values and defaults",
+			values:      map[string]string{"ticket": "ABC-123"},
+			wantTag:     "ABC-123",
+			wantContent: "Investigate ABC-123 in the backend code.",
+		},
+		{
+			name:        "value overrides default",
+			values:      map[string]string{"ticket": "ABC-123", "area": "frontend"},
+			wantTag:     "ABC-123",
+			wantContent: "Investigate ABC-123 in the frontend code.",
+		},
+		{
+			name:    "missing value",
+			values:  nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb, err := ParseContentWithParams(content, tt.values)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pb.Phases[0].Tag != tt.wantTag {
+				t.Errorf("tag = %q, want %q", pb.Phases[0].Tag, tt.wantTag)
+			}
+			if pb.Phases[0].Content != tt.wantContent {
+				t.Errorf("content = %q, want %q", pb.Phases[0].Content, tt.wantContent)
+			}
+			if !slices.Equal(pb.Phases[1].Uses, []string{tt.wantTag}) {
+				t.Errorf("uses = %v, want [%s]", pb.Phases[1].Uses, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestParseContentUndeclaredReference(t *testing.T) {
+	content := "---\nparams: [ticket]\n---\n## Research\n{{.area}}\n"
+	if _, err := ParseContentWithParams(content, map[string]string{"ticket": "x"}); err == nil {
+		t.Fatal("expected error for undeclared {{.area}}, got nil")
+	}
+
+	// Without declared params the body is not treated as a template
+	pb, err := ParseContent("## Research\nKeep {{braces}} literal\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pb.Phases[0].Content != "Keep {{braces}} literal" {
+		t.Errorf("content = %q", pb.Phases[0].Content)
+	}
+}
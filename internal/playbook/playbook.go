@@ -3,70 +3,197 @@ package playbook
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/config"
 )
 
 // Phase represents a single phase in a playbook
 type Phase struct {
-	Type    string   // "research", "plan", "implement", "new", "fix", "fix-local-comments"
-	Content string   // task description (body text under heading, metadata stripped)
-	Tag     string   // optional tag for referencing this phase's outputs
-	Uses    []string // optional tags of phases whose outputs to use
-	Include []string // optional file paths to prepend to the phase prompt
-	Pick    string   // "true" for fzf selector, "last" for latest file (implement only)
-	Agent   string   // optional agent backend override: "claude", "codex", "amp"
+	Type       string   // "research", "plan", "implement", "new", "fix", "fix-local-comments"
+	Content    string   // task description (body text under heading, metadata stripped)
+	Tag        string   // optional tag for referencing this phase's outputs
+	Uses       []string // optional tags of phases whose outputs to use
+	Include    []string // optional file paths to prepend to the phase prompt
+	Pick       string   // "true" for fzf selector, "last" for latest file (implement only)
+	Agent      string   // optional agent backend override, e.g. "claude", or a fallback chain "claude,pi"
+	When       string   // optional condition; the phase is skipped when it evaluates false
+	OnFailure  string   // optional tag of the phase to jump to when this phase fails
+	Parallel   string   // optional group name; consecutive phases in the same group run concurrently
+	Model      string   // optional model override for this phase
+	Effort     string   // optional effort override for this phase
+	Autonomous string   // optional autonomous mode override: "true" or "false"
+	Timeout    string   // optional maximum run time, as a Go duration (e.g. "30m")
+	Outputs    []string // optional output contracts, one per outputs: line (see OutputSpec)
+	Approve    string   // "true" to pause for approval after the phase (adds an approve phase)
+	Line       int      // line of the phase heading in its playbook file
 }
 
 // Playbook represents a parsed playbook file
 type Playbook struct {
+	Frontmatter
+	File   string // path the playbook was read from; empty when parsed from a string
 	Phases []Phase
 }
 
 // validPhaseTypes maps normalized heading text to phase type
 var validPhaseTypes = map[string]string{
-	"research":     "research",
-	"plan":         "plan",
-	"implement":    "implement",
-	"new":          "new",
-	"fix":          "fix",
+	"research":           "research",
+	"plan":               "plan",
+	"implement":          "implement",
+	"new":                "new",
+	"fix":                "fix",
 	"fix-local-comments": "fix-local-comments",
-	"review":       "review",
-	"exit":         "exit",
-	"play":         "play",
+	"review":             "review",
+	"exit":               "exit",
+	"play":               "play",
+	"goto":               "goto",
+	"approve":            "approve",
+}
+
+// validEfforts lists the effort levels accepted by the effort: metadata key.
+var validEfforts = map[string]bool{
+	"low":    true,
+	"normal": true,
+	"medium": true,
+	"high":   true,
+	"xhigh":  true,
+	"max":    true,
+}
+
+// phaseAgentTypes lists the phase types that run an agent session.
+var phaseAgentTypes = map[string]bool{
+	"research":           true,
+	"plan":               true,
+	"implement":          true,
+	"new":                true,
+	"fix":                true,
+	"fix-local-comments": true,
+	"review":             true,
+}
+
+// ParallelGroupStart returns the index of the first phase of the parallel group that the
+// phase at index i belongs to. For a phase outside any group it returns i.
+func (pb *Playbook) ParallelGroupStart(i int) int {
+	group := pb.Phases[i].Parallel
+	if group == "" {
+		return i
+	}
+	for i > 0 && pb.Phases[i-1].Parallel == group {
+		i--
+	}
+	return i
+}
+
+// ParallelGroupEnd returns the index just past the parallel group starting at index start.
+// For a phase outside any group it returns start+1.
+func (pb *Playbook) ParallelGroupEnd(start int) int {
+	group := pb.Phases[start].Parallel
+	end := start + 1
+	if group == "" {
+		return end
+	}
+	for end < len(pb.Phases) && pb.Phases[end].Parallel == group {
+		end++
+	}
+	return end
 }
 
 // Parse reads a playbook markdown file and extracts phases
 func Parse(path string) (*Playbook, error) {
+	return ParseWithParams(path, nil)
+}
+
+// ParseWithParams reads a playbook markdown file, substitutes parameter values
+// and extracts phases.
+func ParseWithParams(path string, values map[string]string) (*Playbook, error) {
+	return parseFile(path, values, false)
+}
+
+// ParseNested reads a playbook played from another playbook. It inherits the parent's
+// parameter values, even without declaring params itself, and its tag references are
+// not checked, since they may refer to phases of the playbooks around it.
+func ParseNested(path string, values map[string]string) (*Playbook, error) {
+	return parseFile(path, values, true)
+}
+
+func parseFile(path string, values map[string]string, nested bool) (*Playbook, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read playbook: %w", err)
 	}
 
-	return ParseContent(string(data))
+	pb, err := parseContent(string(data), values, nested)
+	if err != nil {
+		return nil, withFile(err, path)
+	}
+	pb.File = path
+	return pb, nil
 }
 
 // ParseContent parses playbook content from a string
 func ParseContent(content string) (*Playbook, error) {
+	return ParseContentWithParams(content, nil)
+}
+
+// ParseContentWithParams parses playbook content from a string. When the frontmatter
+// declares params, {{.name}} references in the body are replaced by the values
+// (falling back to the declared defaults) before phases are extracted, so they
+// apply to metadata as well as content. Values for undeclared names are ignored.
+func ParseContentWithParams(content string, values map[string]string) (*Playbook, error) {
+	return parseContent(content, values, false)
+}
+
+func parseContent(content string, values map[string]string, nested bool) (*Playbook, error) {
+	fm, body, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(fm.Params) > 0 || (nested && len(values) > 0) {
+		merged, missing := fm.Values(values)
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("missing value for playbook parameters: %s", strings.Join(missing, ", "))
+		}
+		if nested {
+			for name, v := range values {
+				if _, ok := merged[name]; !ok {
+					merged[name] = v
+				}
+			}
+		}
+		if body, err = render(body, merged); err != nil {
+			return nil, err
+		}
+	}
+
+	pb, err := parsePhases(body, !nested)
+	if err != nil {
+		return nil, err
+	}
+	pb.Frontmatter = fm
+	return pb, nil
+}
+
+// parsePhases extracts and validates the phases of a playbook body, collecting every
+// problem found rather than stopping at the first. Tag references are checked when
+// checkTags is set and the playbook plays no other playbooks; otherwise they are only
+// known once nested playbooks are expanded.
+func parsePhases(content string, checkTags bool) (*Playbook, error) {
 	lines := strings.Split(content, "\n")
 
+	var errs Errors
 	var phases []Phase
 	var currentType string
+	var currentLine int
 	var currentLines []string
 
-	for _, line := range lines {
+	for n, line := range lines {
 		if strings.HasPrefix(line, "## ") {
 			// Save previous phase if any
 			if currentType != "" {
-				tag, uses, include, pick, agentVal, rest := extractMetadata(currentLines)
-				phases = append(phases, Phase{
-					Type:    currentType,
-					Content: strings.TrimSpace(strings.Join(rest, "\n")),
-					Tag:     tag,
-					Uses:    uses,
-					Include: include,
-					Pick:    pick,
-					Agent:   agentVal,
-				})
+				phases = append(phases, newPhase(currentType, currentLine, currentLines))
 			}
 
 			// Parse heading
@@ -75,10 +202,11 @@ func ParseContent(content string) (*Playbook, error) {
 
 			phaseType, ok := validPhaseTypes[normalized]
 			if !ok {
-				return nil, fmt.Errorf("unknown phase type: %q (valid: research, plan, implement, new, fix, fix-local-comments, review, play, exit)", heading)
+				errs.add(n+1, "unknown phase type: %q (valid: research, plan, implement, new, fix, fix-local-comments, review, play, goto, approve, exit)", heading)
 			}
 
 			currentType = phaseType
+			currentLine = n + 1
 			currentLines = nil
 			continue
 		}
@@ -90,20 +218,14 @@ func ParseContent(content string) (*Playbook, error) {
 
 	// Save last phase
 	if currentType != "" {
-		tag, uses, include, pick, agentVal, rest := extractMetadata(currentLines)
-		phases = append(phases, Phase{
-			Type:    currentType,
-			Content: strings.TrimSpace(strings.Join(rest, "\n")),
-			Tag:     tag,
-			Uses:    uses,
-			Include: include,
-			Pick:    pick,
-			Agent:   agentVal,
-		})
+		phases = append(phases, newPhase(currentType, currentLine, currentLines))
 	}
 
 	if len(phases) == 0 {
-		return nil, fmt.Errorf("no phases found in playbook (use ## Research, ## Plan, ## Implement headings)")
+		if len(errs) == 0 {
+			errs.add(0, "no phases found in playbook (use ## Research, ## Plan, ## Implement headings)")
+		}
+		return nil, errs
 	}
 
 	// Validate unique tags
@@ -111,30 +233,75 @@ func ParseContent(content string) (*Playbook, error) {
 	for _, p := range phases {
 		if p.Tag != "" {
 			if seen[p.Tag] {
-				return nil, fmt.Errorf("duplicate phase tag: %q", p.Tag)
+				errs.add(p.Line, "duplicate phase tag: %q", p.Tag)
 			}
 			seen[p.Tag] = true
 		}
 	}
 
+	hasPlay := false
+	for _, p := range phases {
+		if p.Type == "play" {
+			hasPlay = true
+		}
+	}
+
 	// Validate pick is only used on implement phases with valid values
 	for i, p := range phases {
 		if p.Pick == "" {
 			continue
 		}
 		if p.Type != "implement" {
-			return nil, fmt.Errorf("phase %d (%s): pick is only valid on implement phases", i+1, p.Type)
-		}
-		if p.Pick != "true" && p.Pick != "last" {
-			return nil, fmt.Errorf("phase %d (implement): invalid pick value %q (valid: true, last)", i+1, p.Pick)
+			errs.add(p.Line, "phase %d (%s): pick is only valid on implement phases", i+1, p.Type)
+		} else if p.Pick != "true" && p.Pick != "last" {
+			errs.add(p.Line, "phase %d (implement): invalid pick value %q (valid: true, last)", i+1, p.Pick)
 		}
 	}
 
 	// Validate agent values
-	validAgents := map[string]bool{"claude": true, "codex": true, "amp": true, "pi": true}
+	validAgents := make(map[string]bool)
+	for _, name := range config.ValidAgents() {
+		validAgents[name] = true
+	}
+	for i, p := range phases {
+		if p.Agent == "" {
+			continue
+		}
+		for _, name := range strings.Split(p.Agent, ",") {
+			if !validAgents[name] {
+				errs.add(p.Line, "phase %d (%s): unknown agent %q (valid: %s)", i+1, p.Type, name, config.ValidAgentsText())
+			}
+		}
+	}
+
+	// Validate model, effort, autonomous and timeout overrides
+	for i, p := range phases {
+		if p.Model != "" && strings.ContainsAny(p.Model, " \t") {
+			errs.add(p.Line, "phase %d (%s): invalid model %q", i+1, p.Type, p.Model)
+		}
+		if p.Effort != "" && !validEfforts[p.Effort] {
+			errs.add(p.Line, "phase %d (%s): invalid effort %q (valid: low, normal, medium, high, xhigh, max)", i+1, p.Type, p.Effort)
+		}
+		if p.Autonomous != "" && p.Autonomous != "true" && p.Autonomous != "false" {
+			errs.add(p.Line, "phase %d (%s): invalid autonomous value %q (valid: true, false)", i+1, p.Type, p.Autonomous)
+		}
+		if p.Timeout != "" {
+			if d, err := time.ParseDuration(p.Timeout); err != nil || d <= 0 {
+				errs.add(p.Line, "phase %d (%s): invalid timeout %q (use a duration such as 30m or 1h30m)", i+1, p.Type, p.Timeout)
+			}
+		}
+	}
+
+	// Validate outputs: contracts, which only agent phases can fulfil
 	for i, p := range phases {
-		if p.Agent != "" && !validAgents[p.Agent] {
-			return nil, fmt.Errorf("phase %d (%s): unknown agent %q (valid: claude, codex, amp, pi)", i+1, p.Type, p.Agent)
+		if len(p.Outputs) > 0 && !phaseAgentTypes[p.Type] {
+			errs.add(p.Line, "phase %d (%s): outputs is only valid on agent phases", i+1, p.Type)
+			continue
+		}
+		for _, raw := range p.Outputs {
+			if _, err := ParseOutputSpec(raw); err != nil {
+				errs.add(p.Line, "phase %d (%s): outputs: %v", i+1, p.Type, err)
+			}
 		}
 	}
 
@@ -143,24 +310,126 @@ func ParseContent(content string) (*Playbook, error) {
 		if p.Type != "play" {
 			continue
 		}
-		if p.Tag != "" || len(p.Uses) > 0 || len(p.Include) > 0 || p.Agent != "" {
-			return nil, fmt.Errorf("phase %d (play): metadata (tag/uses/include/agent) not allowed on play phases", i+1)
+		if p.Tag != "" || len(p.Uses) > 0 || len(p.Include) > 0 || p.Agent != "" || p.OnFailure != "" || hasRunOverrides(p) {
+			errs.add(p.Line, "phase %d (play): metadata (tag/uses/include/agent/on-failure/model/effort/autonomous/timeout) not allowed on play phases", i+1)
 		}
 		if p.Content == "" {
-			return nil, fmt.Errorf("phase %d (play): missing playbook file path", i+1)
+			errs.add(p.Line, "phase %d (play): missing playbook file path", i+1)
+			continue
 		}
 		if strings.Contains(p.Content, "\n") {
-			return nil, fmt.Errorf("phase %d (play): content must be a single file path, got multiple lines", i+1)
+			errs.add(p.Line, "phase %d (play): content must be a single file path, got multiple lines", i+1)
+			continue
 		}
 		if !strings.HasSuffix(p.Content, ".md") {
-			return nil, fmt.Errorf("phase %d (play): file must be a .md file: %s", i+1, p.Content)
+			errs.add(p.Line, "phase %d (play): file must be a .md file: %s", i+1, p.Content)
+			continue
 		}
 		info, err := os.Stat(p.Content)
 		if err != nil {
-			return nil, fmt.Errorf("phase %d (play): file not found: %s", i+1, p.Content)
+			errs.add(p.Line, "phase %d (play): file not found: %s", i+1, p.Content)
+		} else if info.IsDir() {
+			errs.add(p.Line, "phase %d (play): path is a directory: %s", i+1, p.Content)
+		}
+	}
+
+	// Validate when: conditions
+	for i, p := range phases {
+		if p.When == "" {
+			continue
 		}
-		if info.IsDir() {
-			return nil, fmt.Errorf("phase %d (play): path is a directory: %s", i+1, p.Content)
+		if _, err := ParseCondition(p.When); err != nil {
+			errs.add(p.Line, "phase %d (%s): invalid when: %v", i+1, p.Type, err)
+		}
+	}
+
+	// Validate goto phases: content is the target tag, only when: is allowed as metadata
+	for i, p := range phases {
+		if p.Type == "goto" {
+			if p.Tag != "" || len(p.Uses) > 0 || len(p.Include) > 0 || p.Agent != "" || p.OnFailure != "" || hasRunOverrides(p) {
+				errs.add(p.Line, "phase %d (goto): only when: metadata is allowed on goto phases", i+1)
+			}
+			if p.Content == "" {
+				errs.add(p.Line, "phase %d (goto): missing target tag", i+1)
+				continue
+			}
+			if strings.ContainsAny(p.Content, " \n") {
+				errs.add(p.Line, "phase %d (goto): content must be a single tag, got %q", i+1, p.Content)
+			}
+		}
+	}
+
+	// Validate approval gates: approve phases review an earlier agent phase and only take
+	// when: metadata; approve: true is for sequential agent phases
+	for i, p := range phases {
+		if p.Approve != "" {
+			switch {
+			case p.Approve != "true" && p.Approve != "false":
+				errs.add(p.Line, "phase %d (%s): invalid approve value %q (valid: true, false)", i+1, p.Type, p.Approve)
+			case !phaseAgentTypes[p.Type]:
+				errs.add(p.Line, "phase %d (%s): approve is only valid on agent phases", i+1, p.Type)
+			case p.Parallel != "":
+				errs.add(p.Line, "phase %d (%s): approve is not supported on parallel phases; add an approve phase after the group", i+1, p.Type)
+			}
+		}
+		if p.Type != "approve" {
+			continue
+		}
+		if p.Tag != "" || len(p.Uses) > 0 || len(p.Include) > 0 || p.Agent != "" || p.OnFailure != "" ||
+			p.Parallel != "" || len(p.Outputs) > 0 || p.Approve != "" || hasRunOverrides(p) {
+			errs.add(p.Line, "phase %d (approve): only when: metadata is allowed on approve phases", i+1)
+		}
+		if checkTags && !hasPlay && PreviousAgentPhase(phases, i) < 0 {
+			errs.add(p.Line, "phase %d (approve): no earlier agent phase to approve", i+1)
+		}
+	}
+
+	if checkTags && !hasPlay {
+		errs = append(errs, checkRefs(phases, nil)...)
+	}
+
+	// Validate parallel groups: contiguous, agent phases only, nothing interactive or jumping
+	groupEnd := make(map[string]int)
+	for i, p := range phases {
+		if p.Parallel == "" {
+			continue
+		}
+		if _, ok := phaseAgentTypes[p.Type]; !ok {
+			errs.add(p.Line, "phase %d (%s): parallel is only valid on agent phases", i+1, p.Type)
+		}
+		if p.Pick == "true" {
+			errs.add(p.Line, "phase %d (%s): pick: true is interactive and cannot run in parallel", i+1, p.Type)
+		}
+		if p.OnFailure != "" {
+			errs.add(p.Line, "phase %d (%s): on-failure is not supported on parallel phases", i+1, p.Type)
+		}
+		if end, ok := groupEnd[p.Parallel]; ok && end != i-1 {
+			errs.add(p.Line, "phase %d (%s): parallel group %q must be contiguous", i+1, p.Type, p.Parallel)
+		}
+		groupEnd[p.Parallel] = i
+	}
+	for i, p := range phases {
+		if p.Parallel == "" {
+			continue
+		}
+		for _, ref := range p.Uses {
+			for j := i - 1; j >= 0 && phases[j].Parallel == p.Parallel; j-- {
+				if phases[j].Tag == ref {
+					errs.add(p.Line, "phase %d (%s): uses %q from the same parallel group", i+1, p.Type, ref)
+				}
+			}
+		}
+		// Branches are checked against the files they find afterwards, so a file matching
+		// two branches' outputs: could be credited to the one that did not write it
+		for _, spec := range ParseOutputSpecs(p) {
+			for j := i - 1; j >= 0 && phases[j].Parallel == p.Parallel; j-- {
+				for _, other := range ParseOutputSpecs(phases[j]) {
+					if globsOverlap(spec.Glob, other.Glob) {
+						errs.add(p.Line, "phase %d (%s): outputs %s overlaps outputs %s of phase %d in the same parallel group",
+							i+1, p.Type, spec.Glob, other.Glob, j+1)
+					}
+				}
+			}
 		}
 	}
 
@@ -169,20 +438,123 @@ func ParseContent(content string) (*Playbook, error) {
 		for _, f := range p.Include {
 			info, err := os.Stat(f)
 			if err != nil {
-				return nil, fmt.Errorf("phase %d include file not found: %s", i+1, f)
+				errs.add(p.Line, "phase %d include file not found: %s", i+1, f)
+			} else if info.IsDir() {
+				errs.add(p.Line, "phase %d include path is a directory: %s", i+1, f)
 			}
-			if info.IsDir() {
-				return nil, fmt.Errorf("phase %d include path is a directory: %s", i+1, f)
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(a, b int) bool { return errs[a].Line < errs[b].Line })
+		return nil, errs
+	}
+	return &Playbook{Phases: insertApprovals(phases)}, nil
+}
+
+// insertApprovals adds an approve phase after every phase with approve: true.
+func insertApprovals(phases []Phase) []Phase {
+	out := make([]Phase, 0, len(phases))
+	for _, p := range phases {
+		out = append(out, p)
+		if p.Approve == "true" {
+			out = append(out, Phase{Type: "approve", Line: p.Line})
+		}
+	}
+	return out
+}
+
+// PreviousAgentPhase returns the index of the nearest agent phase before index i, or -1.
+func PreviousAgentPhase(phases []Phase, i int) int {
+	for j := i - 1; j >= 0; j-- {
+		if phaseAgentTypes[phases[j].Type] {
+			return j
+		}
+	}
+	return -1
+}
+
+// ReviewedPhases returns the phases [start, end) an approve phase at index i reviews: the
+// nearest agent phase before it, or the whole parallel group that phase belongs to.
+// start is -1 if there is no earlier agent phase.
+func ReviewedPhases(phases []Phase, i int) (start, end int) {
+	j := PreviousAgentPhase(phases, i)
+	if j < 0 {
+		return -1, -1
+	}
+	start, end = j, j+1
+	if group := phases[j].Parallel; group != "" {
+		for start > 0 && phases[start-1].Parallel == group {
+			start--
+		}
+	}
+	return start, end
+}
+
+// checkRefs checks that the tags referenced by uses:, when:, goto and on-failure are
+// defined by some phase. files, if non-nil, holds the file each phase was read from.
+func checkRefs(phases []Phase, files []string) Errors {
+	var errs Errors
+	report := func(i int, format string, args ...any) {
+		e := &Error{Line: phases[i].Line, Msg: fmt.Sprintf(format, args...)}
+		if files != nil {
+			e.File = files[i]
+		}
+		errs = append(errs, e)
+	}
+
+	tags := make(map[string]bool)
+	for _, p := range phases {
+		if p.Tag != "" {
+			tags[p.Tag] = true
+		}
+	}
+	for i, p := range phases {
+		for _, ref := range p.Uses {
+			if !tags[ref] {
+				report(i, "phase %d (%s): uses unknown tag %q", i+1, p.Type, ref)
 			}
 		}
+		if p.When != "" {
+			if cond, err := ParseCondition(p.When); err == nil {
+				switch cond.Kind {
+				case "captured", "contains", "succeeded", "failed":
+					if cond.Arg != PreviousPhase && !tags[cond.Arg] {
+						report(i, "phase %d (%s): when: refers to unknown tag %q", i+1, p.Type, cond.Arg)
+					}
+				}
+			}
+		}
+		target := p.OnFailure
+		if p.Type == "goto" {
+			target = p.Content
+		}
+		if target != "" && !tags[target] {
+			report(i, "phase %d (%s): unknown jump target tag %q", i+1, p.Type, target)
+		}
 	}
+	return errs
+}
+
+// hasRunOverrides reports whether a phase sets model, effort, autonomous or timeout metadata.
+func hasRunOverrides(p Phase) bool {
+	return p.Model != "" || p.Effort != "" || p.Autonomous != "" || p.Timeout != ""
+}
 
-	return &Playbook{Phases: phases}, nil
+// newPhase builds a Phase of the given type from the body lines under its heading,
+// which is on the given line.
+func newPhase(phaseType string, line int, lines []string) Phase {
+	p, rest := extractMetadata(lines)
+	p.Type = phaseType
+	p.Line = line
+	p.Content = strings.TrimSpace(strings.Join(rest, "\n"))
+	return p
 }
 
-// extractMetadata parses tag:, uses:, include:, pick:, and agent: lines from the top of phase body lines.
-// Returns tag, uses, include, pick, agentVal, and remaining content lines with metadata stripped.
-func extractMetadata(lines []string) (tag string, uses []string, include []string, pick string, agentVal string, rest []string) {
+// extractMetadata parses tag:, uses:, include:, pick:, agent:, when:, on-failure:, parallel:,
+// model:, effort:, autonomous:, timeout:, outputs: and approve: lines from the top of phase body lines.
+// Returns a Phase with the metadata fields set, and the remaining content lines with metadata stripped.
+func extractMetadata(lines []string) (p Phase, rest []string) {
 	i := 0
 	for i < len(lines) {
 		trimmed := strings.TrimSpace(lines[i])
@@ -192,29 +564,17 @@ func extractMetadata(lines []string) (tag string, uses []string, include []strin
 		}
 		lower := strings.ToLower(trimmed)
 		if strings.HasPrefix(lower, "tag:") {
-			tag = strings.TrimSpace(trimmed[4:])
+			p.Tag = strings.TrimSpace(trimmed[4:])
 			i++
 			continue
 		}
 		if strings.HasPrefix(lower, "uses:") {
-			raw := strings.TrimSpace(trimmed[5:])
-			for _, u := range strings.Split(raw, ",") {
-				u = strings.TrimSpace(u)
-				if u != "" {
-					uses = append(uses, u)
-				}
-			}
+			p.Uses = splitList(trimmed[5:])
 			i++
 			continue
 		}
 		if strings.HasPrefix(lower, "include:") {
-			raw := strings.TrimSpace(trimmed[8:])
-			for _, f := range strings.Split(raw, ",") {
-				f = strings.TrimSpace(f)
-				if f != "" {
-					include = append(include, f)
-				}
-			}
+			p.Include = splitList(trimmed[8:])
 			i++
 			continue
 		}
@@ -222,17 +582,78 @@ func extractMetadata(lines []string) (tag string, uses []string, include []strin
 			val := strings.TrimSpace(strings.ToLower(trimmed[5:]))
 			switch val {
 			case "true", "yes", "1":
-				pick = "true"
+				p.Pick = "true"
 			case "last":
-				pick = "last"
+				p.Pick = "last"
 			default:
-				pick = val
+				p.Pick = val
 			}
 			i++
 			continue
 		}
 		if strings.HasPrefix(lower, "agent:") {
-			agentVal = strings.TrimSpace(strings.ToLower(trimmed[6:]))
+			p.Agent = strings.Join(splitList(strings.ToLower(trimmed[6:])), ",")
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "when:") {
+			p.When = strings.TrimSpace(trimmed[5:])
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "on-failure:") {
+			p.OnFailure = strings.TrimSpace(trimmed[11:])
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "parallel:") {
+			p.Parallel = strings.TrimSpace(trimmed[9:])
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "model:") {
+			p.Model = strings.TrimSpace(trimmed[6:])
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "effort:") {
+			p.Effort = strings.TrimSpace(strings.ToLower(trimmed[7:]))
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "autonomous:") {
+			val := strings.TrimSpace(strings.ToLower(trimmed[11:]))
+			switch val {
+			case "true", "yes", "1":
+				p.Autonomous = "true"
+			case "false", "no", "0":
+				p.Autonomous = "false"
+			default:
+				p.Autonomous = val
+			}
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "timeout:") {
+			p.Timeout = strings.TrimSpace(trimmed[8:])
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "approve:") {
+			val := strings.TrimSpace(strings.ToLower(trimmed[8:]))
+			switch val {
+			case "true", "yes", "1":
+				p.Approve = "true"
+			case "false", "no", "0":
+				p.Approve = "false"
+			default:
+				p.Approve = val
+			}
+			i++
+			continue
+		}
+		if strings.HasPrefix(lower, "outputs:") {
+			p.Outputs = append(p.Outputs, strings.TrimSpace(trimmed[8:]))
 			i++
 			continue
 		}
@@ -241,3 +662,15 @@ func extractMetadata(lines []string) (tag string, uses []string, include []strin
 	rest = lines[i:]
 	return
 }
+
+// splitList splits a comma-separated metadata value, dropping empty items.
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
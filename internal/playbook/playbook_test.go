@@ -1,7 +1,10 @@
 package playbook
 
 import (
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 )
 
@@ -258,6 +261,18 @@ Explore the codebase.
 			content: "## Research\nagent: gemini\nExplore\n",
 			wantErr: true,
 		},
+		{
+			name:    "phase with agent fallback chain",
+			content: "## Research\nagent: Claude, pi ,codex\nExplore the codebase.\n",
+			want: []Phase{
+				{Type: "research", Content: "Explore the codebase.", Agent: "claude,pi,codex"},
+			},
+		},
+		{
+			name:    "invalid agent in fallback chain",
+			content: "## Research\nagent: claude, gemini\nExplore\n",
+			wantErr: true,
+		},
 		{
 			name: "exit phase terminates playbook",
 			content: `## Research
@@ -274,6 +289,192 @@ This should not run.
 				{Type: "implement", Content: "This should not run."},
 			},
 		},
+		{
+			name: "when and on-failure metadata",
+			content: `## Plan
+tag: plan
+when: not exists thoughts/shared/plans/*.md
+Design it.
+
+## Implement
+tag: impl
+on-failure: plan
+Build it.
+`,
+			want: []Phase{
+				{Type: "plan", Content: "Design it.", Tag: "plan", When: "not exists thoughts/shared/plans/*.md"},
+				{Type: "implement", Content: "Build it.", Tag: "impl", OnFailure: "plan"},
+			},
+		},
+		{
+			name: "goto loop",
+			content: `## Implement
+tag: impl
+Build it.
+
+## Review
+tag: review
+
+## Goto
+when: not contains review LGTM
+impl
+`,
+			want: []Phase{
+				{Type: "implement", Content: "Build it.", Tag: "impl"},
+				{Type: "review", Content: "", Tag: "review"},
+				{Type: "goto", Content: "impl", When: "not contains review LGTM"},
+			},
+		},
+		{
+			name:    "invalid when condition",
+			content: "## Research\nwhen: sometimes maybe\nExplore\n",
+			wantErr: true,
+		},
+		{
+			name:    "goto without target",
+			content: "## Research\ntag: r\nExplore\n\n## Goto\n",
+			wantErr: true,
+		},
+		{
+			name:    "goto unknown tag",
+			content: "## Research\ntag: r\nExplore\n\n## Goto\nmissing\n",
+			wantErr: true,
+		},
+		{
+			name:    "goto with tag not allowed",
+			content: "## Research\ntag: r\nExplore\n\n## Goto\ntag: g\nr\n",
+			wantErr: true,
+		},
+		{
+			name:    "on-failure unknown tag",
+			content: "## Research\non-failure: nowhere\nExplore\n",
+			wantErr: true,
+		},
+		{
+			name:    "parallel research group",
+			content: "## Research\nparallel: scan\ntag: api\nAPI layer\n\n## Research\nparallel: scan\ntag: db\nDB layer\n\n## Plan\nuses: api, db\nDesign\n",
+			want: []Phase{
+				{Type: "research", Content: "API layer", Tag: "api", Parallel: "scan"},
+				{Type: "research", Content: "DB layer", Tag: "db", Parallel: "scan"},
+				{Type: "plan", Content: "Design", Uses: []string{"api", "db"}},
+			},
+		},
+		{
+			name:    "parallel group not contiguous",
+			content: "## Research\nparallel: scan\nA\n\n## Plan\nDesign\n\n## Research\nparallel: scan\nB\n",
+			wantErr: true,
+		},
+		{
+			name:    "parallel with pick true",
+			content: "## Implement\nparallel: build\npick: true\n\n## Implement\nparallel: build\nplan.md\n",
+			wantErr: true,
+		},
+		{
+			name:    "parallel uses tag from own group",
+			content: "## Research\nparallel: scan\ntag: api\nA\n\n## Plan\nparallel: scan\nuses: api\nDesign\n",
+			wantErr: true,
+		},
+		{
+			name:    "parallel outputs overlap",
+			content: "## Research\nparallel: scan\noutputs: docs/*.md\nA\n\n## Research\nparallel: scan\noutputs: docs/db-*.md\nB\n",
+			wantErr: true,
+		},
+		{
+			name:    "parallel outputs distinct",
+			content: "## Research\nparallel: scan\noutputs: docs/api-*.md\nA\n\n## Research\nparallel: scan\noutputs: docs/db-*.md\nB\n",
+			want: []Phase{
+				{Type: "research", Content: "A", Parallel: "scan", Outputs: []string{"docs/api-*.md"}},
+				{Type: "research", Content: "B", Parallel: "scan", Outputs: []string{"docs/db-*.md"}},
+			},
+		},
+		{
+			name:    "model, effort, autonomous and timeout overrides",
+			content: "## Research\nmodel: opus\neffort: MAX\ntimeout: 45m\nExplore\n\n## Implement\nmodel: sonnet\nautonomous: yes\nplan.md\n",
+			want: []Phase{
+				{Type: "research", Content: "Explore", Model: "opus", Effort: "max", Timeout: "45m"},
+				{Type: "implement", Content: "plan.md", Model: "sonnet", Autonomous: "true"},
+			},
+		},
+		{
+			name:    "invalid effort",
+			content: "## Research\neffort: extreme\nExplore\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid autonomous value",
+			content: "## Implement\nautonomous: maybe\nplan.md\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid timeout",
+			content: "## Research\ntimeout: soon\nExplore\n",
+			wantErr: true,
+		},
+		{
+			name:    "model not allowed on goto",
+			content: "## Research\ntag: r\nExplore\n\n## Goto\nmodel: opus\nr\n",
+			wantErr: true,
+		},
+		{
+			name:    "outputs",
+			content: "## Plan\noutputs: thoughts/shared/plans/*.md; sections=Overview\noutputs: docs/*.md; count=2\nDesign it\n",
+			want: []Phase{
+				{Type: "plan", Content: "Design it", Outputs: []string{"thoughts/shared/plans/*.md; sections=Overview", "docs/*.md; count=2"}},
+			},
+		},
+		{
+			name:    "invalid outputs",
+			content: "## Plan\noutputs: docs/*.md; count=none\nDesign it\n",
+			wantErr: true,
+		},
+		{
+			name:    "outputs on exit",
+			content: "## Research\nA\n\n## Exit\noutputs: docs/*.md\n",
+			wantErr: true,
+		},
+		{
+			name:    "approve phase",
+			content: "## Plan\nDesign it\n\n## Approve\nCheck the rollout section\n\n## Implement\nBuild it\n",
+			want: []Phase{
+				{Type: "plan", Content: "Design it"},
+				{Type: "approve", Content: "Check the rollout section"},
+				{Type: "implement", Content: "Build it"},
+			},
+		},
+		{
+			name:    "approve metadata adds an approve phase",
+			content: "## Plan\napprove: yes\nDesign it\n\n## Implement\nBuild it\n",
+			want: []Phase{
+				{Type: "plan", Content: "Design it"},
+				{Type: "approve"},
+				{Type: "implement", Content: "Build it"},
+			},
+		},
+		{
+			name:    "invalid approve value",
+			content: "## Plan\napprove: maybe\nDesign it\n",
+			wantErr: true,
+		},
+		{
+			name:    "approve before any agent phase",
+			content: "## Approve\n\n## Plan\nDesign it\n",
+			wantErr: true,
+		},
+		{
+			name:    "tag on approve phase",
+			content: "## Plan\nDesign it\n\n## Approve\ntag: gate\n",
+			wantErr: true,
+		},
+		{
+			name:    "approve metadata on parallel phase",
+			content: "## Research\nparallel: scan\napprove: true\nA\n\n## Research\nparallel: scan\nB\n",
+			wantErr: true,
+		},
+		{
+			name:    "parallel on goto",
+			content: "## Research\ntag: r\nA\n\n## Goto\nparallel: scan\nr\n",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -312,7 +513,127 @@ This should not run.
 				if phase.Agent != tt.want[i].Agent {
 					t.Errorf("phase %d: agent = %q, want %q", i, phase.Agent, tt.want[i].Agent)
 				}
+				if phase.When != tt.want[i].When {
+					t.Errorf("phase %d: when = %q, want %q", i, phase.When, tt.want[i].When)
+				}
+				if phase.OnFailure != tt.want[i].OnFailure {
+					t.Errorf("phase %d: on-failure = %q, want %q", i, phase.OnFailure, tt.want[i].OnFailure)
+				}
+				if phase.Parallel != tt.want[i].Parallel {
+					t.Errorf("phase %d: parallel = %q, want %q", i, phase.Parallel, tt.want[i].Parallel)
+				}
+				if phase.Model != tt.want[i].Model {
+					t.Errorf("phase %d: model = %q, want %q", i, phase.Model, tt.want[i].Model)
+				}
+				if phase.Effort != tt.want[i].Effort {
+					t.Errorf("phase %d: effort = %q, want %q", i, phase.Effort, tt.want[i].Effort)
+				}
+				if phase.Autonomous != tt.want[i].Autonomous {
+					t.Errorf("phase %d: autonomous = %q, want %q", i, phase.Autonomous, tt.want[i].Autonomous)
+				}
+				if phase.Timeout != tt.want[i].Timeout {
+					t.Errorf("phase %d: timeout = %q, want %q", i, phase.Timeout, tt.want[i].Timeout)
+				}
+				if !slices.Equal(phase.Outputs, tt.want[i].Outputs) {
+					t.Errorf("phase %d: outputs = %v, want %v", i, phase.Outputs, tt.want[i].Outputs)
+				}
+			}
+		})
+	}
+}
+
+func TestParseContentReportsAllErrors(t *testing.T) {
+	content := `## Research
+effort: extreme
+tag: a
+
+## Bogus
+text
+
+## Plan
+tag: a
+uses: missing
+`
+	_, err := ParseContent(content)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("error = %v (%T), want Errors", err, err)
+	}
+
+	var lines []int
+	for _, e := range errs {
+		lines = append(lines, e.Line)
+	}
+	// invalid effort, unknown heading, duplicate tag, unknown uses tag
+	if want := []int{1, 5, 8, 8}; !slices.Equal(lines, want) {
+		t.Errorf("error lines = %v, want %v\n%v", lines, want, err)
+	}
+}
+
+func TestParseWithParamsChecksRefs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unknown uses tag",
+			content: "## Research\ntag: notes\nExplore\n\n## Plan\nuses: nope\nDesign\n",
+			want:    `uses unknown tag "nope"`,
+		},
+		{
+			name:    "unknown jump target",
+			content: "## Research\non-failure: nowhere\nExplore\n",
+			want:    `unknown jump target tag "nowhere"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "playbook.md")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ParseWithParams(path, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
 			}
 		})
 	}
 }
+
+func TestReviewedPhases(t *testing.T) {
+	pb, err := ParseContent("## Research\nA\n\n## Approve\n\n## Research\nparallel: scan\nB\n\n## Research\nparallel: scan\nC\n\n## Approve\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	tests := []struct {
+		approve    int
+		start, end int
+	}{
+		{approve: 1, start: 0, end: 1},
+		{approve: 4, start: 2, end: 4},
+	}
+	for _, tt := range tests {
+		start, end := ReviewedPhases(pb.Phases, tt.approve)
+		if start != tt.start || end != tt.end {
+			t.Errorf("ReviewedPhases(%d) = [%d, %d), want [%d, %d)", tt.approve, start, end, tt.start, tt.end)
+		}
+	}
+	if start, _ := ReviewedPhases(pb.Phases, 0); start != -1 {
+		t.Errorf("ReviewedPhases(0) start = %d, want -1", start)
+	}
+}
+
+func TestParallelGroupBounds(t *testing.T) {
+	pb, err := ParseContent("## Research\nA\n\n## Research\nparallel: scan\nB\n\n## Research\nparallel: scan\nC\n\n## Plan\nD\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for i, want := range [][2]int{{0, 1}, {1, 3}, {1, 3}, {3, 4}} {
+		start := pb.ParallelGroupStart(i)
+		if got := [2]int{start, pb.ParallelGroupEnd(start)}; got != want {
+			t.Errorf("phase %d: group = %v, want %v", i, got, want)
+		}
+	}
+}
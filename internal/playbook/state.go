@@ -0,0 +1,124 @@
+package playbook
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Context is what earlier phases pass forward to later ones: the files they captured
+// and how they ended.
+type Context struct {
+	ResearchFiles []string            `json:"research_files"`
+	PlanFile      string              `json:"plan_file"`
+	TaggedFiles   map[string][]string `json:"tagged_files"`
+	PhaseStatus   map[int]string      `json:"phase_status"` // phase index → done, failed, skipped or rolled-back
+	PhaseFiles    map[int][]string    `json:"phase_files"`  // phase index → files captured by its last run
+	LastStatus    string              `json:"last_status"`  // status of the most recently run phase
+}
+
+// PhaseTime records when a phase last started and finished.
+type PhaseTime struct {
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+// Running reports whether the phase started and has not finished since.
+func (t PhaseTime) Running() bool {
+	return !t.Started.IsZero() && t.Finished.Before(t.Started)
+}
+
+// Elapsed returns how long the phase ran, measured up to now if it is still running.
+func (t PhaseTime) Elapsed(now time.Time) time.Duration {
+	switch {
+	case t.Started.IsZero():
+		return 0
+	case t.Running():
+		return now.Sub(t.Started)
+	}
+	return t.Finished.Sub(t.Started)
+}
+
+// State holds the state persisted between phases so a play session can be resumed.
+type State struct {
+	Context
+	NextPhase       int               `json:"next_phase"`
+	Phases          []Phase           `json:"phases"`
+	PhaseSessionIDs map[int]string    `json:"phase_session_ids"` // phase index → agent session ID
+	PhaseAgents     map[int]string    `json:"phase_agents"`      // phase index → backend its session ID belongs to
+	PhaseTimes      map[int]PhaseTime `json:"phase_times"`       // phase index → when it last ran
+	Jumps           map[int]int       `json:"jumps"`             // goto/on-failure phase index → jumps taken
+	ParallelDone    map[int]bool      `json:"parallel_done"`     // finished branches of the parallel group in progress
+	Params          map[string]string `json:"params"`            // playbook parameter values
+	Snapshots       map[int]Context   `json:"snapshots"`         // phase index → context the phase last started with
+}
+
+// DecodeState decodes a play session's saved state.
+func DecodeState(data string) (*State, error) {
+	var s State
+	if data == "" {
+		return &s, nil
+	}
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil, fmt.Errorf("decode play state: %w", err)
+	}
+	return &s, nil
+}
+
+// Clone returns a deep copy of the context.
+func (c Context) Clone() Context {
+	out := Context{
+		ResearchFiles: slices.Clone(c.ResearchFiles),
+		PlanFile:      c.PlanFile,
+		TaggedFiles:   make(map[string][]string, len(c.TaggedFiles)),
+		PhaseStatus:   make(map[int]string, len(c.PhaseStatus)),
+		PhaseFiles:    make(map[int][]string, len(c.PhaseFiles)),
+		LastStatus:    c.LastStatus,
+	}
+	for tag, files := range c.TaggedFiles {
+		out.TaggedFiles[tag] = slices.Clone(files)
+	}
+	for i, st := range c.PhaseStatus {
+		out.PhaseStatus[i] = st
+	}
+	for i, files := range c.PhaseFiles {
+		out.PhaseFiles[i] = slices.Clone(files)
+	}
+	return out
+}
+
+// RestoreSnapshot resets the context to what it was when phase i last started. Phase 0
+// always starts empty, and the next phase to run starts with the current context.
+func (s *State) RestoreSnapshot(i int) error {
+	if snap, ok := s.Snapshots[i]; ok {
+		s.Context = snap.Clone()
+		return nil
+	}
+	switch {
+	case i == 0:
+		s.Context = Context{}
+	case i == s.NextPhase:
+	default:
+		return fmt.Errorf("phase %d has no snapshot: it has not run in this session yet", i+1)
+	}
+	return nil
+}
+
+// StartPhase records that phase i is starting now.
+func (s *State) StartPhase(i int, now time.Time) {
+	if s.PhaseTimes == nil {
+		s.PhaseTimes = make(map[int]PhaseTime)
+	}
+	s.PhaseTimes[i] = PhaseTime{Started: now}
+}
+
+// FinishPhase records that phase i finished now.
+func (s *State) FinishPhase(i int, now time.Time) {
+	t, ok := s.PhaseTimes[i]
+	if !ok {
+		return
+	}
+	t.Finished = now
+	s.PhaseTimes[i] = t
+}
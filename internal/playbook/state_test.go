@@ -0,0 +1,109 @@
+package playbook
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRestoreSnapshot(t *testing.T) {
+	afterResearch := Context{
+		ResearchFiles: []string{"r1.md"},
+		TaggedFiles:   map[string][]string{"notes": {"r1.md"}},
+		PhaseStatus:   map[int]string{0: StatusDone},
+		LastStatus:    StatusDone,
+	}
+	state := State{
+		Context: Context{
+			ResearchFiles: []string{"r1.md"},
+			PlanFile:      "p1.md",
+			TaggedFiles:   map[string][]string{"notes": {"r1.md"}, "design": {"p1.md"}},
+			PhaseStatus:   map[int]string{0: StatusDone, 1: StatusDone},
+		},
+		NextPhase: 3,
+		Snapshots: map[int]Context{0: {}, 1: afterResearch},
+	}
+
+	t.Run("restores snapshot", func(t *testing.T) {
+		s := state
+		if err := s.RestoreSnapshot(1); err != nil {
+			t.Fatalf("RestoreSnapshot(1): %v", err)
+		}
+		if s.PlanFile != "" || len(s.TaggedFiles["design"]) != 0 {
+			t.Errorf("plan outputs not cleared: plan=%q design=%v", s.PlanFile, s.TaggedFiles["design"])
+		}
+		if !slices.Equal(s.TaggedFiles["notes"], []string{"r1.md"}) {
+			t.Errorf("notes = %v, want [r1.md]", s.TaggedFiles["notes"])
+		}
+
+		// The restored context is a copy: changing it must not alter the snapshot
+		s.TaggedFiles["notes"] = append(s.TaggedFiles["notes"], "r2.md")
+		if len(s.Snapshots[1].TaggedFiles["notes"]) != 1 {
+			t.Errorf("snapshot was modified through the restored context")
+		}
+	})
+
+	t.Run("next phase uses current context", func(t *testing.T) {
+		s := state
+		if err := s.RestoreSnapshot(3); err != nil {
+			t.Fatalf("RestoreSnapshot(3): %v", err)
+		}
+		if s.PlanFile != "p1.md" {
+			t.Errorf("plan = %q, want p1.md", s.PlanFile)
+		}
+	})
+
+	t.Run("phase not reached", func(t *testing.T) {
+		s := state
+		s.NextPhase = 2
+		if err := s.RestoreSnapshot(3); err == nil {
+			t.Fatal("expected error for a phase without a snapshot")
+		}
+	})
+}
+
+func TestDecodeStateLegacyJSON(t *testing.T) {
+	legacy := `{"next_phase":2,"research_files":["r.md"],"plan_file":"p.md","tagged_files":{"notes":["r.md"]}}`
+
+	state, err := DecodeState(legacy)
+	if err != nil {
+		t.Fatalf("DecodeState: %v", err)
+	}
+	if state.NextPhase != 2 || state.PlanFile != "p.md" || !slices.Equal(state.TaggedFiles["notes"], []string{"r.md"}) {
+		t.Errorf("decoded state = %+v", state)
+	}
+}
+
+func TestPhaseTimes(t *testing.T) {
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	var s State
+
+	s.FinishPhase(0, start) // no start recorded: ignored
+	if _, ok := s.PhaseTimes[0]; ok {
+		t.Fatal("FinishPhase recorded a phase that never started")
+	}
+
+	s.StartPhase(0, start)
+	pt := s.PhaseTimes[0]
+	if !pt.Running() {
+		t.Error("started phase should be running")
+	}
+	if got := pt.Elapsed(start.Add(90 * time.Second)); got != 90*time.Second {
+		t.Errorf("running Elapsed = %v, want 1m30s", got)
+	}
+
+	s.FinishPhase(0, start.Add(time.Minute))
+	pt = s.PhaseTimes[0]
+	if pt.Running() {
+		t.Error("finished phase should not be running")
+	}
+	if got := pt.Elapsed(start.Add(time.Hour)); got != time.Minute {
+		t.Errorf("finished Elapsed = %v, want 1m0s", got)
+	}
+
+	// Restarting clears the previous finish time
+	s.StartPhase(0, start.Add(2*time.Minute))
+	if !s.PhaseTimes[0].Running() {
+		t.Error("restarted phase should be running")
+	}
+}
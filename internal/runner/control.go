@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/tmux"
+)
+
+const (
+	// maxControlInput caps how much one client may send to a session.
+	maxControlInput = 64 * 1024
+	// controlTimeout bounds a control socket exchange.
+	controlTimeout = 5 * time.Second
+)
+
+// ErrNoInputRoute is returned by SendInput when a session has neither a control socket
+// nor a tmux pane to send to.
+var ErrNoInputRoute = errors.New("session has no control socket or tmux pane")
+
+// ControlSocketPath returns the path of the control socket of the session whose output
+// goes to outputFile. Text written to the socket is typed into the session's PTY.
+func ControlSocketPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, ".log") + ".sock"
+}
+
+// RemoveSessionFiles removes what a session whose output goes to outputFile may leave
+// next to it besides the log: its hooks log and a control socket left behind by a
+// crashed run.
+func RemoveSessionFiles(outputFile string) {
+	os.Remove(ControlSocketPath(outputFile)) //nolint:errcheck
+	os.Remove(HooksLogPath(outputFile))      //nolint:errcheck
+}
+
+// controlServer types what clients of a session's control socket send into its PTY.
+type controlServer struct {
+	ln   net.Listener
+	path string
+}
+
+// listenControl starts serving the control socket at path, writing input to pty.
+func listenControl(path string, pty io.Writer) (*controlServer, error) {
+	os.Remove(path) //nolint:errcheck // a stale socket from a crashed run
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on control socket: %w", err)
+	}
+	// Whoever can connect can type into the session
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("restrict control socket permissions: %w", err)
+	}
+	s := &controlServer{ln: ln, path: path}
+	go s.serve(pty)
+	return s, nil
+}
+
+// serve handles one client at a time: it reads until the client closes its side, types
+// the input and answers "ok", or answers "error: " and why without typing anything.
+func (s *controlServer) serve(pty io.Writer) {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.SetDeadline(time.Now().Add(controlTimeout)) //nolint:errcheck
+		input, err := io.ReadAll(io.LimitReader(conn, maxControlInput+1))
+		switch {
+		case err != nil || len(input) == 0:
+		case len(input) > maxControlInput:
+			fmt.Fprintf(conn, "error: input is longer than %d bytes\n", maxControlInput) //nolint:errcheck
+		default:
+			writeAll(pty, input)
+			io.WriteString(conn, "ok\n") //nolint:errcheck
+		}
+		conn.Close()
+	}
+}
+
+func (s *controlServer) close() {
+	s.ln.Close()
+	os.Remove(s.path) //nolint:errcheck
+}
+
+// SendInput types text into a running session, followed by Enter unless enter is false.
+// It goes through the session's control socket, or, for sessions without one, through
+// tmux send-keys to the session's pane.
+func SendInput(session *db.Session, text string, enter bool) error {
+	if session.OutputFile != "" {
+		err := sendControl(ControlSocketPath(session.OutputFile), controlInput(text, enter))
+		// A missing or dead socket means the session predates control sockets or is gone
+		if err == nil || !errors.Is(err, os.ErrNotExist) && !errors.Is(err, syscall.ECONNREFUSED) {
+			return err
+		}
+	}
+	if session.HasTmuxLocation() {
+		loc := tmux.Location{Session: session.TmuxSession, Window: session.TmuxWindow, Pane: session.TmuxPane}
+		return tmux.SendKeys(loc, text, enter)
+	}
+	return ErrNoInputRoute
+}
+
+// controlInput returns the bytes typed for text: line endings as typed, and a carriage
+// return for Enter.
+func controlInput(text string, enter bool) []byte {
+	if enter {
+		return formatInitialInput(text)
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return []byte(strings.ReplaceAll(text, "\r", "\n"))
+}
+
+// sendControl sends input to the control socket at path and waits for the session to
+// confirm it.
+func sendControl(path string, input []byte) error {
+	if len(input) > maxControlInput {
+		return fmt.Errorf("input is longer than %d bytes", maxControlInput)
+	}
+	conn, err := net.DialTimeout("unix", path, controlTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(controlTimeout)) //nolint:errcheck
+
+	if _, err := conn.Write(input); err != nil {
+		return fmt.Errorf("send to session: %w", err)
+	}
+	conn.(*net.UnixConn).CloseWrite() //nolint:errcheck
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return fmt.Errorf("read session reply: %w", err)
+	}
+	switch answer := strings.TrimSpace(string(reply)); {
+	case answer == "ok":
+	case strings.HasPrefix(answer, "error: "):
+		return fmt.Errorf("session refused the input: %s", strings.TrimPrefix(answer, "error: "))
+	default:
+		return fmt.Errorf("session did not accept the input")
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/db"
+)
+
+func TestSendInputControlSocket(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	b := &Base{db: database, outputDir: tmpDir}
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Execute(context.Background(), exec.Command("sh", "-c", `echo ready; read -r line; echo "got:$line"`), agent.RunOptions{
+			WorkflowType: db.WorkflowGeneral,
+			WorkingDir:   tmpDir,
+			Headless:     true,
+			SessionID:    "ctl-test",
+		})
+	}()
+
+	// Wait for the session to start listening
+	var session *db.Session
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		session, _ = database.GetSession("ctl-test")
+		if session != nil {
+			if _, err := os.Stat(ControlSocketPath(session.OutputFile)); err == nil {
+				break
+			}
+		}
+	}
+	if session == nil {
+		t.Fatal("session was not created")
+	}
+
+	if err := SendInput(session, "continue", true); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	out, _ := os.ReadFile(session.OutputFile)
+	if !strings.Contains(string(out), "got:continue") {
+		t.Errorf("session output = %q, want the sent line", out)
+	}
+	if _, err := os.Stat(ControlSocketPath(session.OutputFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("control socket left behind after the session ended")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to write from the control server's goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestControlServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s.sock")
+	var pty syncBuffer
+	s, err := listenControl(path, &pty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.close()
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("socket mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+
+	// Bypass sendControl's own length check to see what the server answers
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write(bytes.Repeat([]byte("x"), maxControlInput+1))
+	conn.(*net.UnixConn).CloseWrite()
+	reply, _ := io.ReadAll(conn)
+	conn.Close()
+	if !strings.HasPrefix(string(reply), "error: input is longer than") {
+		t.Errorf("reply to long input = %q, want an error", reply)
+	}
+	if err := sendControl(path, bytes.Repeat([]byte("x"), maxControlInput+1)); err == nil {
+		t.Error("sendControl() with long input: want an error")
+	}
+	if pty.String() != "" {
+		t.Errorf("long input was typed: %d bytes", len(pty.String()))
+	}
+
+	if err := sendControl(path, []byte("yes\r")); err != nil {
+		t.Fatalf("sendControl() error = %v", err)
+	}
+	if pty.String() != "yes\r" {
+		t.Errorf("typed %q, want %q", pty.String(), "yes\r")
+	}
+}
+
+func TestSendInputNoRoute(t *testing.T) {
+	session := &db.Session{ID: "gone", OutputFile: filepath.Join(t.TempDir(), "gone.log")}
+	if err := SendInput(session, "hello", true); !errors.Is(err, ErrNoInputRoute) {
+		t.Errorf("SendInput() error = %v, want ErrNoInputRoute", err)
+	}
+}
+
+func TestControlInput(t *testing.T) {
+	tests := []struct {
+		text  string
+		enter bool
+		want  string
+	}{
+		{text: "yes", enter: true, want: "yes\r"},
+		{text: "line 1\r\nline 2", enter: true, want: "line 1\nline 2\r"},
+		{text: "partial", enter: false, want: "partial"},
+	}
+	for _, tt := range tests {
+		if got := string(controlInput(tt.text, tt.enter)); got != tt.want {
+			t.Errorf("controlInput(%q, %v) = %q, want %q", tt.text, tt.enter, got, tt.want)
+		}
+	}
+}
+
+func TestControlSocketPath(t *testing.T) {
+	if got := ControlSocketPath("/out/ab12cd34.log"); got != "/out/ab12cd34.sock" {
+		t.Errorf("ControlSocketPath() = %q", got)
+	}
+}
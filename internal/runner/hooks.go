@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/hooks"
+)
+
+// HooksLogPath returns the path of the log that hooks of the session whose output goes to
+// outputFile write to while the agent has the terminal.
+func HooksLogPath(outputFile string) string {
+	return strings.TrimSuffix(outputFile, ".log") + ".hooks.log"
+}
+
+// preSession warns about venue hooks that will not run and runs the pre-session hooks,
+// unless they already ran for the session on another backend.
+func (b *Base) preSession(session *db.Session, opts agent.RunOptions) error {
+	if b.Hooks == nil || opts.PreSessionRan {
+		return nil
+	}
+	untrusted, err := b.Hooks.Untrusted(session.WorkingDirectory)
+	if err != nil {
+		return err
+	}
+	if len(untrusted) == 0 && !b.Hooks.Has(hooks.PreSession, session.WorkingDirectory) {
+		return nil
+	}
+	out := b.hookOutput(session, opts)
+	defer out.Close()
+
+	for _, hook := range untrusted {
+		fmt.Fprintf(out, "cmt: skipping untrusted hook %s (review it, then run: cmt hooks trust)\n", hook.Path)
+	}
+	if err := b.Hooks.Run(hooks.NewPayload(hooks.PreSession, session, opts.AutonomousMode), out); err != nil {
+		return fmt.Errorf("session refused: %w", err)
+	}
+	return nil
+}
+
+// postSession runs the post-session hooks with the session as it ended.
+func (b *Base) postSession(sessionID string, opts agent.RunOptions) {
+	if b.Hooks == nil {
+		return
+	}
+	session, err := b.db.GetSession(sessionID)
+	if err != nil || session == nil || !b.Hooks.Has(hooks.PostSession, session.WorkingDirectory) {
+		return
+	}
+	out := b.hookOutput(session, opts)
+	defer out.Close()
+	if err := b.Hooks.Run(hooks.NewPayload(hooks.PostSession, session, opts.AutonomousMode), out); err != nil {
+		fmt.Fprintf(out, "cmt: %v\n", err)
+	}
+}
+
+// backgroundHook starts the hooks of p's event, if there are any, without waiting for
+// them. Their output goes to the session's hooks log, so it does not mix with the
+// agent's screen.
+func (b *Base) backgroundHook(p hooks.Payload) {
+	if !b.Hooks.Has(p.Event, p.Session.Directory) {
+		return
+	}
+	go func() {
+		out, err := os.OpenFile(HooksLogPath(p.Session.OutputFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return
+		}
+		defer out.Close()
+		if err := b.Hooks.Run(p, out); err != nil {
+			fmt.Fprintf(out, "cmt: %v\n", err)
+		}
+	}()
+}
+
+// hookOutput returns where the output of hooks run before or after the agent goes: the
+// terminal, or for headless sessions, the session's hooks log.
+func (b *Base) hookOutput(session *db.Session, opts agent.RunOptions) io.WriteCloser {
+	if !opts.Headless {
+		return nopCloser{os.Stderr}
+	}
+	f, err := os.OpenFile(HooksLogPath(session.OutputFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nopCloser{io.Discard}
+	}
+	return f
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
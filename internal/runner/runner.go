@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -22,14 +23,13 @@ import (
 
 	"github.com/agentic-camerata/cmt/internal/agent"
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/hooks"
+	"github.com/agentic-camerata/cmt/internal/notify"
 	"github.com/agentic-camerata/cmt/internal/tmux"
 )
 
 var defaultCapturedFileRe = regexp.MustCompile(`(thoughts/shared/\S+\.md)`)
 
-// claudeSessionIDRe matches the Claude session ID in PTY output (e.g. "session_01ABC...")
-var claudeSessionIDRe = regexp.MustCompile(`session_([A-Za-z0-9]{10,})`)
-
 const (
 	// idleThreshold is how long without output before transitioning back to waiting
 	idleThreshold = 1 * time.Second
@@ -37,10 +37,44 @@ const (
 	autoTerminateThreshold = 5 * time.Second
 )
 
+// EnvSessionID is set for the agent to the ID of the session it runs in, so the tools it
+// runs, such as cmt mcp, know which session asks.
+const EnvSessionID = "CMT_SESSION_ID"
+
+// EnvAutonomous is set to "true" for an agent running without permission prompts, so cmt mcp
+// lets it start subsessions that skip them too. Other agents never inherit it. It is not
+// CMT_AUTONOMOUS, which would make every cmt the agent runs autonomous.
+const EnvAutonomous = "CMT_SESSION_AUTONOMOUS"
+
+// outputDrainTimeout is how long to wait for the rest of the output once the process exited.
+const outputDrainTimeout = 500 * time.Millisecond
+
+// sessionIDTailSize is how much recent output is searched for a session ID.
+const sessionIDTailSize = 4096
+
+// headlessSize is the fixed terminal size given to headless sessions, which have no
+// controlling terminal to inherit a size from.
+var headlessSize = &pty.Winsize{Rows: 40, Cols: 120}
+
 // Base provides PTY-based execution infrastructure for agent implementations.
 type Base struct {
 	db        *db.DB
 	outputDir string
+
+	// AutoTerminateAfter overrides how long an auto-terminating session must be idle
+	// before it is killed. Zero means autoTerminateThreshold.
+	AutoTerminateAfter time.Duration
+
+	// SessionIDs finds the backend's own session ID when the caller asks for it through
+	// RunOptions.CapturedSessionID. Nil means the backend's session ID is not captured.
+	SessionIDs agent.SessionIDSource
+
+	// Notifier is told when sessions start waiting for input and when they end. Nil
+	// means no notifications.
+	Notifier *notify.Notifier
+
+	// Hooks runs the user's scripts on session events. Nil means no hooks.
+	Hooks *hooks.Hooks
 }
 
 // NewBase creates a new Base runner, ensuring the output directory exists.
@@ -55,9 +89,20 @@ func NewBase(database *db.DB) (*Base, error) {
 		return nil, fmt.Errorf("create output directory: %w", err)
 	}
 
+	notifier, err := notify.Default()
+	if err != nil {
+		return nil, err
+	}
+	userHooks, err := hooks.Default()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Base{
 		db:        database,
 		outputDir: outputDir,
+		Notifier:  notifier,
+		Hooks:     userHooks,
 	}, nil
 }
 
@@ -93,7 +138,10 @@ func (b *Base) Execute(ctx context.Context, cmd *exec.Cmd, opts agent.RunOptions
 	}
 
 	// Create session record
-	sessionID := uuid.New().String()[:8]
+	sessionID := opts.SessionID
+	if sessionID == "" {
+		sessionID = uuid.New().String()[:8]
+	}
 	outputFile := filepath.Join(b.outputDir, sessionID+".log")
 
 	prefix := os.Getenv("CMT_PREFIX")
@@ -111,6 +159,8 @@ func (b *Base) Execute(ctx context.Context, cmd *exec.Cmd, opts agent.RunOptions
 		OutputFile:       outputFile,
 		LoopInterval:     opts.LoopInterval,
 		ParentID:         opts.ParentID,
+		Agent:            opts.Agent,
+		TodoID:           opts.TodoID,
 	}
 
 	if opts.ResumeSessionID != "" && opts.ResumeSessionID != "*" {
@@ -121,14 +171,59 @@ func (b *Base) Execute(ctx context.Context, cmd *exec.Cmd, opts agent.RunOptions
 		return fmt.Errorf("create session: %w", err)
 	}
 
+	// A failing pre-session hook refuses the session, which then never started
+	if err := b.preSession(session, opts); err != nil {
+		b.db.DeleteSession(sessionID) //nolint:errcheck
+		return err
+	}
+
+	// The agent's tools can tell which session they run in. Autonomy is never inherited
+	// from an autonomous parent: only a session run without prompts passes it on
+	env := slices.DeleteFunc(cmd.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, EnvAutonomous+"=")
+	})
+	cmd.Env = append(env, EnvSessionID+"="+sessionID)
+	if opts.AutonomousMode {
+		cmd.Env = append(cmd.Env, EnvAutonomous+"=true")
+	}
+
 	// Run with PTY capture
 	var autoTerminated bool
+	started := time.Now()
 	err = b.runWithPTY(ctx, cmd, session, opts, &autoTerminated)
 
+	// A backend that never got going leaves no session behind, so a fallback can take over
+	if errors.Is(err, agent.ErrBackendUnavailable) {
+		b.db.DeleteSession(sessionID) //nolint:errcheck
+		os.Remove(outputFile)         //nolint:errcheck
+		return err
+	}
+	defer b.postSession(sessionID, opts)
+
+	// Backends that keep their session ID on disk are only asked once the process is gone
+	if b.SessionIDs != nil && opts.CapturedSessionID != nil && *opts.CapturedSessionID == "" {
+		if sid := b.SessionIDs.AfterExit(workDir, started); sid != "" {
+			*opts.CapturedSessionID = sid
+			b.db.UpdateClaudeSessionID(sessionID, sid) //nolint:errcheck
+		}
+	}
+
+	// A cancelled context killed the process; report why instead of the kill signal
+	if ctx.Err() != nil {
+		b.db.UpdateSessionStatus(sessionID, db.StatusAbandoned)
+		event := notify.EventAbandoned
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			event = notify.EventBudget
+		}
+		b.Notifier.Notify(notify.ForSession(session, event, context.Cause(ctx).Error()))
+		return context.Cause(ctx)
+	}
+
 	// When auto-terminate kills the process, cmd.Wait() returns a "signal: killed" error
 	// which is expected and should be treated as successful completion
 	if err != nil && !(opts.AutoTerminate && isKilledError(err)) {
 		b.db.UpdateSessionStatus(sessionID, db.StatusAbandoned)
+		b.Notifier.Notify(notify.ForSession(session, notify.EventAbandoned, err.Error()))
 		return err
 	}
 
@@ -139,9 +234,38 @@ func (b *Base) Execute(ctx context.Context, cmd *exec.Cmd, opts agent.RunOptions
 	}
 
 	b.db.UpdateSessionStatus(sessionID, db.StatusCompleted)
+	if opts.CompleteTodo && opts.TodoID != "" {
+		b.completeTodo(opts.TodoID)
+	}
+	if b.isPlayPhase(session) {
+		// Play phases are reported by the play when it moves on
+		b.Notifier.Cancel(sessionID)
+	} else {
+		b.Notifier.Notify(notify.ForSession(session, notify.EventCompleted, ""))
+	}
 	return nil
 }
 
+// isPlayPhase reports whether session is a phase of a play, rather than a top-level
+// session or one another session started.
+func (b *Base) isPlayPhase(session *db.Session) bool {
+	if session.ParentID == "" {
+		return false
+	}
+	parent, err := b.db.GetSession(session.ParentID)
+	return err == nil && parent != nil && parent.WorkflowType == db.WorkflowPlay
+}
+
+// completeTodo marks the todo a session was started for as done.
+func (b *Base) completeTodo(id string) {
+	todo, err := b.db.GetTodo(id)
+	if err != nil || todo == nil || todo.Status == db.TodoStatusDone {
+		return
+	}
+	todo.Status = db.TodoStatusDone
+	b.db.UpdateTodo(todo) //nolint:errcheck
+}
+
 // activityMonitor tracks PTY output to detect working/waiting states
 type activityMonitor struct {
 	sessionID     string
@@ -150,33 +274,41 @@ type activityMonitor struct {
 	isWorking     bool
 	hasWorked     bool
 	autoTerminate bool
+	terminateIdle time.Duration // idle time before auto-terminate kills the process
 	terminated    bool
 	process       *os.Process
+	onWaiting     func() // called when the session goes from working to waiting
+	onWorking     func() // called when the session starts working
 	mu            sync.Mutex
 	done          chan struct{}
 }
 
 func newActivityMonitor(sessionID string, database *db.DB) *activityMonitor {
 	return &activityMonitor{
-		sessionID:  sessionID,
-		db:         database,
-		lastOutput: time.Now(),
-		isWorking:  false,
-		done:       make(chan struct{}),
+		sessionID:     sessionID,
+		db:            database,
+		lastOutput:    time.Now(),
+		isWorking:     false,
+		terminateIdle: autoTerminateThreshold,
+		done:          make(chan struct{}),
 	}
 }
 
 func (m *activityMonitor) onOutput() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	m.lastOutput = time.Now()
 	m.terminated = false
-	if !m.isWorking {
+	started := !m.isWorking
+	if started {
 		m.isWorking = true
 		m.hasWorked = true
 		m.db.UpdateSessionStatus(m.sessionID, db.StatusWorking)
 	}
+	m.mu.Unlock()
+
+	if started && m.onWorking != nil {
+		m.onWorking()
+	}
 }
 
 func (m *activityMonitor) start() {
@@ -191,15 +323,20 @@ func (m *activityMonitor) start() {
 			case <-ticker.C:
 				m.mu.Lock()
 				idle := time.Since(m.lastOutput)
-				if m.isWorking && idle > idleThreshold {
+				stopped := m.isWorking && idle > idleThreshold
+				if stopped {
 					m.isWorking = false
 					m.db.UpdateSessionStatus(m.sessionID, db.StatusWaiting)
 				}
-				if m.autoTerminate && m.hasWorked && !m.terminated && idle > autoTerminateThreshold && m.process != nil {
+				if m.autoTerminate && m.hasWorked && !m.terminated && idle > m.terminateIdle && m.process != nil {
 					m.terminated = true
 					m.process.Kill()
 				}
 				m.mu.Unlock()
+
+				if stopped && m.onWaiting != nil {
+					m.onWaiting()
+				}
 			}
 		}
 	}()
@@ -262,13 +399,30 @@ func (s *suspendState) setOldState(state interface{}) {
 
 // runWithPTY runs the command with a pseudo-terminal for interactive use.
 // If autoTerminated is non-nil, it is set to true when the activity monitor killed the process.
+// Headless sessions are not attached to the terminal: output only goes to the session log
+// and stdin is not forwarded.
 func (b *Base) runWithPTY(ctx context.Context, cmd *exec.Cmd, session *db.Session, opts agent.RunOptions, autoTerminated *bool) error {
-	ptmx, err := pty.Start(cmd)
+	var ptmx *os.File
+	var err error
+	if opts.Headless {
+		ptmx, err = pty.StartWithSize(cmd, headlessSize)
+	} else {
+		ptmx, err = pty.Start(cmd)
+	}
 	if err != nil {
+		if opts.StartupCheck != nil && errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("%w: %s is not installed", agent.ErrBackendUnavailable, filepath.Base(cmd.Path))
+		}
 		return fmt.Errorf("start pty: %w", err)
 	}
 	defer ptmx.Close()
 
+	var startup *startupWatch
+	if opts.StartupCheck != nil {
+		startup = newStartupWatch(*opts.StartupCheck, cmd.Process, opts.TaskDescription+"\n"+opts.InitialInput)
+		defer startup.stop()
+	}
+
 	var outFile *os.File
 	var monitor *activityMonitor
 	if session != nil {
@@ -278,7 +432,17 @@ func (b *Base) runWithPTY(ctx context.Context, cmd *exec.Cmd, session *db.Sessio
 
 		monitor = newActivityMonitor(session.ID, b.db)
 		monitor.autoTerminate = opts.AutoTerminate
+		if b.AutoTerminateAfter > 0 {
+			monitor.terminateIdle = b.AutoTerminateAfter
+		}
 		monitor.process = cmd.Process
+		monitor.onWorking = func() { b.Notifier.Cancel(session.ID) }
+		monitor.onWaiting = func() {
+			b.Notifier.Notify(notify.ForSession(session, notify.EventWaiting, ""))
+			payload := hooks.NewPayload(hooks.OnWaiting, session, opts.AutonomousMode)
+			payload.Session.Status = string(db.StatusWaiting)
+			b.backgroundHook(payload)
+		}
 		monitor.start()
 		defer monitor.stop()
 
@@ -288,6 +452,11 @@ func (b *Base) runWithPTY(ctx context.Context, cmd *exec.Cmd, session *db.Sessio
 			return fmt.Errorf("create output file: %w", err)
 		}
 		defer outFile.Close()
+
+		// Let `cmt send` type into the session from outside; it falls back to tmux without this
+		if control, err := listenControl(ControlSocketPath(session.OutputFile), ptmx); err == nil {
+			defer control.close()
+		}
 	}
 
 	ss := &suspendState{
@@ -295,76 +464,99 @@ func (b *Base) runWithPTY(ctx context.Context, cmd *exec.Cmd, session *db.Sessio
 		childPID: cmd.Process.Pid,
 	}
 
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGWINCH)
-	go func() {
-		for range ch {
-			if err := pty.InheritSize(os.Stdin, ptmx); err != nil {
-				// Ignore resize errors
+	if !opts.Headless {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGWINCH)
+		go func() {
+			for range ch {
+				if err := pty.InheritSize(os.Stdin, ptmx); err != nil {
+					// Ignore resize errors
+				}
 			}
-		}
-	}()
-	ch <- syscall.SIGWINCH
+		}()
+		ch <- syscall.SIGWINCH
 
-	sigCont := make(chan os.Signal, 1)
-	signal.Notify(sigCont, syscall.SIGCONT)
-	go func() {
-		for range sigCont {
-			ss.mu.Lock()
-			if ss.oldState == nil && isTerminal(os.Stdin.Fd()) {
-				ss.resume()
+		sigCont := make(chan os.Signal, 1)
+		signal.Notify(sigCont, syscall.SIGCONT)
+		go func() {
+			for range sigCont {
+				ss.mu.Lock()
+				if ss.oldState == nil && isTerminal(os.Stdin.Fd()) {
+					ss.resume()
+				}
+				ss.mu.Unlock()
 			}
-			ss.mu.Unlock()
-		}
-	}()
-	defer signal.Stop(sigCont)
+		}()
+		defer signal.Stop(sigCont)
 
-	if isTerminal(os.Stdin.Fd()) {
-		state, err := makeRaw(os.Stdin.Fd())
-		if err != nil {
-			return fmt.Errorf("set raw mode: %w", err)
+		if isTerminal(os.Stdin.Fd()) {
+			state, err := makeRaw(os.Stdin.Fd())
+			if err != nil {
+				return fmt.Errorf("set raw mode: %w", err)
+			}
+			ss.setOldState(state)
+			defer func() {
+				restoreTerminal(os.Stdin.Fd(), ss.getOldState())
+			}()
 		}
-		ss.setOldState(state)
-		defer func() {
-			restoreTerminal(os.Stdin.Fd(), ss.getOldState())
-		}()
 	}
 
 	done := make(chan struct{})
 	defer close(done)
 
 	capturedSeen := map[string]bool{}
+	captureSessionID := b.SessionIDs != nil && opts.CapturedSessionID != nil && session != nil
+	var sessionIDTail string // recent output, so an ID split across reads is still found
 
 	// PTY output -> stdout + file, with activity detection and file capture
+	outputDone := make(chan struct{})
 	go func() {
+		defer close(outputDone)
 		buf := make([]byte, 1024)
 		for {
 			n, err := ptmx.Read(buf)
 			if n > 0 {
-				writeAll(os.Stdout, buf[:n])
+				if !opts.Headless {
+					writeAll(os.Stdout, buf[:n])
+				}
 				if outFile != nil {
 					outFile.Write(buf[:n])
 				}
 				if monitor != nil {
 					monitor.onOutput()
 				}
+				if startup != nil {
+					startup.onOutput(buf[:n])
+				}
 
-				if opts.CapturedFiles != nil {
+				if opts.CapturedFiles != nil || session != nil {
 					re := defaultCapturedFileRe
 					if opts.CapturePattern != nil {
 						re = opts.CapturePattern
 					}
 					matches := re.FindAllString(string(buf[:n]), -1)
 					for _, m := range matches {
-						if !capturedSeen[m] {
-							capturedSeen[m] = true
+						if capturedSeen[m] {
+							continue
+						}
+						capturedSeen[m] = true
+						if opts.CapturedFiles != nil {
 							*opts.CapturedFiles = append(*opts.CapturedFiles, m)
 						}
+						if session != nil {
+							payload := hooks.NewPayload(hooks.OnCapture, session, opts.AutonomousMode)
+							payload.Session.Status = string(db.StatusWorking)
+							payload.File = m
+							b.backgroundHook(payload)
+						}
 					}
 				}
-				if opts.CapturedSessionID != nil && *opts.CapturedSessionID == "" && session != nil {
-					if m := claudeSessionIDRe.FindStringSubmatch(string(buf[:n])); len(m) > 1 {
-						sid := m[1]
+				if captureSessionID && *opts.CapturedSessionID == "" {
+					sessionIDTail += string(buf[:n])
+					if len(sessionIDTail) > sessionIDTailSize {
+						sessionIDTail = sessionIDTail[len(sessionIDTail)-sessionIDTailSize:]
+					}
+					if sid := b.SessionIDs.FromOutput(sessionIDTail); sid != "" {
 						*opts.CapturedSessionID = sid
 						b.db.UpdateClaudeSessionID(session.ID, sid) //nolint:errcheck
 					}
@@ -390,37 +582,34 @@ func (b *Base) runWithPTY(ctx context.Context, cmd *exec.Cmd, session *db.Sessio
 		}()
 	}
 
-	// stdin -> PTY (with Ctrl+Z interception)
+	// Kill the process when the context is cancelled (e.g. a playbook phase timeout)
 	go func() {
-		buf := make([]byte, 1024)
-		for {
-			n, err := os.Stdin.Read(buf)
-			if err != nil {
-				return
-			}
-			select {
-			case <-done:
-				return
-			default:
-			}
-			start := 0
-			for i := 0; i < n; i++ {
-				if buf[i] == 0x1a {
-					if i > start {
-						writeAll(ptmx, buf[start:i])
-					}
-					ss.suspend()
-					start = i + 1
-				}
-			}
-			if start < n {
-				writeAll(ptmx, buf[start:n])
-			}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			cmd.Process.Kill() //nolint:errcheck
 		}
 	}()
 
+	// stdin -> PTY (with Ctrl+Z interception)
+	if !opts.Headless {
+		go forwardStdin(ptmx, ss, done)
+	}
+
 	waitErr := cmd.Wait()
 
+	// Let the reader catch up with the last output, unless a leftover child keeps the PTY open
+	select {
+	case <-outputDone:
+	case <-time.After(outputDrainTimeout):
+	}
+
+	if startup != nil {
+		if reason := startup.failure(waitErr); reason != "" {
+			return fmt.Errorf("%w: %s %s", agent.ErrBackendUnavailable, filepath.Base(cmd.Path), reason)
+		}
+	}
+
 	if autoTerminated != nil && monitor != nil {
 		monitor.mu.Lock()
 		*autoTerminated = monitor.terminated
@@ -430,6 +619,36 @@ func (b *Base) runWithPTY(ctx context.Context, cmd *exec.Cmd, session *db.Sessio
 	return waitErr
 }
 
+// forwardStdin copies stdin to the PTY until done is closed, intercepting Ctrl+Z to
+// suspend the session.
+func forwardStdin(ptmx *os.File, ss *suspendState, done <-chan struct{}) {
+	buf := make([]byte, 1024)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		select {
+		case <-done:
+			return
+		default:
+		}
+		start := 0
+		for i := 0; i < n; i++ {
+			if buf[i] == 0x1a {
+				if i > start {
+					writeAll(ptmx, buf[start:i])
+				}
+				ss.suspend()
+				start = i + 1
+			}
+		}
+		if start < n {
+			writeAll(ptmx, buf[start:n])
+		}
+	}
+}
+
 func writeAll(w io.Writer, data []byte) {
 	for len(data) > 0 {
 		n, err := w.Write(data)
@@ -446,6 +665,18 @@ func formatInitialInput(input string) []byte {
 	return append([]byte(input), '\r')
 }
 
+// isSignaledError reports whether err is the exit of a process ended by any signal,
+// such as the interrupt of a user pressing Ctrl+C.
+func isSignaledError(err error) bool {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.Signaled()
+		}
+	}
+	return false
+}
+
 func isKilledError(err error) bool {
 	var exitErr *exec.ExitError
 	if errors.As(err, &exitErr) {
@@ -1,11 +1,22 @@
 package runner
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/agentic-camerata/cmt/internal/agent"
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/hooks"
+	"github.com/agentic-camerata/cmt/internal/notify"
 )
 
 func TestFormatInitialInput(t *testing.T) {
@@ -134,3 +145,409 @@ func TestActivityMonitor(t *testing.T) {
 		}
 	})
 }
+
+func TestExecuteContextCancel(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	b := &Base{db: database, outputDir: tmpDir}
+	cause := errors.New("timed out after 200ms")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 200*time.Millisecond, cause)
+	defer cancel()
+
+	start := time.Now()
+	err = b.Execute(ctx, exec.Command("sleep", "10"), agent.RunOptions{
+		WorkflowType: db.WorkflowGeneral,
+		WorkingDir:   tmpDir,
+		Headless:     true,
+	})
+	if !errors.Is(err, cause) {
+		t.Fatalf("Execute() error = %v, want %v", err, cause)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Execute() took %s, process was not killed", elapsed)
+	}
+
+	sessions, err := database.ListSessions(db.StatusAbandoned)
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("got %d abandoned sessions, want 1", len(sessions))
+	}
+}
+
+func TestExecuteStartupCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	b := &Base{db: database, outputDir: tmpDir}
+	check := &agent.StartupCheck{
+		FirstOutputTimeout: 300 * time.Millisecond,
+		Window:             2 * time.Second,
+		ErrorPatterns:      agent.StartupErrorPatterns,
+	}
+
+	tests := []struct {
+		name            string
+		cmd             *exec.Cmd
+		task            string
+		wantUnavailable bool
+		wantErr         bool
+	}{
+		{name: "not installed", cmd: exec.Command("cmt-no-such-agent"), wantUnavailable: true},
+		{name: "fails before printing", cmd: exec.Command("sh", "-c", "exit 1"), wantUnavailable: true},
+		{name: "reports a rate limit", cmd: exec.Command("sh", "-c", "echo 'API Error: 429 rate limited'; sleep 10"), wantUnavailable: true},
+		{name: "shell cannot find the agent", cmd: exec.Command("sh", "-c", "echo 'run.sh: line 3: claude: command not found'; sleep 10"), wantUnavailable: true},
+		{name: "prints nothing", cmd: exec.Command("sleep", "10"), wantUnavailable: true},
+		{name: "starts fine", cmd: exec.Command("sh", "-c", "echo working; sleep 0.1")},
+		{name: "quit after starting", cmd: exec.Command("sh", "-c", "echo working; exit 1"), wantErr: true},
+		{name: "interrupted before printing", cmd: exec.Command("sh", "-c", "kill -INT $$"), wantErr: true},
+		{
+			name: "echoes a task that mentions an error",
+			cmd:  exec.Command("sh", "-c", "printf '> fix the\\r\\n  API Error: 429 handling\\r\\n'; sleep 0.1"),
+			task: "Fix the API Error: 429 handling",
+		},
+		{name: "error after the UI is shown", cmd: exec.Command("sh", "-c", "printf '\\033[?1049hAPI Error: 429'; sleep 0.1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := database.ListSessions("")
+			err := b.Execute(context.Background(), tt.cmd, agent.RunOptions{
+				WorkflowType:    db.WorkflowGeneral,
+				WorkingDir:      tmpDir,
+				TaskDescription: tt.task,
+				Headless:        true,
+				Agent:           "test",
+				StartupCheck:    check,
+			})
+			if got := errors.Is(err, agent.ErrBackendUnavailable); got != tt.wantUnavailable {
+				t.Fatalf("Execute() error = %v, want unavailable %v", err, tt.wantUnavailable)
+			}
+
+			after, _ := database.ListSessions("")
+			if tt.wantUnavailable {
+				if len(after) != len(before) {
+					t.Errorf("unavailable backend left a session behind")
+				}
+				return
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			wantStatus := db.StatusCompleted
+			if tt.wantErr {
+				wantStatus = db.StatusAbandoned
+			}
+			if len(after) != len(before)+1 || after[0].Agent != "test" || after[0].Status != wantStatus {
+				t.Errorf("sessions = %+v, want one %s session run by test", after, wantStatus)
+			}
+		})
+	}
+}
+
+func TestExecuteCapturesSessionID(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	sessionDir := filepath.Join(tmpDir, "sessions")
+	tests := []struct {
+		name string
+		cmd  *exec.Cmd
+		want string
+	}{
+		{name: "from output", cmd: exec.Command("sh", "-c", `echo '{"session_id":"out-123"}'`), want: "out-123"},
+		{name: "from session file", cmd: exec.Command("sh", "-c", "mkdir -p "+sessionDir+" && echo '{}' > "+sessionDir+"/file-456.jsonl"), want: "file-456"},
+		{name: "not found", cmd: exec.Command("true"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.RemoveAll(sessionDir)
+			b := &Base{db: database, outputDir: tmpDir, SessionIDs: agent.FirstOf{
+				agent.OutputPattern{Re: agent.JSONSessionIDRe},
+				agent.SessionFiles{Dir: func(string) string { return sessionDir }, Ext: ".jsonl", IDRe: regexp.MustCompile(`^(.+)\.jsonl$`)},
+			}}
+
+			var sid string
+			err := b.Execute(context.Background(), tt.cmd, agent.RunOptions{
+				WorkflowType:      db.WorkflowGeneral,
+				WorkingDir:        tmpDir,
+				Headless:          true,
+				CapturedSessionID: &sid,
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if sid != tt.want {
+				t.Errorf("captured session ID = %q, want %q", sid, tt.want)
+			}
+			sessions, _ := database.ListSessions("")
+			if sessions[0].ClaudeSessionID != tt.want {
+				t.Errorf("stored session ID = %q, want %q", sessions[0].ClaudeSessionID, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteSetsSessionID(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	b := &Base{db: database, outputDir: tmpDir}
+	err = b.Execute(context.Background(), exec.Command("sh", "-c", `echo "id=$CMT_SESSION_ID"`), agent.RunOptions{
+		WorkflowType: db.WorkflowGeneral,
+		WorkingDir:   tmpDir,
+		SessionID:    "ab12cd34",
+		Headless:     true,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	out, _ := os.ReadFile(filepath.Join(tmpDir, "ab12cd34.log"))
+	if !strings.Contains(string(out), "id=ab12cd34") {
+		t.Errorf("agent output = %q, want its session ID in the environment", out)
+	}
+}
+
+func TestExecuteAutonomousEnv(t *testing.T) {
+	t.Setenv(EnvAutonomous, "true")
+
+	tests := []struct {
+		name       string
+		autonomous bool
+		want       string
+	}{
+		{name: "not inherited by a prompting session", autonomous: false, want: "autonomous=<>"},
+		{name: "set for an autonomous session", autonomous: true, want: "autonomous=<true>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+			if err != nil {
+				t.Fatalf("Failed to open database: %v", err)
+			}
+			defer database.Close()
+
+			b := &Base{db: database, outputDir: tmpDir}
+			err = b.Execute(context.Background(), exec.Command("sh", "-c", `echo "autonomous=<$CMT_SESSION_AUTONOMOUS>"`), agent.RunOptions{
+				WorkflowType:   db.WorkflowGeneral,
+				WorkingDir:     tmpDir,
+				SessionID:      "ab12cd34",
+				Headless:       true,
+				AutonomousMode: tt.autonomous,
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			out, _ := os.ReadFile(filepath.Join(tmpDir, "ab12cd34.log"))
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("agent output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteCompletesTodo(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		complete bool
+		want     db.TodoStatus
+	}{
+		{name: "completed", command: "true", complete: true, want: db.TodoStatusDone},
+		{name: "completed without asking", command: "true", want: db.TodoStatusTodo},
+		{name: "failed", command: "false", complete: true, want: db.TodoStatusTodo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+			if err != nil {
+				t.Fatalf("Failed to open database: %v", err)
+			}
+			defer database.Close()
+			if err := database.CreateTodo(&db.Todo{ID: "todo-1", Status: db.TodoStatusTodo, Summary: "Fix it"}); err != nil {
+				t.Fatalf("CreateTodo() error = %v", err)
+			}
+
+			b := &Base{db: database, outputDir: tmpDir}
+			b.Execute(context.Background(), exec.Command(tt.command), agent.RunOptions{ //nolint:errcheck
+				WorkflowType: db.WorkflowGeneral,
+				WorkingDir:   tmpDir,
+				SessionID:    "ab12cd34",
+				Headless:     true,
+				TodoID:       "todo-1",
+				CompleteTodo: tt.complete,
+			})
+
+			session, err := database.GetSession("ab12cd34")
+			if err != nil || session == nil {
+				t.Fatalf("GetSession() = %v, %v", session, err)
+			}
+			if session.TodoID != "todo-1" {
+				t.Errorf("session TodoID = %q, want todo-1", session.TodoID)
+			}
+			todo, err := database.GetTodo("todo-1")
+			if err != nil {
+				t.Fatalf("GetTodo() error = %v", err)
+			}
+			if todo.Status != tt.want {
+				t.Errorf("todo status = %s, want %s", todo.Status, tt.want)
+			}
+		})
+	}
+}
+
+// recordSink records the events of the notifications it is sent.
+type recordSink struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (s *recordSink) Send(n notify.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, n.Event)
+	return nil
+}
+
+func TestExecuteNotifies(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  []string
+		timeout  time.Duration
+		parentID string
+		want     []notify.Event
+	}{
+		{name: "completed", command: []string{"true"}, want: []notify.Event{notify.EventCompleted}},
+		{name: "failed", command: []string{"false"}, want: []notify.Event{notify.EventAbandoned}},
+		{name: "waiting then completed", command: []string{"sh", "-c", "echo hi; sleep 2"}, want: []notify.Event{notify.EventWaiting, notify.EventCompleted}},
+		{name: "timed out", command: []string{"sleep", "10"}, timeout: 200 * time.Millisecond, want: []notify.Event{notify.EventBudget}},
+		{name: "play phase completed", command: []string{"true"}, parentID: "play-1", want: nil},
+		{name: "subsession completed", command: []string{"true"}, parentID: "sess-1", want: []notify.Event{notify.EventCompleted}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+			if err != nil {
+				t.Fatalf("Failed to open database: %v", err)
+			}
+			defer database.Close()
+			database.CreateSession(&db.Session{ID: "play-1", WorkflowType: db.WorkflowPlay, Status: db.StatusWorking})
+			database.CreateSession(&db.Session{ID: "sess-1", WorkflowType: db.WorkflowResearch, Status: db.StatusWaiting})
+
+			sink := &recordSink{}
+			b := &Base{db: database, outputDir: tmpDir, Notifier: notify.New(&notify.Config{Debounce: "0s"}, sink)}
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			b.Execute(ctx, exec.Command(tt.command[0], tt.command[1:]...), agent.RunOptions{ //nolint:errcheck
+				WorkflowType: db.WorkflowGeneral,
+				WorkingDir:   tmpDir,
+				Headless:     true,
+				ParentID:     tt.parentID,
+			})
+			b.Notifier.Wait()
+
+			sink.mu.Lock()
+			defer sink.mu.Unlock()
+			if !slices.Equal(sink.events, tt.want) {
+				t.Errorf("notified %v, want %v", sink.events, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	hookDir := filepath.Join(tmpDir, "hooks")
+	os.MkdirAll(hookDir, 0755)
+	marker := filepath.Join(tmpDir, "refuse")
+	os.WriteFile(filepath.Join(hookDir, "pre-session"), []byte("#!/bin/sh\n! test -e "+marker+"\n"), 0755)
+	os.WriteFile(filepath.Join(hookDir, "post-session"), []byte("#!/bin/sh\necho \"$CMT_SESSION_ID $CMT_STATUS\" >> "+filepath.Join(tmpDir, "post")+"\n"), 0755)
+
+	b := &Base{db: database, outputDir: tmpDir, Hooks: &hooks.Hooks{GlobalDir: hookDir, TrustFile: filepath.Join(tmpDir, "trusted.json")}}
+	opts := agent.RunOptions{WorkflowType: db.WorkflowGeneral, WorkingDir: tmpDir, Headless: true}
+
+	opts.SessionID = "ran"
+	if err := b.Execute(context.Background(), exec.Command("true"), opts); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	post, _ := os.ReadFile(filepath.Join(tmpDir, "post"))
+	if string(post) != "ran completed\n" {
+		t.Errorf("post-session hook wrote %q, want %q", post, "ran completed\n")
+	}
+
+	// A failing pre-session hook refuses the session
+	os.WriteFile(marker, nil, 0644)
+	opts.SessionID = "refused"
+	if err := b.Execute(context.Background(), exec.Command("true"), opts); err == nil || !strings.Contains(err.Error(), "session refused") {
+		t.Fatalf("Execute() error = %v, want the session refused", err)
+	}
+	if session, _ := database.GetSession("refused"); session != nil {
+		t.Errorf("refused session was recorded: %+v", session)
+	}
+
+	// Pre-session hooks that ran on an earlier backend of a chain do not run again
+	opts.SessionID = "fallback"
+	opts.PreSessionRan = true
+	if err := b.Execute(context.Background(), exec.Command("true"), opts); err != nil {
+		t.Fatalf("Execute() after the pre-session hooks ran: error = %v", err)
+	}
+}
+
+func TestExecuteWithoutHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	database, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	b := &Base{db: database, outputDir: tmpDir, Hooks: &hooks.Hooks{GlobalDir: filepath.Join(tmpDir, "hooks"), TrustFile: filepath.Join(tmpDir, "trusted.json")}}
+	err = b.Execute(context.Background(), exec.Command("sh", "-c", "echo 'wrote thoughts/shared/research/x.md'"), agent.RunOptions{
+		WorkflowType: db.WorkflowGeneral,
+		WorkingDir:   tmpDir,
+		Headless:     true,
+		SessionID:    "nohooks",
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	session, _ := database.GetSession("nohooks")
+	if _, err := os.Stat(HooksLogPath(session.OutputFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("hooks log created without any hooks: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentic-camerata/cmt/internal/agent"
+)
+
+// maxStartupOutput caps how much early output is kept for matching startup error patterns.
+const maxStartupOutput = 16 * 1024
+
+// ansiRe matches terminal escape sequences, which TUIs interleave with their text.
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// altScreenRe matches the switch to the alternate screen that full-screen TUIs make when
+// they show their UI.
+var altScreenRe = regexp.MustCompile(`\x1b\[\?(1049|1047|47)h`)
+
+// spaceRe matches runs of whitespace, which TUIs insert when they wrap text.
+var spaceRe = regexp.MustCompile(`\s+`)
+
+// startupWatch applies an agent.StartupCheck to a running process: it kills the process
+// when it prints nothing for too long or prints a known startup error before showing its
+// UI, and classifies an exit before any output as the backend being unavailable.
+type startupWatch struct {
+	check   agent.StartupCheck
+	process *os.Process
+	started time.Time
+	echoed  string // normalized text the agent is expected to echo, such as the task
+
+	mu     sync.Mutex
+	output []byte
+	seen   bool   // any output yet
+	ui     bool   // the agent showed its UI; later output is not checked
+	reason string // why the process was killed, if it was
+	timer  *time.Timer
+}
+
+// newStartupWatch starts watching process. Errors found in echoed, the task the agent
+// shows back to the user, are not startup errors.
+func newStartupWatch(check agent.StartupCheck, process *os.Process, echoed string) *startupWatch {
+	w := &startupWatch{check: check, process: process, started: time.Now(), echoed: normalizeSpace(echoed)}
+	if check.FirstOutputTimeout > 0 {
+		w.timer = time.AfterFunc(check.FirstOutputTimeout, func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if !w.seen {
+				w.kill(fmt.Sprintf("printed nothing within %s", check.FirstOutputTimeout))
+			}
+		})
+	}
+	return w
+}
+
+// onOutput records output from the process and checks it for startup errors until the
+// agent shows its UI or the startup window closes.
+func (w *startupWatch) onOutput(data []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seen = true
+	if w.reason != "" || w.ui || time.Since(w.started) > w.check.Window || len(w.output) >= maxStartupOutput {
+		return
+	}
+	w.output = append(w.output, data...)
+	raw := w.output
+	if loc := altScreenRe.FindIndex(raw); loc != nil {
+		raw = raw[:loc[0]]
+		w.ui = true
+	}
+	text := ansiRe.ReplaceAllString(string(raw), "")
+	for _, re := range w.check.ErrorPatterns {
+		for _, m := range re.FindAllString(text, -1) {
+			if w.echoed != "" && strings.Contains(w.echoed, normalizeSpace(m)) {
+				continue // the agent showing the task back, not an error
+			}
+			w.kill(fmt.Sprintf("reported %q", m))
+			return
+		}
+	}
+}
+
+// normalizeSpace lowercases s and collapses its whitespace, so text compares equal
+// however a TUI wrapped it.
+func normalizeSpace(s string) string {
+	return strings.ToLower(spaceRe.ReplaceAllString(strings.TrimSpace(s), " "))
+}
+
+// kill stops the process for the given reason. w.mu must be held.
+func (w *startupWatch) kill(reason string) {
+	w.reason = reason
+	w.process.Kill() //nolint:errcheck
+}
+
+// failure returns why the backend counts as unavailable given how the process exited,
+// or "" if it started fine. Only what the watch found counts, and an exit before any
+// output that the user did not cause: an agent the user quit right away is not retried
+// on another backend.
+func (w *startupWatch) failure(waitErr error) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.reason != "" {
+		return w.reason
+	}
+	if !w.seen && waitErr != nil && !isSignaledError(waitErr) {
+		return fmt.Sprintf("exited before printing anything (%v)", waitErr)
+	}
+	return ""
+}
+
+func (w *startupWatch) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
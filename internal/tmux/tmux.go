@@ -44,15 +44,27 @@ func CurrentLocation() (*Location, error) {
 		return nil, err
 	}
 
-	// Get session:window.pane format
-	out, err := exec.Command("tmux", "display-message", "-p", "#{session_name}:#{window_index}:#{pane_index}").Output()
+	// Get session:window.pane format. Ask about our own pane rather than the active one,
+	// which differs when we were started in a pane that is not focused.
+	args := []string{"display-message", "-p"}
+	if pane := os.Getenv("TMUX_PANE"); pane != "" {
+		args = append(args, "-t", pane)
+	}
+	out, err := exec.Command("tmux", append(args, locationFormat)...).Output()
 	if err != nil {
 		return nil, fmt.Errorf("get tmux location: %w", err)
 	}
+	return parseLocation(string(out))
+}
+
+// locationFormat is the tmux format parseLocation reads.
+const locationFormat = "#{session_name}:#{window_index}:#{pane_index}"
 
-	parts := strings.Split(strings.TrimSpace(string(out)), ":")
+// parseLocation parses tmux output in locationFormat.
+func parseLocation(out string) (*Location, error) {
+	parts := strings.Split(strings.TrimSpace(out), ":")
 	if len(parts) != 3 {
-		return nil, fmt.Errorf("unexpected tmux output format: %s", string(out))
+		return nil, fmt.Errorf("unexpected tmux output format: %s", out)
 	}
 
 	window, err := strconv.Atoi(parts[1])
@@ -131,3 +143,94 @@ func GetPaneWorkingDirectory(loc Location) (string, error) {
 	}
 	return strings.TrimSpace(string(out)), nil
 }
+
+// Placement says where Spawn starts a command.
+type Placement struct {
+	Session string   // tmux session to start in, created detached if it does not exist; empty means the current one
+	Split   string   // "h" or "v" to split a pane (side by side or stacked) instead of opening a window
+	Name    string   // name of the new window, or title of the new pane
+	Dir     string   // working directory
+	Env     []string // KEY=VALUE variables to set for the command
+}
+
+// Spawn starts command in a new tmux window or pane, without switching to it, and
+// returns where it runs. Outside tmux, only a named Session can be started in.
+func Spawn(p Placement, command []string) (*Location, error) {
+	if p.Session == "" {
+		if err := RequireTmux(); err != nil {
+			return nil, err
+		}
+	}
+	newSession := p.Session != "" && !HasSession(p.Session)
+	out, err := exec.Command("tmux", spawnArgs(p, newSession, command)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("start tmux %s: %w", placementKind(p, newSession), err)
+	}
+	loc, err := parseLocation(string(out))
+	if err != nil {
+		return nil, err
+	}
+	if p.Split != "" && p.Name != "" {
+		exec.Command("tmux", "select-pane", "-t", loc.String(), "-T", p.Name).Run() //nolint:errcheck
+	}
+	return loc, nil
+}
+
+// spawnArgs returns the tmux arguments that start command as p places it. newSession
+// says whether p.Session has to be created.
+func spawnArgs(p Placement, newSession bool, command []string) []string {
+	var args []string
+	switch {
+	case newSession:
+		args = []string{"new-session", "-d", "-s", p.Session}
+	case p.Split != "":
+		args = []string{"split-window", "-d", "-" + p.Split}
+	default:
+		args = []string{"new-window", "-d"}
+	}
+	if p.Name != "" && (newSession || p.Split == "") {
+		args = append(args, "-n", p.Name)
+	}
+	if p.Session != "" && !newSession {
+		args = append(args, "-t", p.Session+":")
+	}
+	if p.Dir != "" {
+		args = append(args, "-c", p.Dir)
+	}
+	for _, kv := range p.Env {
+		args = append(args, "-e", kv)
+	}
+	args = append(args, "-P", "-F", locationFormat)
+	return append(args, command...)
+}
+
+// placementKind names what Spawn creates, for errors.
+func placementKind(p Placement, newSession bool) string {
+	switch {
+	case newSession:
+		return "session"
+	case p.Split != "":
+		return "pane"
+	}
+	return "window"
+}
+
+// HasSession reports whether a tmux session with exactly this name exists.
+func HasSession(name string) bool {
+	return exec.Command("tmux", "has-session", "-t", "="+name).Run() == nil
+}
+
+// SendKeys types text into the pane at loc, followed by Enter if enter is set.
+func SendKeys(loc Location, text string, enter bool) error {
+	if text != "" {
+		if err := exec.Command("tmux", "send-keys", "-t", loc.String(), "-l", text).Run(); err != nil {
+			return fmt.Errorf("send keys to %s: %w", loc, err)
+		}
+	}
+	if enter {
+		if err := exec.Command("tmux", "send-keys", "-t", loc.String(), "Enter").Run(); err != nil {
+			return fmt.Errorf("send keys to %s: %w", loc, err)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package tmux
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -204,3 +205,70 @@ func TestGetPaneWorkingDirectory(t *testing.T) {
 		t.Logf("Pane working directory: %s", dir)
 	})
 }
+
+func TestParseLocation(t *testing.T) {
+	loc, err := parseLocation("work:3:1\n")
+	if err != nil {
+		t.Fatalf("parseLocation() error = %v", err)
+	}
+	if *loc != (Location{Session: "work", Window: 3, Pane: 1}) {
+		t.Errorf("parseLocation() = %+v", loc)
+	}
+
+	for _, out := range []string{"", "work:3", "work:x:1", "work:3:y"} {
+		if _, err := parseLocation(out); err == nil {
+			t.Errorf("parseLocation(%q) error = nil, want error", out)
+		}
+	}
+}
+
+func TestSpawnArgs(t *testing.T) {
+	command := []string{"cmt", "new", "fix it"}
+	tests := []struct {
+		name       string
+		placement  Placement
+		newSession bool
+		want       string
+	}{
+		{
+			name:      "window in current session",
+			placement: Placement{Name: "ab12cd34-general", Dir: "/src"},
+			want:      "new-window -d -n ab12cd34-general -c /src -P -F " + locationFormat + " cmt new fix it",
+		},
+		{
+			name:      "split",
+			placement: Placement{Split: "h", Name: "ab12cd34-general", Env: []string{"CMT_DB=/tmp/x.db"}},
+			want:      "split-window -d -h -e CMT_DB=/tmp/x.db -P -F " + locationFormat + " cmt new fix it",
+		},
+		{
+			name:      "window in existing session",
+			placement: Placement{Session: "agents", Name: "w"},
+			want:      "new-window -d -n w -t agents: -P -F " + locationFormat + " cmt new fix it",
+		},
+		{
+			name:       "new session",
+			placement:  Placement{Session: "agents", Split: "v", Name: "w", Dir: "/src"},
+			newSession: true,
+			want:       "new-session -d -s agents -n w -c /src -P -F " + locationFormat + " cmt new fix it",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(spawnArgs(tt.placement, tt.newSession, command), " ")
+			if got != tt.want {
+				t.Errorf("spawnArgs() = %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpawnRequiresTmux(t *testing.T) {
+	original := os.Getenv("TMUX")
+	defer os.Setenv("TMUX", original)
+	os.Unsetenv("TMUX")
+
+	if _, err := Spawn(Placement{Name: "x"}, []string{"true"}); err != ErrNotInTmux {
+		t.Errorf("Spawn() error = %v, want ErrNotInTmux", err)
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+	"github.com/agentic-camerata/cmt/internal/runner"
 	"github.com/agentic-camerata/cmt/internal/tmux"
 )
 
@@ -72,6 +74,19 @@ type Dashboard struct {
 	expandedScrollOff int           // Scroll offset for the list
 	showDocViewer     bool          // Whether the document viewer is visible
 	docViewport       viewport.Model // Viewport for document content
+	docPath           string         // Document shown by the viewer outside the expanded venue view
+
+	// Play progress state
+	playFile int // Selected captured file of the selected play session
+
+	// Prompt state (new session, send message)
+	prompt *inputPrompt // Open prompt, or nil
+	notice string       // Outcome of the last prompt action, shown in the help bar
+
+	// Live output state
+	tails  map[string]*outputTail // Output tails of running sessions, by session ID
+	peek   bool                   // Whether the peeked session's screen fills the dashboard
+	peekID string                 // The peeked session
 }
 
 // NewDashboard creates a new dashboard model
@@ -168,8 +183,11 @@ func (d *Dashboard) loadPinnedVenues() tea.Msg {
 
 // pruneDeletedSessions removes old deleted sessions
 func (d *Dashboard) pruneDeletedSessions() tea.Msg {
-	count, err := d.db.PruneDeletedSessions()
-	return pruneCompletedMsg{count: count, err: err}
+	outputFiles, err := d.db.PruneDeletedSessions()
+	for _, f := range outputFiles {
+		runner.RemoveSessionFiles(f)
+	}
+	return pruneCompletedMsg{count: int64(len(outputFiles)), err: err}
 }
 
 // loadTodos fetches all todos from the database
@@ -205,12 +223,21 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if d.prompt != nil {
+			if cmd := d.updatePrompt(msg); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			break
+		}
+		if d.peek && !peekKeys[msg.String()] {
+			break
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return d, tea.Quit
 
 		case "tab":
-			if d.showInfo || (d.viewMode == viewVenueExpanded && d.showDocViewer) {
+			if d.showInfo || d.showDocViewer {
 				d.focus = (d.focus + 1) % 2
 			}
 
@@ -233,10 +260,15 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				} else if d.selected < d.listLen()-1 {
 					d.selected++
+					d.playFile = 0
 					d.updateInfoContent()
+					d.refreshPlayFileViewer()
+					if d.peek {
+						d.followPeek()
+					}
 				}
 			} else if d.focus == focusInfo {
-				if d.viewMode == viewVenueExpanded {
+				if d.viewMode == viewVenueExpanded || d.showDocViewer {
 					var cmd tea.Cmd
 					d.docViewport, cmd = d.docViewport.Update(msg)
 					cmds = append(cmds, cmd)
@@ -266,10 +298,15 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				} else if d.selected > 0 {
 					d.selected--
+					d.playFile = 0
 					d.updateInfoContent()
+					d.refreshPlayFileViewer()
+					if d.peek {
+						d.followPeek()
+					}
 				}
 			} else if d.focus == focusInfo {
-				if d.viewMode == viewVenueExpanded {
+				if d.viewMode == viewVenueExpanded || d.showDocViewer {
 					var cmd tea.Cmd
 					d.docViewport, cmd = d.docViewport.Update(msg)
 					cmds = append(cmds, cmd)
@@ -320,7 +357,9 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "esc", "backspace":
-			if d.viewMode == viewVenueExpanded {
+			if d.peek {
+				d.peek = false
+			} else if d.viewMode == viewVenueExpanded {
 				d.viewMode = viewVenues
 				d.showDocViewer = false
 				d.focus = focusList
@@ -329,6 +368,9 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				d.viewMode = viewNormal
 				d.showInfo = false
 				d.focus = focusList
+			} else if d.viewMode == viewNormal && d.showDocViewer {
+				d.showDocViewer = false
+				d.focus = focusList
 			}
 
 		case "o":
@@ -354,6 +396,42 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 					}
 				}
+			} else if d.viewMode == viewNormal {
+				d.togglePlayFileViewer()
+			}
+
+		case "f":
+			// Select the next captured file of the selected play session
+			if d.viewMode == viewNormal {
+				d.nextPlayFile()
+			}
+
+		case "p":
+			// Resume the selected abandoned play session
+			if d.viewMode == viewNormal && d.focus == focusList {
+				if cmd := d.resumePlay(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+
+		case "n":
+			// Start a new session in a new tmux window, on the selected todo in the todos view
+			if d.viewMode == viewTodos && d.focus == focusList {
+				d.startTodoSession()
+			} else if d.viewMode != viewTrash && d.viewMode != viewTodos {
+				d.startNewSession()
+			}
+
+		case "m":
+			// Type a message into the selected running session
+			if d.viewMode == viewNormal && d.focus == focusList {
+				d.startSendMessage()
+			}
+
+		case "v":
+			// Peek at the selected session's screen
+			if d.viewMode == viewNormal && d.focus == focusList {
+				d.togglePeek()
 			}
 
 		case "r":
@@ -402,6 +480,9 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					syscall.Kill(session.PID, syscall.SIGKILL)
 				}
 				d.db.SoftDeleteSession(session.ID)
+				if session.OutputFile != "" {
+					runner.RemoveSessionFiles(session.OutputFile)
+				}
 				cmds = append(cmds, d.loadSessions)
 			} else if d.viewMode == viewTodos && d.focus == focusList {
 				items := sortedTodos(d.todos)
@@ -418,6 +499,8 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				d.viewMode = viewNormal
 			}
+			d.showDocViewer = false
+			d.focus = focusList
 			d.selected = 0 // Reset selection when switching views
 			cmds = append(cmds, d.loadSessions)
 
@@ -436,6 +519,8 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				d.viewMode = viewVenues
 			}
+			d.showDocViewer = false
+			d.focus = focusList
 			d.selected = 0
 			d.venueScrollRow = 0
 			cmds = append(cmds, d.loadSessions)
@@ -459,6 +544,7 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			d.viewMode = viewTodos
 			d.selected = 0
 			d.showInfo = false
+			d.showDocViewer = false
 			d.focus = focusList
 		}
 
@@ -481,6 +567,8 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		d.err = msg.err
 		// Sort sessions: active first, then by created_at desc
 		d.sessions = sortSessions(msg.sessions)
+		// Sessions reload on every tick, which keeps the live output current
+		d.refreshTails()
 		// Clamp selection if list shrunk (only in session-based views)
 		if d.viewMode == viewNormal || d.viewMode == viewTrash {
 			if d.selected >= len(d.sessions) && len(d.sessions) > 0 {
@@ -508,6 +596,14 @@ func (d *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case cmtDoneMsg:
+		d.notice = cmtNotice(msg)
+		cmds = append(cmds, d.loadSessions)
+
+	case playResumedMsg:
+		// The resumed play reports its own errors; refresh to pick up its new state
+		cmds = append(cmds, d.loadSessions)
+
 	case pinnedVenuesLoadedMsg:
 		if msg.err == nil {
 			d.pinnedVenues = msg.dirs
@@ -603,11 +699,14 @@ func (d *Dashboard) formatSessionInfo(session *db.Session) string {
 	content.WriteString(fmt.Sprintf("ID:                %s\n", session.ID))
 	content.WriteString(fmt.Sprintf("Status:            %s\n", session.Status))
 	content.WriteString(fmt.Sprintf("Workflow:          %s\n", session.WorkflowType))
+	if session.Agent != "" {
+		content.WriteString(fmt.Sprintf("Agent:             %s\n", session.Agent))
+	}
 	content.WriteString(fmt.Sprintf("Working Directory: %s\n", session.WorkingDirectory))
 	content.WriteString(fmt.Sprintf("Prefix:            %s\n", session.Prefix))
 	content.WriteString(fmt.Sprintf("Created:           %s\n", session.CreatedAt.Format(time.RFC3339)))
 	content.WriteString(fmt.Sprintf("Updated:           %s\n", session.UpdatedAt.Format(time.RFC3339)))
-	content.WriteString(fmt.Sprintf("Claude Session ID: %s\n", session.ClaudeSessionID))
+	content.WriteString(fmt.Sprintf("Agent Session ID:  %s\n", session.ClaudeSessionID))
 	tmuxLoc := "-"
 	if session.HasTmuxLocation() {
 		tmuxLoc = fmt.Sprintf("%s:%d.%d", session.TmuxSession, session.TmuxWindow, session.TmuxPane)
@@ -616,6 +715,9 @@ func (d *Dashboard) formatSessionInfo(session *db.Session) string {
 	content.WriteString(fmt.Sprintf("Output File:       %s\n", session.OutputFile))
 	content.WriteString(fmt.Sprintf("PID:               %d\n", session.PID))
 
+	// Show the latest screen of running sessions
+	content.WriteString(d.formatLiveOutput(session))
+
 	// Show parent info if this is a child session
 	if session.ParentID != "" {
 		content.WriteString(fmt.Sprintf("Parent ID:         %s\n", session.ParentID))
@@ -640,6 +742,18 @@ func (d *Dashboard) formatSessionInfo(session *db.Session) string {
 		}
 	}
 
+	// Show phase progress and captured files for play sessions
+	if session.WorkflowType == db.WorkflowPlay {
+		if state, err := playbook.DecodeState(session.PlayState); err == nil {
+			selected := -1
+			if d.viewMode == viewNormal {
+				selected = d.playFile
+			}
+			content.WriteString("\n")
+			content.WriteString(formatPlayProgress(session, state, selected, time.Now()))
+		}
+	}
+
 	// Show saved playbook content for play sessions
 	if session.WorkflowType == db.WorkflowPlay && session.PlaybookFile != "" {
 		content.WriteString("\n")
@@ -684,14 +798,18 @@ func (d *Dashboard) View() string {
 		)
 	}
 
+	if d.peek {
+		return d.renderPeek()
+	}
+
 	// Session list panel
 	listPanel := d.renderSessionList()
 
 	// Help bar
 	help := d.renderHelp()
 
-	// Doc viewer panel (expanded venue view)
-	if d.viewMode == viewVenueExpanded && d.showDocViewer {
+	// Doc viewer panel (expanded venue view, or a play's captured file)
+	if d.showDocViewer {
 		docPanel := d.renderDocViewer()
 		return lipgloss.JoinVertical(lipgloss.Left, header, listPanel, docPanel, help)
 	}
@@ -1079,6 +1197,10 @@ func (d *Dashboard) renderInfoPanel() string {
 
 // updateDocViewerContent reads the selected document and sets the viewport content
 func (d *Dashboard) updateDocViewerContent() {
+	if d.viewMode != viewVenueExpanded {
+		d.setDocViewerFile(d.docPath)
+		return
+	}
 	if d.expandedSelected >= len(d.expandedItems) {
 		d.docViewport.SetContent("No document selected")
 		return
@@ -1090,7 +1212,12 @@ func (d *Dashboard) updateDocViewerContent() {
 		return
 	}
 
-	data, err := os.ReadFile(item.DocPath)
+	d.setDocViewerFile(item.DocPath)
+}
+
+// setDocViewerFile reads path into the document viewer
+func (d *Dashboard) setDocViewerFile(path string) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		d.docViewport.SetContent(fmt.Sprintf("Error reading file: %v", err))
 		return
@@ -1110,7 +1237,11 @@ func (d *Dashboard) renderDocViewer() string {
 	}
 
 	titleText := "Document Viewer"
-	if d.expandedSelected < len(d.expandedItems) {
+	if d.viewMode != viewVenueExpanded {
+		if d.docPath != "" {
+			titleText = filepath.Base(d.docPath)
+		}
+	} else if d.expandedSelected < len(d.expandedItems) {
 		item := d.expandedItems[d.expandedSelected]
 		if item.Type == VenueItemDocument {
 			titleText = filepath.Base(item.DocPath)
@@ -1123,22 +1254,36 @@ func (d *Dashboard) renderDocViewer() string {
 
 // renderHelp renders the help bar
 func (d *Dashboard) renderHelp() string {
+	if d.prompt != nil {
+		return d.renderPrompt()
+	}
 	var help string
-	switch d.viewMode {
-	case viewTrash:
+	switch {
+	case d.peek:
+		help = "j/k: prev/next session • m: send message • v/esc: back • q: quit"
+	case d.viewMode == viewTrash:
 		help = "j/k: navigate • R: restore • T: back to sessions • i: toggle info • r: refresh • q: quit"
-	case viewVenues:
-		help = "h/j/k/l: navigate • enter: expand • V: back to sessions • r: refresh • q: quit"
-	case viewTodos:
-		help = "j/k: navigate • c: toggle done • o: open url • D: delete • i: toggle info • esc: back • r: refresh • q: quit"
-	case viewVenueExpanded:
+	case d.viewMode == viewVenues:
+		help = "h/j/k/l: navigate • enter: expand • n: new session • V: back to sessions • r: refresh • q: quit"
+	case d.viewMode == viewTodos:
+		help = "j/k: navigate • n: start session • c: toggle done • o: open url • D: delete • i: toggle info • esc: back • r: refresh • q: quit"
+	case d.viewMode == viewVenueExpanded:
 		if d.showDocViewer {
 			help = "j/k: navigate • tab: switch focus • o: close viewer • enter: jump • esc: back • q: quit"
 		} else {
-			help = "j/k: navigate • enter: jump • o: view doc • esc: back to venues • r: refresh • q: quit"
+			help = "j/k: navigate • enter: jump • o: view doc • n: new session • esc: back to venues • r: refresh • q: quit"
 		}
 	default:
-		help = "j/k: navigate • enter: jump • s: stop • D: delete • T: trash • V: venues • i: toggle info • r: refresh • q: quit"
+		if d.showDocViewer {
+			help = "j/k: navigate • tab: switch focus • f: next file • o: close viewer • esc: close viewer • q: quit"
+		} else if session, _ := d.selectedPlay(); session != nil {
+			help = "j/k: navigate • enter: jump • n: new session • m: send message • v: peek • f: next file • o: view file • p: resume play • s: stop • D: delete • i: toggle info • r: refresh • q: quit"
+		} else {
+			help = "j/k: navigate • enter: jump • n: new session • m: send message • v: peek • s: stop • D: delete • T: trash • V: venues • i: toggle info • r: refresh • q: quit"
+		}
+	}
+	if d.notice != "" {
+		help = d.notice + " • " + help
 	}
 	return helpStyle.Render(help)
 }
@@ -1214,7 +1359,7 @@ func (d *Dashboard) contentHeight() int {
 	//   2 - session panel border (top + bottom)
 	//   1 - help bar
 	// = 5 total
-	if d.viewMode == viewVenueExpanded && d.showDocViewer {
+	if d.showDocViewer {
 		// Doc viewer gets up to 80% of available space; list gets the rest
 		chrome := 8
 		available := d.height - chrome
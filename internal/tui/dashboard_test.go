@@ -1,12 +1,14 @@
 package tui
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/agentic-camerata/cmt/internal/db"
 )
@@ -481,3 +483,207 @@ func setupTestDB(t *testing.T) *db.DB {
 
 	return database
 }
+
+func TestDashboardPrompt(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	database.CreateSession(&db.Session{ID: "test-1", WorkflowType: db.WorkflowResearch, Status: db.StatusWaiting, WorkingDirectory: "/src/app"})
+
+	d := NewDashboard(database)
+	d.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	d.Update(d.loadSessions())
+
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if d.prompt == nil || d.prompt.label != "New session in /src/app" {
+		t.Fatalf("after n: prompt = %+v, want new session prompt for /src/app", d.prompt)
+	}
+
+	// Keys go to the prompt, not the dashboard
+	for _, r := range "fix it!" {
+		d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	d.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if d.prompt.text != "fix it" {
+		t.Errorf("prompt text = %q, want %q", d.prompt.text, "fix it")
+	}
+	if !strings.Contains(d.View(), "New session in /src/app: fix it") {
+		t.Error("View() does not show the new session prompt")
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if d.prompt != nil {
+		t.Error("esc did not close the prompt")
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if d.prompt == nil || d.prompt.label != "Send to test-1" {
+		t.Fatalf("after m: prompt = %+v, want send prompt for test-1", d.prompt)
+	}
+	var submitted string
+	d.prompt.submit = func(text string) tea.Cmd {
+		submitted = text
+		return nil
+	}
+	d.prompt.text = " yes "
+	d.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if submitted != "yes" || d.prompt != nil {
+		t.Fatalf("enter: submitted %q, prompt open %v; want yes submitted and prompt closed", submitted, d.prompt != nil)
+	}
+	d.Update(cmtDoneMsg{output: "Sent to session test-1"})
+	if !strings.Contains(d.renderHelp(), "Sent to session test-1") {
+		t.Errorf("help bar = %q, want the send outcome", d.renderHelp())
+	}
+}
+
+func TestNewSessionArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		task   string
+		inTmux bool
+		want   string
+	}{
+		{name: "in tmux", task: "fix it", inTmux: true, want: "new --window -- fix it"},
+		{name: "outside tmux", task: "fix it", inTmux: false, want: "new --tmux-session cmt -- fix it"},
+		{name: "no task", task: "", inTmux: true, want: "new --window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(newSessionArgs(tt.task, tt.inTmux), " "); got != tt.want {
+				t.Errorf("newSessionArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTodoStartArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		venue  string
+		inTmux bool
+		want   string
+	}{
+		{name: "in tmux", venue: "/src/app", inTmux: true, want: "todo start t1 --window --venue /src/app"},
+		{name: "outside tmux", venue: "/src/app", inTmux: false, want: "todo start t1 --tmux-session cmt --venue /src/app"},
+		{name: "no venue", venue: "", inTmux: true, want: "todo start t1 --window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(todoStartArgs("t1", tt.venue, tt.inTmux), " "); got != tt.want {
+				t.Errorf("todoStartArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDashboardTodoSessions(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+	database.CreateTodo(&db.Todo{ID: "t1", Status: db.TodoStatusTodo, Summary: "Fix the crash"})
+	database.CreateSession(&db.Session{ID: "sess-1", WorkflowType: db.WorkflowResearch, Status: db.StatusCompleted, WorkingDirectory: "/src/app", TodoID: "t1"})
+	database.CreateSession(&db.Session{ID: "sess-2", WorkflowType: db.WorkflowGeneral, Status: db.StatusWaiting, WorkingDirectory: "/src/other"})
+
+	d := NewDashboard(database)
+	d.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	d.Update(d.loadTodos())
+
+	info := d.formatTodoInfo(d.todos[0])
+	if !strings.Contains(info, "sess-1") || !strings.Contains(info, "research") || strings.Contains(info, "sess-2") {
+		t.Errorf("formatTodoInfo() = %q, want only the linked session sess-1", info)
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if d.prompt == nil || d.prompt.label != "Start session on t1 in" {
+		t.Fatalf("after n: prompt = %+v, want the start session prompt for t1", d.prompt)
+	}
+	if d.prompt.text != "/src/app" {
+		t.Errorf("prompt text = %q, want the linked session's directory /src/app", d.prompt.text)
+	}
+}
+
+func TestDashboardLiveOutput(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	dir := t.TempDir()
+	runningLog := filepath.Join(dir, "run-1.log")
+	doneLog := filepath.Join(dir, "done-1.log")
+	// A spinner redrawn in place should show only its last frame
+	os.WriteFile(runningLog, []byte("building\r\n\x1b[2K\rstep 1\r\x1b[2Kstep 2\r\n"), 0644)
+	os.WriteFile(doneLog, []byte("finished\r\n"), 0644)
+	database.CreateSession(&db.Session{ID: "run-1", WorkflowType: db.WorkflowResearch, Status: db.StatusWorking, OutputFile: runningLog})
+	database.CreateSession(&db.Session{ID: "done-1", WorkflowType: db.WorkflowResearch, Status: db.StatusCompleted, OutputFile: doneLog})
+
+	d := NewDashboard(database)
+	d.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	d.Update(d.loadSessions())
+
+	if _, ok := d.tails["done-1"]; ok {
+		t.Error("completed session is tailed")
+	}
+	info := d.formatSessionInfo(d.sessions[0])
+	if !strings.Contains(info, "Live Output") || !strings.Contains(info, "step 2") || strings.Contains(info, "step 1") {
+		t.Errorf("info panel = %q, want live output ending in step 2", info)
+	}
+
+	// New output shows up on the next refresh
+	f, _ := os.OpenFile(runningLog, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("step 3\r\n")
+	f.Close()
+	d.Update(d.loadSessions())
+	if !strings.Contains(d.formatSessionInfo(d.sessions[0]), "step 3") {
+		t.Error("info panel does not show appended output")
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if !d.peek || d.peekID != "run-1" {
+		t.Fatalf("after v: peek = %v on %q, want peek on run-1", d.peek, d.peekID)
+	}
+	if view := d.View(); !strings.Contains(view, "Peek run-1") || !strings.Contains(view, "step 3") {
+		t.Errorf("peek view = %q, want run-1's screen", view)
+	}
+
+	// Moving the selection peeks at the next session, even when it is done
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if d.peekID != "done-1" || !strings.Contains(d.View(), "finished") {
+		t.Errorf("after j: peeking at %q, want done-1's screen", d.peekID)
+	}
+
+	// Keys that act on sessions are ignored while peeking
+	d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	if d.viewMode != viewNormal {
+		t.Error("T switched views while peeking")
+	}
+
+	d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if d.peek {
+		t.Error("esc did not leave peek")
+	}
+}
+
+func TestRenderPeekNarrow(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	log := filepath.Join(t.TempDir(), "wide-1.log")
+	os.WriteFile(log, []byte(strings.Repeat("日本", 20)+"\r\n"), 0644)
+	database.CreateSession(&db.Session{ID: "wide-1", WorkflowType: db.WorkflowResearch, Status: db.StatusWorking, OutputFile: log})
+
+	for _, width := range []int{2, 20} {
+		d := NewDashboard(database)
+		d.Update(tea.WindowSizeMsg{Width: width, Height: 20})
+		d.Update(d.loadSessions())
+		d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+
+		for _, line := range strings.Split(d.renderPeek(), "\n") {
+			// Lines are padded to the widest part of the view, such as the help
+			line = strings.TrimRight(line, " ")
+			if strings.Contains(line, "日") && lipgloss.Width(line) > width {
+				t.Errorf("width %d: output line %q is %d cells wide", width, line, lipgloss.Width(line))
+			}
+		}
+	}
+}
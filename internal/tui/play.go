@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+)
+
+// Display statuses for phases that have no recorded outcome.
+const (
+	phasePending = "pending"
+	phaseRunning = "running"
+)
+
+// playFile is a file captured by a play phase, labelled with the tag (or phase type) it was captured under.
+type playFile struct {
+	label string
+	path  string
+}
+
+// playResumedMsg is sent when a resumed play exits and control returns to the dashboard.
+type playResumedMsg struct{}
+
+// phaseDisplayStatus returns the status shown for phase i: running while the phase is in
+// progress in a running session, otherwise its recorded outcome, or pending if it has none.
+func phaseDisplayStatus(state *playbook.State, i int, running bool) string {
+	if running && state.PhaseTimes[i].Running() {
+		return phaseRunning
+	}
+	if st := state.PhaseStatus[i]; st != "" {
+		return st
+	}
+	return phasePending
+}
+
+// playCapturedFiles lists the files captured so far, grouped by tag in tag order. Files
+// captured by untagged research and plan phases follow under the phase type.
+func playCapturedFiles(state *playbook.State) []playFile {
+	var files []playFile
+	seen := make(map[string]bool)
+	add := func(label, path string) {
+		if path == "" || seen[path] {
+			return
+		}
+		seen[path] = true
+		files = append(files, playFile{label: label, path: path})
+	}
+
+	tags := make([]string, 0, len(state.TaggedFiles))
+	for tag := range state.TaggedFiles {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		for _, path := range state.TaggedFiles[tag] {
+			add(tag, path)
+		}
+	}
+	for _, path := range state.ResearchFiles {
+		add("research", path)
+	}
+	add("plan", state.PlanFile)
+	return files
+}
+
+// formatPlayProgress formats the progress of a play session: every phase with its status and
+// elapsed time, the next phase to run, and the captured files with selected marked.
+func formatPlayProgress(session *db.Session, state *playbook.State, selected int, now time.Time) string {
+	var content strings.Builder
+	content.WriteString("─── Play Progress ────────────────────────\n")
+	content.WriteString("\n")
+	if len(state.Phases) == 0 {
+		content.WriteString("(No phases recorded yet)\n")
+		return content.String()
+	}
+
+	running := isRunning(session)
+	next := "-"
+	if state.NextPhase < len(state.Phases) {
+		next = fmt.Sprintf("%d/%d %s", state.NextPhase+1, len(state.Phases), phaseLabel(state.Phases[state.NextPhase]))
+	} else if session.Status == db.StatusCompleted {
+		next = "(complete)"
+	}
+	content.WriteString(fmt.Sprintf("Next Phase:        %s\n", next))
+	content.WriteString("\n")
+
+	for i, phase := range state.Phases {
+		elapsed := "-"
+		if d := state.PhaseTimes[i].Elapsed(now); d > 0 {
+			elapsed = d.Round(time.Second).String()
+		}
+		content.WriteString(fmt.Sprintf("%3d  %-24s %-12s %s\n",
+			i+1, truncateToWidth(phaseLabel(phase), 24), phaseDisplayStatus(state, i, running), elapsed))
+	}
+
+	files := playCapturedFiles(state)
+	if len(files) == 0 {
+		return content.String()
+	}
+	content.WriteString("\n")
+	content.WriteString("─── Captured Files ───────────────────────\n")
+	content.WriteString("\n")
+	for i, f := range files {
+		marker := "  "
+		if i == selected {
+			marker = "> "
+		}
+		content.WriteString(fmt.Sprintf("%s%-12s %s\n", marker, f.label+":", f.path))
+	}
+	return content.String()
+}
+
+// phaseLabel describes a phase by its type and tag.
+func phaseLabel(phase playbook.Phase) string {
+	if phase.Tag != "" {
+		return fmt.Sprintf("%s (%s)", phase.Type, phase.Tag)
+	}
+	return phase.Type
+}
+
+// selectedPlay returns the selected session and its decoded play state when the normal
+// view has a play session selected.
+func (d *Dashboard) selectedPlay() (*db.Session, *playbook.State) {
+	if d.viewMode != viewNormal {
+		return nil, nil
+	}
+	session := d.normalViewSession(d.selected)
+	if session == nil || session.WorkflowType != db.WorkflowPlay {
+		return nil, nil
+	}
+	state, err := playbook.DecodeState(session.PlayState)
+	if err != nil {
+		return session, nil
+	}
+	return session, state
+}
+
+// selectedPlayFile returns the absolute path of the captured file selected in the play
+// progress panel, or "" if there is none.
+func (d *Dashboard) selectedPlayFile() string {
+	session, state := d.selectedPlay()
+	if state == nil {
+		return ""
+	}
+	files := playCapturedFiles(state)
+	if d.playFile >= len(files) {
+		return ""
+	}
+	path := files[d.playFile].path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(session.WorkingDirectory, path)
+	}
+	return path
+}
+
+// nextPlayFile selects the next captured file of the selected play, wrapping around.
+func (d *Dashboard) nextPlayFile() {
+	_, state := d.selectedPlay()
+	if state == nil {
+		return
+	}
+	if n := len(playCapturedFiles(state)); n > 0 {
+		d.playFile = (d.playFile + 1) % n
+	}
+	d.updateInfoContent()
+	d.refreshPlayFileViewer()
+}
+
+// togglePlayFileViewer opens the selected captured file in the doc viewer, or closes the viewer.
+func (d *Dashboard) togglePlayFileViewer() {
+	if d.showDocViewer {
+		d.showDocViewer = false
+		d.focus = focusList
+		return
+	}
+	path := d.selectedPlayFile()
+	if path == "" {
+		return
+	}
+	d.docPath = path
+	d.showDocViewer = true
+	d.docViewport = viewport.New(d.infoWidth(), d.infoHeight())
+	d.updateDocViewerContent()
+	d.focus = focusInfo
+}
+
+// resumePlay suspends the dashboard and runs "cmt play --resume" for the selected
+// abandoned play session in its working directory, against the dashboard's database.
+func (d *Dashboard) resumePlay() tea.Cmd {
+	session, _ := d.selectedPlay()
+	if session == nil || session.Status != db.StatusAbandoned {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	cmd := exec.Command(exe, "--db", d.db.Path(), "play", "--resume", session.ID)
+	cmd.Dir = session.WorkingDirectory
+	return tea.ExecProcess(cmd, func(error) tea.Msg {
+		return playResumedMsg{}
+	})
+}
+
+// refreshPlayFileViewer points an open doc viewer at the selected captured file, closing
+// it when the selection has no captured file to show.
+func (d *Dashboard) refreshPlayFileViewer() {
+	if !d.showDocViewer || d.viewMode != viewNormal {
+		return
+	}
+	d.docPath = d.selectedPlayFile()
+	if d.docPath == "" {
+		d.showDocViewer = false
+		d.focus = focusList
+		return
+	}
+	d.updateDocViewerContent()
+}
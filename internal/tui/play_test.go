@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/playbook"
+)
+
+func testPlayState(start time.Time) *playbook.State {
+	return &playbook.State{
+		Context: playbook.Context{
+			ResearchFiles: []string{"thoughts/shared/research/r.md"},
+			PlanFile:      "thoughts/shared/plans/p.md",
+			TaggedFiles:   map[string][]string{"design": {"thoughts/shared/plans/p.md"}},
+			PhaseStatus:   map[int]string{0: playbook.StatusDone, 1: playbook.StatusDone, 3: playbook.StatusRolledBack},
+		},
+		NextPhase: 2,
+		Phases: []playbook.Phase{
+			{Type: "research"},
+			{Type: "plan", Tag: "design"},
+			{Type: "implement"},
+			{Type: "review"},
+		},
+		PhaseTimes: map[int]playbook.PhaseTime{
+			0: {Started: start, Finished: start.Add(4 * time.Minute)},
+			1: {Started: start.Add(5 * time.Minute), Finished: start.Add(7 * time.Minute)},
+			2: {Started: start.Add(8 * time.Minute)},
+		},
+	}
+}
+
+func TestPhaseDisplayStatus(t *testing.T) {
+	state := testPlayState(time.Now())
+
+	tests := []struct {
+		name    string
+		phase   int
+		running bool
+		want    string
+	}{
+		{name: "done", phase: 0, running: true, want: playbook.StatusDone},
+		{name: "in progress", phase: 2, running: true, want: phaseRunning},
+		{name: "in progress in abandoned session", phase: 2, running: false, want: phasePending},
+		{name: "rolled back", phase: 3, running: true, want: playbook.StatusRolledBack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := phaseDisplayStatus(state, tt.phase, tt.running); got != tt.want {
+				t.Errorf("phaseDisplayStatus(%d) = %q, want %q", tt.phase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayCapturedFiles(t *testing.T) {
+	files := playCapturedFiles(testPlayState(time.Now()))
+
+	// The plan file is listed once, under its tag
+	want := []playFile{
+		{label: "design", path: "thoughts/shared/plans/p.md"},
+		{label: "research", path: "thoughts/shared/research/r.md"},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("playCapturedFiles() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("files[%d] = %v, want %v", i, files[i], want[i])
+		}
+	}
+}
+
+func TestFormatPlayProgress(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	session := &db.Session{ID: "play-1", WorkflowType: db.WorkflowPlay, Status: db.StatusWorking}
+
+	out := formatPlayProgress(session, testPlayState(start), 1, start.Add(10*time.Minute))
+
+	for _, want := range []string{
+		"Next Phase:        3/4 implement",
+		"research                 done         4m0s",
+		"plan (design)            done         2m0s",
+		"implement                running      2m0s",
+		"review                   rolled-back  -",
+		"  design:      thoughts/shared/plans/p.md",
+		"> research:    thoughts/shared/research/r.md",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("formatPlayProgress() missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestPlayProgressKeys(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	workDir := t.TempDir()
+	for _, f := range []string{"thoughts/shared/plans/p.md", "thoughts/shared/research/r.md"} {
+		path := filepath.Join(workDir, f)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		os.WriteFile(path, []byte("contents of "+filepath.Base(f)), 0o644)
+	}
+	stateJSON, _ := json.Marshal(testPlayState(time.Now()))
+	session := &db.Session{
+		ID:               "play-1",
+		WorkflowType:     db.WorkflowPlay,
+		Status:           db.StatusCompleted,
+		WorkingDirectory: workDir,
+		PlayState:        string(stateJSON),
+	}
+
+	newDashboard := func() *Dashboard {
+		d := NewDashboard(database)
+		d.width = 120
+		d.height = 40
+		d.sessions = []*db.Session{session}
+		return d
+	}
+	press := func(d *Dashboard, key rune) (*Dashboard, tea.Cmd) {
+		model, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		return model.(*Dashboard), cmd
+	}
+
+	t.Run("o opens the selected captured file", func(t *testing.T) {
+		d, _ := press(newDashboard(), 'o')
+		if !d.showDocViewer {
+			t.Fatal("o should open the doc viewer")
+		}
+		if want := filepath.Join(workDir, "thoughts/shared/plans/p.md"); d.docPath != want {
+			t.Errorf("docPath = %q, want %q", d.docPath, want)
+		}
+		if !strings.Contains(d.View(), "p.md") {
+			t.Error("view should show the doc viewer titled with the file name")
+		}
+
+		d, _ = press(d, 'o')
+		if d.showDocViewer {
+			t.Error("second o should close the doc viewer")
+		}
+	})
+
+	t.Run("f cycles captured files", func(t *testing.T) {
+		d, _ := press(newDashboard(), 'o')
+		d, _ = press(d, 'f')
+		if d.playFile != 1 {
+			t.Errorf("playFile = %d, want 1", d.playFile)
+		}
+		if filepath.Base(d.docPath) != "r.md" {
+			t.Errorf("viewer should follow the selection, showing %q", d.docPath)
+		}
+		d, _ = press(d, 'f')
+		if d.playFile != 0 {
+			t.Errorf("playFile = %d, want 0 after wrapping", d.playFile)
+		}
+	})
+
+	t.Run("p only resumes abandoned plays", func(t *testing.T) {
+		d := newDashboard()
+		if _, cmd := press(d, 'p'); cmd != nil {
+			t.Error("p should not resume a completed play")
+		}
+
+		abandoned := *session
+		abandoned.Status = db.StatusAbandoned
+		d.sessions = []*db.Session{&abandoned}
+		if _, cmd := press(d, 'p'); cmd == nil {
+			t.Error("p should resume an abandoned play")
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/agentic-camerata/cmt/internal/db"
+	"github.com/agentic-camerata/cmt/internal/vt"
+)
+
+const (
+	// previewRows and previewCols are the terminal size sessions' output is replayed at.
+	// Headless sessions run at this size; interactive ones at their terminal's, which is
+	// not recorded, so their redraws may land slightly off.
+	previewRows = 40
+	previewCols = 120
+	// previewLines is how many screen lines the info panel shows.
+	previewLines = 12
+	// maxTailStart is how much of an existing log is replayed when tailing starts.
+	maxTailStart = 256 * 1024
+)
+
+// outputTail follows a session's output file through a terminal emulator, so the
+// dashboard can show what the session's screen looks like now.
+type outputTail struct {
+	path   string
+	offset int64
+	screen *vt.Screen
+}
+
+func newOutputTail(path string) *outputTail {
+	return &outputTail{path: path, offset: -1, screen: vt.New(previewRows, previewCols)}
+}
+
+// update feeds output written since the last update to the emulator.
+func (t *outputTail) update() error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if t.offset < 0 {
+		t.offset = max(info.Size()-maxTailStart, 0)
+	}
+	if info.Size() < t.offset {
+		// The log was recreated (e.g. a loop iteration); start over
+		t.offset = 0
+		t.screen = vt.New(previewRows, previewCols)
+	}
+	if _, err := f.Seek(t.offset, io.SeekStart); err != nil {
+		return err
+	}
+	n, err := io.Copy(t.screen, f)
+	t.offset += n
+	return err
+}
+
+// refreshTails updates the output tails of running sessions and the peeked one, and
+// drops the tails of sessions that are no longer shown or running.
+func (d *Dashboard) refreshTails() {
+	if d.tails == nil {
+		d.tails = make(map[string]*outputTail)
+	}
+	keep := make(map[string]bool)
+	for _, s := range d.sessions {
+		if s.OutputFile != "" && (isRunning(s) || d.peek && s.ID == d.peekID) {
+			keep[s.ID] = true
+			t, ok := d.tails[s.ID]
+			if !ok {
+				t = newOutputTail(s.OutputFile)
+				d.tails[s.ID] = t
+			}
+			t.update() //nolint:errcheck // the log may not exist yet
+		}
+	}
+	for id := range d.tails {
+		if !keep[id] {
+			delete(d.tails, id)
+		}
+	}
+}
+
+// formatLiveOutput formats the last screen lines of a running session for the info panel.
+func (d *Dashboard) formatLiveOutput(session *db.Session) string {
+	t, ok := d.tails[session.ID]
+	if !ok {
+		return ""
+	}
+	var content strings.Builder
+	content.WriteString("\n")
+	content.WriteString("─── Live Output ──────────────────────────\n")
+	content.WriteString("\n")
+	lines := t.screen.LastLines(previewLines)
+	if len(lines) == 0 {
+		content.WriteString("(No output yet)\n")
+	}
+	for _, line := range lines {
+		content.WriteString(line + "\n")
+	}
+	return content.String()
+}
+
+// togglePeek opens the full-screen view of the selected session's output, or closes it.
+func (d *Dashboard) togglePeek() {
+	if d.peek {
+		d.peek = false
+		return
+	}
+	session := d.normalViewSession(d.selected)
+	if session == nil || session.OutputFile == "" {
+		return
+	}
+	d.peek = true
+	d.peekID = session.ID
+	d.refreshTails()
+}
+
+// followPeek points the peek view at the newly selected session.
+func (d *Dashboard) followPeek() {
+	if session := d.normalViewSession(d.selected); session != nil && session.OutputFile != "" {
+		d.peekID = session.ID
+		d.refreshTails()
+	}
+}
+
+// peekKeys are the keys handled while peeking; others are ignored.
+var peekKeys = map[string]bool{"j": true, "k": true, "down": true, "up": true, "m": true, "v": true, "esc": true, "q": true, "ctrl+c": true}
+
+// renderPeek renders the peeked session's screen over the whole dashboard.
+func (d *Dashboard) renderPeek() string {
+	header := titleStyle.Render("Agentic Camerata")
+	var session *db.Session
+	for _, s := range d.sessions {
+		if s.ID == d.peekID {
+			session = s
+		}
+	}
+	if session == nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, "Session not found", d.renderHelp())
+	}
+
+	title := titleStyle.Render(fmt.Sprintf("Peek %s · %s · %s", session.ID, session.WorkflowType, session.Status))
+	height := max(d.height-6, 3)
+	var lines []string
+	if t, ok := d.tails[session.ID]; ok {
+		lines = t.screen.LastLines(height)
+	}
+	if len(lines) == 0 {
+		lines = []string{"(No output yet)"}
+	}
+	// Cut by display width, so wide characters do not wrap the panel
+	width := max(d.infoWidth(), 0)
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "")
+	}
+	body := panelStyle.Width(width).Height(height).Render(strings.Join(lines, "\n"))
+	return lipgloss.JoinVertical(lipgloss.Left, header, title, body, d.renderHelp())
+}
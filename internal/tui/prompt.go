@@ -0,0 +1,185 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/agentic-camerata/cmt/internal/tmux"
+)
+
+// launchTmuxSession is the tmux session new sessions are started in when the dashboard
+// does not run inside tmux.
+const launchTmuxSession = "cmt"
+
+// inputPrompt is a one-line text prompt shown in place of the help bar.
+type inputPrompt struct {
+	label  string
+	text   string
+	submit func(text string) tea.Cmd
+}
+
+// cmtDoneMsg is sent when a cmt command run by the dashboard finished.
+type cmtDoneMsg struct {
+	output string
+	err    error
+}
+
+// startNewSession opens the task prompt for a new session in the directory of the
+// selected session or venue.
+func (d *Dashboard) startNewSession() {
+	dir := d.newSessionDir()
+	label := "New session in ."
+	if dir != "" {
+		label = "New session in " + shortenPath(dir, 40)
+	}
+	d.openPrompt(label, func(task string) tea.Cmd {
+		return d.runCmt(dir, newSessionArgs(task, tmux.InTmux()))
+	})
+}
+
+// startTodoSession opens the venue prompt for a session on the selected todo, filled in
+// with the directory of the todo's latest session or else the dashboard's own.
+func (d *Dashboard) startTodoSession() {
+	items := sortedTodos(d.todos)
+	if d.selected >= len(items) {
+		return
+	}
+	todo := items[d.selected]
+	dir, _ := os.Getwd()
+	if sessions, err := d.db.ListTodoSessions(todo.ID); err == nil && len(sessions) > 0 {
+		dir = sessions[0].WorkingDirectory
+	}
+	d.openPrompt("Start session on "+todo.ID+" in", func(venue string) tea.Cmd {
+		return d.runCmt("", todoStartArgs(todo.ID, expandHome(venue), tmux.InTmux()))
+	})
+	d.prompt.text = dir
+}
+
+// startSendMessage opens the prompt for a message to type into the selected session.
+func (d *Dashboard) startSendMessage() {
+	session := d.normalViewSession(d.selected)
+	if session == nil || !isRunning(session) {
+		return
+	}
+	d.openPrompt("Send to "+session.ID, func(text string) tea.Cmd {
+		return d.runCmt("", []string{"send", session.ID, "--", text})
+	})
+}
+
+func (d *Dashboard) openPrompt(label string, submit func(string) tea.Cmd) {
+	d.prompt = &inputPrompt{label: label, submit: submit}
+	d.notice = ""
+}
+
+// newSessionDir returns the directory a new session started from the dashboard runs in:
+// the selected venue's, or the selected session's. Empty means the dashboard's own.
+func (d *Dashboard) newSessionDir() string {
+	switch d.viewMode {
+	case viewVenues:
+		if venues := buildVenues(d.sessions, d.pinnedVenues); d.selected < len(venues) {
+			return venues[d.selected].Directory
+		}
+	case viewVenueExpanded:
+		if d.expandedVenue != nil {
+			return d.expandedVenue.Directory
+		}
+	case viewNormal:
+		if session := d.normalViewSession(d.selected); session != nil {
+			return session.WorkingDirectory
+		}
+	}
+	return ""
+}
+
+// updatePrompt handles a key while the prompt is open.
+func (d *Dashboard) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	p := d.prompt
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		d.prompt = nil
+	case tea.KeyEnter:
+		d.prompt = nil
+		return p.submit(strings.TrimSpace(p.text))
+	case tea.KeyBackspace:
+		if runes := []rune(p.text); len(runes) > 0 {
+			p.text = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.text += string(msg.Runes)
+	}
+	return nil
+}
+
+// runCmt runs cmt with args in dir against the dashboard's database.
+func (d *Dashboard) runCmt(dir string, args []string) tea.Cmd {
+	dbPath := d.db.Path()
+	return func() tea.Msg {
+		exe, err := os.Executable()
+		if err != nil {
+			return cmtDoneMsg{err: err}
+		}
+		cmd := exec.Command(exe, append([]string{"--db", dbPath}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		return cmtDoneMsg{output: strings.TrimSpace(string(out)), err: err}
+	}
+}
+
+// newSessionArgs returns the cmt arguments that start a new session for task in a new
+// tmux window: of the current tmux session, or of launchTmuxSession outside tmux.
+func newSessionArgs(task string, inTmux bool) []string {
+	args := []string{"new", "--window"}
+	if !inTmux {
+		args = []string{"new", "--tmux-session", launchTmuxSession}
+	}
+	if task != "" {
+		args = append(args, "--", task)
+	}
+	return args
+}
+
+// todoStartArgs returns the cmt arguments that start a session on a todo in venue, in a
+// new tmux window like newSessionArgs. An empty venue is the dashboard's directory.
+func todoStartArgs(todoID, venue string, inTmux bool) []string {
+	args := []string{"todo", "start", todoID, "--window"}
+	if !inTmux {
+		args = []string{"todo", "start", todoID, "--tmux-session", launchTmuxSession}
+	}
+	if venue != "" {
+		args = append(args, "--venue", venue)
+	}
+	return args
+}
+
+// expandHome replaces a leading ~ in path with the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + rest
+}
+
+// cmtNotice describes the outcome of a cmt command for the help bar.
+func cmtNotice(msg cmtDoneMsg) string {
+	if msg.err != nil {
+		if msg.output != "" {
+			return "Failed: " + msg.output
+		}
+		return fmt.Sprintf("Failed: %v", msg.err)
+	}
+	return msg.output
+}
+
+// renderPrompt renders the open prompt in place of the help bar.
+func (d *Dashboard) renderPrompt() string {
+	return helpStyle.Render(fmt.Sprintf("%s: %s█  (enter: confirm • esc: cancel)", d.prompt.label, d.prompt.text))
+}
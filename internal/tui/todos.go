@@ -317,5 +317,17 @@ func (d *Dashboard) formatTodoInfo(item *db.Todo) string {
 		b.WriteString("Message: \u2014\n")
 	}
 
+	// Sessions started on the todo
+	sessions, _ := d.db.ListTodoSessions(item.ID)
+	if len(sessions) == 0 {
+		b.WriteString("Sessions: \u2014\n")
+	} else {
+		b.WriteString("Sessions:\n")
+		for _, s := range sessions {
+			b.WriteString(fmt.Sprintf("  %s  %-9s %-9s %-4s %s\n",
+				s.ID, s.WorkflowType, s.Status, formatAge(s.CreatedAt), shortenPath(s.WorkingDirectory, 40)))
+		}
+	}
+
 	return b.String()
 }
@@ -0,0 +1,50 @@
+package vt
+
+import (
+	"regexp"
+	"strings"
+)
+
+// escapeRe matches escape sequences: CSI, OSC (ended by BEL or ST), charset selection
+// and other two-byte escapes.
+var escapeRe = regexp.MustCompile(`\x1b\[[0-9;:?<=>]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[()][0-9A-Za-z]|\x1b[ -~]`)
+
+// Plain returns output as plain text, the way a log of it reads rather than the way a
+// screen shows it: escape sequences are removed, a line rewritten after a carriage
+// return keeps only its last version, and runs of blank lines collapse to one.
+func Plain(output []byte) string {
+	text := escapeRe.ReplaceAllString(string(output), "")
+
+	var b strings.Builder
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		segments := strings.Split(line, "\r")
+		line = ""
+		for i := len(segments) - 1; i >= 0; i-- {
+			if segments[i] != "" {
+				line = segments[i]
+				break
+			}
+		}
+		line = strings.TrimRight(strings.Map(dropControl, line), " ")
+		if line == "" {
+			if blank || b.Len() == 0 {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// dropControl removes control characters other than tabs.
+func dropControl(r rune) rune {
+	if (r < 0x20 && r != '\t') || r == 0x7f {
+		return -1
+	}
+	return r
+}
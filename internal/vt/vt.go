@@ -0,0 +1,401 @@
+// Package vt emulates enough of a VT100/xterm terminal to render what a full-screen
+// program drew: text, cursor movement, erasing, scrolling and the alternate screen.
+// Colours and other attributes are dropped.
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parser states
+const (
+	stateGround = iota
+	stateEscape
+	stateCSI
+	stateOSC
+	stateOSCEscape
+	stateCharset
+)
+
+// Screen is a terminal screen that output is written to.
+type Screen struct {
+	rows, cols int
+	cells      [][]rune
+	saved      [][]rune // main screen while the alternate screen is shown
+	x, y       int
+	savedX     int
+	savedY     int
+	wrapNext   bool // the cursor is past the last column; the next rune wraps
+	top, bot   int  // scrolling region, inclusive
+
+	state   int
+	params  []byte
+	partial []byte // an incomplete UTF-8 sequence from the previous write
+}
+
+// New returns a blank screen of the given size.
+func New(rows, cols int) *Screen {
+	s := &Screen{rows: rows, cols: cols}
+	s.cells = blank(rows, cols)
+	s.bot = rows - 1
+	return s
+}
+
+func blank(rows, cols int) [][]rune {
+	cells := make([][]rune, rows)
+	for i := range cells {
+		cells[i] = blankLine(cols)
+	}
+	return cells
+}
+
+func blankLine(cols int) []rune {
+	line := make([]rune, cols)
+	for i := range line {
+		line[i] = ' '
+	}
+	return line
+}
+
+// Write feeds output to the screen. It never fails.
+func (s *Screen) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(s.partial) > 0 {
+		p = append(s.partial, p...)
+		s.partial = nil
+	}
+	for len(p) > 0 {
+		if s.state == stateGround && p[0] >= 0x80 {
+			if !utf8.FullRune(p) {
+				s.partial = append([]byte(nil), p...)
+				break
+			}
+			r, size := utf8.DecodeRune(p)
+			s.put(r)
+			p = p[size:]
+			continue
+		}
+		s.feed(p[0])
+		p = p[1:]
+	}
+	return n, nil
+}
+
+// feed handles one byte outside a UTF-8 sequence.
+func (s *Screen) feed(b byte) {
+	switch s.state {
+	case stateEscape:
+		s.escape(b)
+		return
+	case stateCSI:
+		if b >= 0x40 && b <= 0x7e {
+			s.csi(b)
+			s.state = stateGround
+		} else {
+			s.params = append(s.params, b)
+		}
+		return
+	case stateOSC:
+		switch b {
+		case 0x07:
+			s.state = stateGround
+		case 0x1b:
+			s.state = stateOSCEscape
+		}
+		return
+	case stateOSCEscape:
+		// ESC \ ends the string; anything else starts over as an escape
+		if b == '\\' {
+			s.state = stateGround
+		} else {
+			s.state = stateEscape
+			s.escape(b)
+		}
+		return
+	case stateCharset:
+		s.state = stateGround
+		return
+	}
+
+	switch b {
+	case 0x1b:
+		s.state = stateEscape
+	case '\r':
+		s.x, s.wrapNext = 0, false
+	case '\n', 0x0b, 0x0c:
+		s.lineFeed()
+	case '\b':
+		if s.x > 0 {
+			s.x--
+		}
+		s.wrapNext = false
+	case '\t':
+		s.x = min((s.x/8+1)*8, s.cols-1)
+	default:
+		if b >= 0x20 && b != 0x7f {
+			s.put(rune(b))
+		}
+	}
+}
+
+// escape handles the byte after ESC.
+func (s *Screen) escape(b byte) {
+	s.state = stateGround
+	switch b {
+	case '[':
+		s.state = stateCSI
+		s.params = s.params[:0]
+	case ']', 'P', '_', '^':
+		s.state = stateOSC
+	case '(', ')', '*', '+':
+		s.state = stateCharset
+	case '7':
+		s.savedX, s.savedY = s.x, s.y
+	case '8':
+		s.x, s.y, s.wrapNext = s.savedX, s.savedY, false
+	case 'D':
+		s.lineFeed()
+	case 'E':
+		s.x = 0
+		s.lineFeed()
+	case 'M':
+		if s.y == s.top {
+			s.scrollDown(1)
+		} else if s.y > 0 {
+			s.y--
+		}
+	case 'c':
+		*s = *New(s.rows, s.cols)
+	}
+}
+
+// csi handles a control sequence ending in final.
+func (s *Screen) csi(final byte) {
+	private := len(s.params) > 0 && s.params[0] == '?'
+	args := parseParams(s.params)
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	if private {
+		if final == 'h' || final == 'l' {
+			for _, mode := range args {
+				if mode == 1049 || mode == 1047 || mode == 47 {
+					s.altScreen(final == 'h')
+				}
+			}
+		}
+		return
+	}
+
+	s.wrapNext = false
+	switch final {
+	case 'A':
+		s.y = max(s.y-arg(0, 1), 0)
+	case 'B':
+		s.y = min(s.y+arg(0, 1), s.rows-1)
+	case 'C':
+		s.x = min(s.x+arg(0, 1), s.cols-1)
+	case 'D':
+		s.x = max(s.x-arg(0, 1), 0)
+	case 'E':
+		s.x, s.y = 0, min(s.y+arg(0, 1), s.rows-1)
+	case 'F':
+		s.x, s.y = 0, max(s.y-arg(0, 1), 0)
+	case 'G', '`':
+		s.x = clamp(arg(0, 1)-1, s.cols)
+	case 'd':
+		s.y = clamp(arg(0, 1)-1, s.rows)
+	case 'H', 'f':
+		s.y, s.x = clamp(arg(0, 1)-1, s.rows), clamp(arg(1, 1)-1, s.cols)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	case 'L':
+		if s.y >= s.top && s.y <= s.bot {
+			s.scrollRegion(s.y, s.bot, -arg(0, 1))
+		}
+	case 'M':
+		if s.y >= s.top && s.y <= s.bot {
+			s.scrollRegion(s.y, s.bot, arg(0, 1))
+		}
+	case 'P':
+		line := s.cells[s.y]
+		n := min(arg(0, 1), s.cols-s.x)
+		copy(line[s.x:], line[s.x+n:])
+		for i := s.cols - n; i < s.cols; i++ {
+			line[i] = ' '
+		}
+	case '@':
+		line := s.cells[s.y]
+		n := min(arg(0, 1), s.cols-s.x)
+		copy(line[s.x+n:], line[s.x:s.cols-n])
+		for i := s.x; i < s.x+n; i++ {
+			line[i] = ' '
+		}
+	case 'X':
+		for i := s.x; i < min(s.x+arg(0, 1), s.cols); i++ {
+			s.cells[s.y][i] = ' '
+		}
+	case 'S':
+		s.scrollUp(arg(0, 1))
+	case 'T':
+		s.scrollDown(arg(0, 1))
+	case 'r':
+		top, bot := arg(0, 1)-1, arg(1, s.rows)-1
+		if top < bot && bot < s.rows {
+			s.top, s.bot = top, bot
+			s.x, s.y = 0, 0
+		}
+	case 's':
+		s.savedX, s.savedY = s.x, s.y
+	case 'u':
+		s.x, s.y = s.savedX, s.savedY
+	}
+}
+
+// parseParams parses semicolon-separated numeric parameters; missing ones are 0.
+func parseParams(params []byte) []int {
+	str := strings.TrimLeft(string(params), "?>=!")
+	if str == "" {
+		return nil
+	}
+	parts := strings.Split(str, ";")
+	args := make([]int, len(parts))
+	for i, part := range parts {
+		// Sub-parameters (38:2:...) are only used by colours, which are dropped
+		part, _, _ = strings.Cut(part, ":")
+		args[i], _ = strconv.Atoi(part)
+	}
+	return args
+}
+
+func clamp(v, n int) int {
+	return max(0, min(v, n-1))
+}
+
+// put writes r at the cursor and advances it, wrapping at the right margin.
+func (s *Screen) put(r rune) {
+	if s.wrapNext {
+		s.x = 0
+		s.lineFeed()
+	}
+	s.cells[s.y][s.x] = r
+	if s.x == s.cols-1 {
+		s.wrapNext = true
+	} else {
+		s.x++
+	}
+}
+
+// lineFeed moves the cursor down a line, scrolling at the bottom of the scrolling region.
+func (s *Screen) lineFeed() {
+	s.wrapNext = false
+	switch {
+	case s.y == s.bot:
+		s.scrollUp(1)
+	case s.y < s.rows-1:
+		s.y++
+	}
+}
+
+func (s *Screen) scrollUp(n int)   { s.scrollRegion(s.top, s.bot, n) }
+func (s *Screen) scrollDown(n int) { s.scrollRegion(s.top, s.bot, -n) }
+
+// scrollRegion moves lines top..bot up by n (down for negative n), blanking the lines
+// that scroll in.
+func (s *Screen) scrollRegion(top, bot, n int) {
+	height := bot - top + 1
+	if n > height {
+		n = height
+	}
+	if n < -height {
+		n = -height
+	}
+	region := s.cells[top : bot+1]
+	if n > 0 {
+		copy(region, region[n:])
+		for i := height - n; i < height; i++ {
+			region[i] = blankLine(s.cols)
+		}
+	} else if n < 0 {
+		copy(region[-n:], region[:height+n])
+		for i := 0; i < -n; i++ {
+			region[i] = blankLine(s.cols)
+		}
+	}
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.y + 1; y < s.rows; y++ {
+			s.cells[y] = blankLine(s.cols)
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := 0; y < s.y; y++ {
+			s.cells[y] = blankLine(s.cols)
+		}
+	case 2, 3:
+		s.cells = blank(s.rows, s.cols)
+	}
+}
+
+func (s *Screen) eraseLine(mode int) {
+	from, to := s.x, s.cols
+	switch mode {
+	case 1:
+		from, to = 0, s.x+1
+	case 2:
+		from = 0
+	}
+	for i := from; i < min(to, s.cols); i++ {
+		s.cells[s.y][i] = ' '
+	}
+}
+
+// altScreen switches to the alternate screen, or back to the main one.
+func (s *Screen) altScreen(on bool) {
+	switch {
+	case on && s.saved == nil:
+		s.saved = s.cells
+		s.cells = blank(s.rows, s.cols)
+		s.savedX, s.savedY = s.x, s.y
+	case !on && s.saved != nil:
+		s.cells = s.saved
+		s.saved = nil
+		s.x, s.y = s.savedX, s.savedY
+	}
+	s.wrapNext = false
+}
+
+// Lines returns the screen's lines without trailing spaces, and without the blank lines
+// below the last line with text.
+func (s *Screen) Lines() []string {
+	lines := make([]string, s.rows)
+	last := -1
+	for y, row := range s.cells {
+		lines[y] = strings.TrimRight(string(row), " ")
+		if lines[y] != "" {
+			last = y
+		}
+	}
+	return lines[:last+1]
+}
+
+// LastLines returns the last n lines of Lines.
+func (s *Screen) LastLines(n int) []string {
+	lines := s.Lines()
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
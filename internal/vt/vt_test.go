@@ -0,0 +1,89 @@
+package vt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name   string
+		rows   int
+		cols   int
+		output string
+		want   []string
+	}{
+		{name: "plain lines", rows: 4, cols: 10, output: "one\r\ntwo\r\n", want: []string{"one", "two"}},
+		{name: "colours dropped", rows: 2, cols: 10, output: "\x1b[1;31mred\x1b[0m ok", want: []string{"red ok"}},
+		{name: "carriage return overwrites", rows: 2, cols: 10, output: "50%\r100%", want: []string{"100%"}},
+		{name: "erase line", rows: 2, cols: 10, output: "working...\r\x1b[Kdone", want: []string{"done"}},
+		{name: "cursor position", rows: 3, cols: 10, output: "\x1b[2;4Hx\x1b[1;1Hy", want: []string{"y", "   x"}},
+		{name: "scrolls at the bottom", rows: 2, cols: 10, output: "a\r\nb\r\nc\r\n", want: []string{"c"}},
+		{name: "wraps at the margin", rows: 3, cols: 4, output: "abcdef", want: []string{"abcd", "ef"}},
+		{name: "clear screen", rows: 2, cols: 10, output: "old\x1b[2J\x1b[Hnew", want: []string{"new"}},
+		{name: "redraw in place", rows: 3, cols: 10, output: "spinner |\x1b[1G\x1b[2Kspinner /", want: []string{"spinner /"}},
+		{name: "alternate screen restores the main one", rows: 2, cols: 10, output: "shell\x1b[?1049hfull\x1b[?1049l", want: []string{"shell"}},
+		{name: "title and charset sequences skipped", rows: 2, cols: 10, output: "\x1b]0;title\x07\x1b(Bhi\x1b]2;x\x1b\\!", want: []string{"hi!"}},
+		{name: "delete and insert chars", rows: 1, cols: 10, output: "abcdef\x1b[1;2H\x1b[2P\x1b[1@", want: []string{"a def"}},
+		{name: "scroll region", rows: 4, cols: 10, output: "head\x1b[2;3r\x1b[2;1Hx\r\ny\r\nz\x1b[r\x1b[4;1Hfoot", want: []string{"head", "y", "z", "foot"}},
+		{name: "reverse index at top inserts a line", rows: 3, cols: 10, output: "a\r\nb\x1b[H\x1bM", want: []string{"", "a", "b"}},
+		{name: "utf-8", rows: 1, cols: 10, output: "héllo ✓", want: []string{"héllo ✓"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(tt.rows, tt.cols)
+			s.Write([]byte(tt.output))
+			if got := s.Lines(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenSplitWrites(t *testing.T) {
+	// Escape sequences and UTF-8 runes split across writes
+	s := New(2, 20)
+	output := "\x1b[31mgrün\x1b[0m ✓ done"
+	for i := 0; i < len(output); i++ {
+		s.Write([]byte{output[i]})
+	}
+	if got := strings.Join(s.Lines(), "\n"); got != "grün ✓ done" {
+		t.Errorf("Lines() = %q", got)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	s := New(5, 10)
+	s.Write([]byte("1\r\n2\r\n3\r\n4"))
+	if got := s.LastLines(2); !reflect.DeepEqual(got, []string{"3", "4"}) {
+		t.Errorf("LastLines(2) = %q", got)
+	}
+	if got := s.LastLines(10); len(got) != 4 {
+		t.Errorf("LastLines(10) = %q, want all 4 lines", got)
+	}
+}
+
+func TestPlain(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "plain lines", output: "one\r\ntwo\r\n", want: "one\ntwo"},
+		{name: "colours dropped", output: "\x1b[1;31mred\x1b[0m ok", want: "red ok"},
+		{name: "progress keeps its last version", output: "50%\r100%\r\ndone", want: "100%\ndone"},
+		{name: "cursor movement and titles dropped", output: "\x1b]0;title\x07\x1b[2J\x1b[Hhi\x1b(B\x1b[?25l", want: "hi"},
+		{name: "blank lines collapse", output: "\r\n\r\na\r\n\r\n\r\n\x1b[Kb\r\n\r\n", want: "a\n\nb"},
+		{name: "bells and backspaces dropped", output: "ding\a\b!", want: "ding!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Plain([]byte(tt.output)); got != tt.want {
+				t.Errorf("Plain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}